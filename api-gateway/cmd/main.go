@@ -3,25 +3,35 @@ package main
 import (
 	"log"
 
+	"E-Commerce/api-gateway/config"
+	"E-Commerce/api-gateway/internal/auth"
 	"E-Commerce/api-gateway/internal/handler"
 	"E-Commerce/api-gateway/internal/middleware"
-	"E-Commerce/api-gateway/internal/observability"
 	pbInventory "E-Commerce/inventory-service/proto"
 	pbOrder "E-Commerce/order-service/proto"
+	"E-Commerce/pkg/authz"
+	"E-Commerce/pkg/observability"
+	"E-Commerce/pkg/tracing"
 	pbUser "E-Commerce/user-service/proto"
+	"E-Commerce/user-service/signingkeys"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	_ "github.com/lib/pq"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 )
 
 func main() {
-	// Initialize tracing
-	cleanup := observability.InitTracer()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Initialize tracing; metrics are served on /metrics below
+	cleanup := observability.Init("api-gateway", "")
 	defer cleanup()
 
-	inventoryConn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+	inventoryConn, err := grpc.Dial(cfg.InventoryAddr, grpc.WithInsecure(), tracing.DialOption())
 	if err != nil {
 		log.Fatalf("Failed to connect to Inventory Service: %v", err)
 	} else {
@@ -29,7 +39,7 @@ func main() {
 	}
 	defer inventoryConn.Close()
 
-	orderConn, err := grpc.Dial("localhost:50052", grpc.WithInsecure())
+	orderConn, err := grpc.Dial(cfg.OrderAddr, grpc.WithInsecure(), tracing.DialOption())
 	if err != nil {
 		log.Fatalf("Failed to connect to Order Service: %v", err)
 	} else {
@@ -37,7 +47,7 @@ func main() {
 	}
 	defer orderConn.Close()
 
-	userConn, err := grpc.Dial("localhost:50053", grpc.WithInsecure())
+	userConn, err := grpc.Dial(cfg.UserAddr, grpc.WithInsecure(), tracing.DialOption())
 	if err != nil {
 		log.Fatalf("Failed to connect to User Service: %v", err)
 	} else {
@@ -49,39 +59,52 @@ func main() {
 	orderClient := pbOrder.NewOrderServiceClient(orderConn)
 	userClient := pbUser.NewUserServiceClient(userConn)
 
+	// Tokens are verified with the user-service's public keys
+	verifier := authz.NewVerifier(authz.NewKeySet(signingkeys.Fetch(userClient), cfg.SigningKeysRefreshInterval))
+
 	r := gin.Default()
 
+	// Tag every request with an ID that error responses quote
+	r.Use(middleware.RequestID())
+
 	// Add telemetry middleware
 	r.Use(middleware.Telemetry())
 
 	// Expose metrics endpoint for Prometheus
-	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/metrics", gin.WrapH(observability.MetricsHandler()))
+
+	// Revoked access tokens are shared by every gateway through Redis
+	rdb := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+	defer rdb.Close()
+	denylist := auth.NewRedisDenylist(rdb)
 
-	userHandler := handler.NewUserHandler(userClient)
+	userHandler := handler.NewUserHandler(userClient, denylist)
 	h := handler.NewRESTHandler(inventoryClient, orderClient)
 
 	r.POST("/auth/register", userHandler.Register)
 	r.POST("/auth/login", userHandler.Login)
+	r.POST("/auth/refresh", userHandler.Refresh)
 
 	// Protected routes
 	protected := r.Group("/")
-	protected.Use(middleware.Auth())
+	protected.Use(middleware.Auth(verifier, denylist))
 	{
-		// Admin only routes
-		admin := protected.Group("/")
-		admin.Use(middleware.RequireRole("admin"))
-		{
-			admin.POST("/products", h.CreateProduct)
-			admin.PATCH("/products/:id", h.UpdateProduct)
-			admin.DELETE("/products/:id", h.DeleteProduct)
-			admin.PATCH("/orders/:id", h.UpdateOrder)
-		}
+		protected.POST("/auth/logout", userHandler.Logout)
+
+		// Staff routes, by the permission they require
+		productWrite := middleware.RequirePermission(authz.ProductWrite)
+		protected.POST("/products", productWrite, h.CreateProduct)
+		protected.PATCH("/products/:id", productWrite, h.UpdateProduct)
+		protected.DELETE("/products/:id", productWrite, h.DeleteProduct)
+		protected.PATCH("/orders/:id", middleware.RequirePermission(authz.OrderUpdateStatus), h.UpdateOrder)
+		protected.PUT("/admin/users/:id/role", middleware.RequirePermission(authz.UserManageRoles), userHandler.SetUserRole)
 
 		// User routes
 		protected.GET("/products/:id", h.GetProduct)
 		protected.GET("/products", h.ListProducts)
 		protected.POST("/orders", h.CreateOrder)
 		protected.GET("/orders/:id", h.GetOrder)
+		protected.POST("/orders/:id/cancel", h.CancelOrder)
 		protected.GET("/orders", h.ListOrders)
 
 		// Profile routes
@@ -89,7 +112,7 @@ func main() {
 		protected.PUT("/users/me", userHandler.UpdateCurrentUser)
 	}
 
-	if err := r.Run(":8080"); err != nil {
+	if err := r.Run(cfg.ListenAddr); err != nil {
 		log.Fatalf("Failed to run server: %v", err)
 	}
 }
@@ -1,27 +1,33 @@
 package config
 
 import (
-	pbInventory "E-Commerce/inventory-service/proto"
-	pbOrder "E-Commerce/order-service/proto"
-	"google.golang.org/grpc"
+	"time"
+
+	"E-Commerce/pkg/settings"
 )
 
+// EnvPrefix prefixes the gateway's environment variables:
+// API_GATEWAY_LISTEN_ADDR sets listen_addr.
+const EnvPrefix = "API_GATEWAY"
+
+// Config holds the api-gateway settings. Each can be set in the YAML file,
+// the environment or a flag; see pkg/settings.
 type Config struct {
-	InventoryClient pbInventory.InventoryServiceClient
-	OrderClient     pbOrder.OrderServiceClient
+	ListenAddr    string `setting:"listen_addr" default:":8080" required:"true" usage:"HTTP listen address; /metrics is served here too"`
+	InventoryAddr string `setting:"inventory_addr" default:"localhost:50051" required:"true" usage:"inventory-service gRPC address"`
+	OrderAddr     string `setting:"order_addr" default:"localhost:50052" required:"true" usage:"order-service gRPC address"`
+	UserAddr      string `setting:"user_addr" default:"localhost:50053" required:"true" usage:"user-service gRPC address"`
+	RedisAddr     string `setting:"redis_addr" default:"localhost:6379" required:"true" usage:"Redis address of the revoked token list"`
+
+	SigningKeysRefreshInterval time.Duration `setting:"signing_keys_refresh_interval" default:"5m" required:"true" usage:"how long the user-service's public keys are cached"`
 }
 
-func NewConfig() *Config {
-	inventoryConn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
-	if err != nil {
-		panic(err)
+// Load reads the settings and fails naming every one that is missing or
+// invalid.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := settings.Load(cfg, EnvPrefix); err != nil {
+		return nil, err
 	}
-	orderConn, err := grpc.Dial("localhost:50052", grpc.WithInsecure())
-	if err != nil {
-		panic(err)
-	}
-	return &Config{
-		InventoryClient: pbInventory.NewInventoryServiceClient(inventoryConn),
-		OrderClient:     pbOrder.NewOrderServiceClient(orderConn),
-	}
-}
\ No newline at end of file
+	return cfg, nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const deniedKeyPrefix = "gateway:denied_jti:"
+
+// Denylist holds the IDs (jti) of access tokens revoked before they
+// expire, e.g. by logging out.
+type Denylist interface {
+	// Deny revokes the token with the given ID until it expires anyway.
+	Deny(ctx context.Context, jti string, expiresAt time.Time) error
+	IsDenied(ctx context.Context, jti string) (bool, error)
+}
+
+type redisDenylist struct {
+	redis *redis.Client
+}
+
+// NewRedisDenylist keeps the denylist in Redis, so every gateway replica
+// sees a logout. Entries expire with the tokens they deny.
+func NewRedisDenylist(redis *redis.Client) Denylist {
+	return &redisDenylist{redis: redis}
+}
+
+func (d *redisDenylist) Deny(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.redis.Set(ctx, deniedKeyPrefix+jti, 1, ttl).Err()
+}
+
+func (d *redisDenylist) IsDenied(ctx context.Context, jti string) (bool, error) {
+	n, err := d.redis.Exists(ctx, deniedKeyPrefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
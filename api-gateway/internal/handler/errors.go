@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"E-Commerce/api-gateway/internal/problem"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type problemKind struct {
+	status int
+	code   string
+}
+
+// grpcProblems maps the gRPC status codes of the backends to HTTP. Codes
+// not listed are internal errors.
+var grpcProblems = map[codes.Code]problemKind{
+	codes.InvalidArgument:    {http.StatusBadRequest, problem.CodeInvalidArgument},
+	codes.OutOfRange:         {http.StatusBadRequest, problem.CodeInvalidArgument},
+	codes.Unauthenticated:    {http.StatusUnauthorized, problem.CodeUnauthenticated},
+	codes.PermissionDenied:   {http.StatusForbidden, problem.CodePermissionDenied},
+	codes.NotFound:           {http.StatusNotFound, problem.CodeNotFound},
+	codes.AlreadyExists:      {http.StatusConflict, problem.CodeAlreadyExists},
+	codes.FailedPrecondition: {http.StatusConflict, problem.CodeFailedPrecondition},
+	codes.Aborted:            {http.StatusConflict, problem.CodeConflict},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, problem.CodeRateLimited},
+	codes.Canceled:           {499, problem.CodeCancelled},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, problem.CodeTimeout},
+	codes.Unavailable:        {http.StatusServiceUnavailable, problem.CodeUnavailable},
+	codes.Unimplemented:      {http.StatusNotImplemented, problem.CodeNotImplemented},
+}
+
+// writeGRPCError translates an error returned by a backend into a problem
+// response. Messages of client errors are passed on, since the backends
+// write them for callers; server errors are logged and replaced by a
+// generic message so internals do not leak.
+func writeGRPCError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	kind, ok := grpcProblems[st.Code()]
+	if !ok {
+		kind = problemKind{http.StatusInternalServerError, problem.CodeInternal}
+	}
+	if kind.status >= http.StatusInternalServerError {
+		log.Printf("Request %s to %s failed: %v", c.GetString("requestID"), c.Request.URL.Path, err)
+		problem.Write(c, kind.status, kind.code, "The request could not be completed. Quote the request ID when reporting this.")
+		return
+	}
+	problem.Write(c, kind.status, kind.code, st.Message())
+}
+
+// writeBindError answers a request whose body or query failed to bind.
+func writeBindError(c *gin.Context, err error) {
+	problem.Write(c, http.StatusBadRequest, problem.CodeInvalidArgument, err.Error())
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"E-Commerce/api-gateway/internal/problem"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func serveError(t *testing.T, err error) (*httptest.ResponseRecorder, problem.Problem) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/orders/:id", func(c *gin.Context) {
+		c.Set("requestID", "req-123")
+		writeGRPCError(c, err)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/orders/42", nil))
+
+	var body problem.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w, body
+}
+
+func TestWriteGRPCErrorMapsStatusCodes(t *testing.T) {
+	tests := []struct {
+		code       codes.Code
+		httpStatus int
+		errorCode  string
+	}{
+		{codes.InvalidArgument, http.StatusBadRequest, problem.CodeInvalidArgument},
+		{codes.Unauthenticated, http.StatusUnauthorized, problem.CodeUnauthenticated},
+		{codes.PermissionDenied, http.StatusForbidden, problem.CodePermissionDenied},
+		{codes.NotFound, http.StatusNotFound, problem.CodeNotFound},
+		{codes.AlreadyExists, http.StatusConflict, problem.CodeAlreadyExists},
+		{codes.FailedPrecondition, http.StatusConflict, problem.CodeFailedPrecondition},
+		{codes.ResourceExhausted, http.StatusTooManyRequests, problem.CodeRateLimited},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout, problem.CodeTimeout},
+		{codes.Unavailable, http.StatusServiceUnavailable, problem.CodeUnavailable},
+		{codes.Unimplemented, http.StatusNotImplemented, problem.CodeNotImplemented},
+		{codes.Internal, http.StatusInternalServerError, problem.CodeInternal},
+		{codes.Unknown, http.StatusInternalServerError, problem.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			w, body := serveError(t, status.Error(tt.code, "boom"))
+
+			assert.Equal(t, tt.httpStatus, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.httpStatus, body.Status)
+			assert.Equal(t, tt.errorCode, body.Code)
+			assert.Equal(t, http.StatusText(tt.httpStatus), body.Title)
+		})
+	}
+}
+
+func TestWriteGRPCErrorDescribesClientErrors(t *testing.T) {
+	_, body := serveError(t, status.Error(codes.FailedPrecondition, "order can no longer be cancelled"))
+
+	assert.Equal(t, "about:blank", body.Type)
+	assert.Equal(t, "order can no longer be cancelled", body.Detail)
+	assert.Equal(t, "/orders/42", body.Instance)
+	assert.Equal(t, "req-123", body.RequestID)
+}
+
+func TestWriteGRPCErrorHidesServerErrors(t *testing.T) {
+	for _, err := range []error{
+		status.Error(codes.Internal, `pq: relation "orders" does not exist`),
+		errors.New("connection refused"),
+	} {
+		w, body := serveError(t, err)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, problem.CodeInternal, body.Code)
+		assert.Equal(t, "req-123", body.RequestID)
+		assert.False(t, strings.Contains(w.Body.String(), err.Error()), "body leaks %q", err)
+	}
+}
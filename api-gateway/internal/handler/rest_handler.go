@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"E-Commerce/api-gateway/internal/problem"
 	pbInventory "E-Commerce/inventory-service/proto"
 	pbOrder "E-Commerce/order-service/proto"
+	"E-Commerce/pkg/authz"
 	"net/http"
 	"strconv"
 
@@ -30,7 +32,7 @@ func (h *RESTHandler) CreateProduct(c *gin.Context) {
 		CategoryID  string  `json:"category_id"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeBindError(c, err)
 		return
 	}
 	resp, err := h.inventoryClient.CreateProduct(c.Request.Context(), &pbInventory.CreateProductRequest{
@@ -41,7 +43,7 @@ func (h *RESTHandler) CreateProduct(c *gin.Context) {
 		CategoryId:  req.CategoryID,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, resp.Product)
@@ -51,7 +53,7 @@ func (h *RESTHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
 	resp, err := h.inventoryClient.GetProduct(c.Request.Context(), &pbInventory.GetProductRequest{Id: id})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp.Product)
@@ -67,7 +69,7 @@ func (h *RESTHandler) UpdateProduct(c *gin.Context) {
 		CategoryID  string  `json:"category_id"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeBindError(c, err)
 		return
 	}
 	resp, err := h.inventoryClient.UpdateProduct(c.Request.Context(), &pbInventory.UpdateProductRequest{
@@ -79,7 +81,7 @@ func (h *RESTHandler) UpdateProduct(c *gin.Context) {
 		CategoryId:  req.CategoryID,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp.Product)
@@ -89,7 +91,7 @@ func (h *RESTHandler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 	resp, err := h.inventoryClient.DeleteProduct(c.Request.Context(), &pbInventory.DeleteProductRequest{Id: id})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"success": resp.Success})
@@ -111,7 +113,7 @@ func (h *RESTHandler) ListProducts(c *gin.Context) {
 		PageSize:   int32(pageSize),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -121,20 +123,19 @@ func (h *RESTHandler) ListProducts(c *gin.Context) {
 }
 
 func (h *RESTHandler) CreateOrder(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	if _, exists := c.Get("userID"); !exists {
+		problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authentication is required")
 		return
 	}
 
 	var req struct {
 		Items []struct {
 			ProductID string `json:"product_id" binding:"required"`
-			Quantity  int    `json:"quantity" binding:"required"`
-		} `json:"items" binding:"required"`
+			Quantity  int    `json:"quantity" binding:"required,gt=0"`
+		} `json:"items" binding:"required,min=1,dive"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeBindError(c, err)
 		return
 	}
 
@@ -146,12 +147,12 @@ func (h *RESTHandler) CreateOrder(c *gin.Context) {
 		}
 	}
 
+	// order-service places the order for the caller of the forwarded token
 	resp, err := h.orderClient.CreateOrder(c.Request.Context(), &pbOrder.CreateOrderRequest{
-		UserId: userID.(string),
-		Items:  pbItems,
+		Items: pbItems,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, resp.Order)
@@ -161,7 +162,7 @@ func (h *RESTHandler) GetOrder(c *gin.Context) {
 	id := c.Param("id")
 	resp, err := h.orderClient.GetOrder(c.Request.Context(), &pbOrder.GetOrderRequest{Id: id})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp.Order)
@@ -170,19 +171,45 @@ func (h *RESTHandler) GetOrder(c *gin.Context) {
 func (h *RESTHandler) UpdateOrder(c *gin.Context) {
 	id := c.Param("id")
 	var req struct {
-		Status string `json:"status" binding:"required,oneof=pending completed cancelled"`
+		Status string `json:"status" binding:"required,oneof=paid fulfilling shipped delivered refunded"`
+		Reason string `json:"reason"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeBindError(c, err)
 		return
 	}
 
 	resp, err := h.orderClient.UpdateOrder(c.Request.Context(), &pbOrder.UpdateOrderRequest{
 		Id:     id,
 		Status: req.Status,
+		Reason: req.Reason,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp.Order)
+}
+
+func (h *RESTHandler) CancelOrder(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	// The body is optional; an empty request just carries no reason
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeBindError(c, err)
+			return
+		}
+	}
+
+	resp, err := h.orderClient.CancelOrder(c.Request.Context(), &pbOrder.CancelOrderRequest{
+		Id:     id,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp.Order)
@@ -190,10 +217,10 @@ func (h *RESTHandler) UpdateOrder(c *gin.Context) {
 
 func (h *RESTHandler) ListOrders(c *gin.Context) {
 	userID, _ := c.Get("userID")
-	role, _ := c.Get("role")
 	queryUserID := c.Query("user_id")
 
-	if role == "admin" && queryUserID != "" {
+	// Staff who may read any order can list another user's
+	if authz.Has(c.GetString("role"), authz.OrderReadAny) && queryUserID != "" {
 		userID = queryUserID
 	} else {
 		userID = userID.(string)
@@ -214,7 +241,7 @@ func (h *RESTHandler) ListOrders(c *gin.Context) {
 		PageSize: int32(pageSize),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
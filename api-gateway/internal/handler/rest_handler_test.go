@@ -79,6 +79,30 @@ func (m *mockInventoryServiceClient) UpdateStock(ctx context.Context, req *pbInv
 	return nil, args.Error(1)
 }
 
+func (m *mockInventoryServiceClient) ReserveStock(ctx context.Context, req *pbInventory.ReserveStockRequest, opts ...grpc.CallOption) (*pbInventory.ReserveStockResponse, error) {
+	args := m.Called(ctx, req)
+	if resp := args.Get(0); resp != nil {
+		return resp.(*pbInventory.ReserveStockResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockInventoryServiceClient) CommitReservation(ctx context.Context, req *pbInventory.CommitReservationRequest, opts ...grpc.CallOption) (*pbInventory.CommitReservationResponse, error) {
+	args := m.Called(ctx, req)
+	if resp := args.Get(0); resp != nil {
+		return resp.(*pbInventory.CommitReservationResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockInventoryServiceClient) ReleaseReservation(ctx context.Context, req *pbInventory.ReleaseReservationRequest, opts ...grpc.CallOption) (*pbInventory.ReleaseReservationResponse, error) {
+	args := m.Called(ctx, req)
+	if resp := args.Get(0); resp != nil {
+		return resp.(*pbInventory.ReleaseReservationResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 // Mock order service client
 type mockOrderServiceClient struct {
 	mock.Mock
@@ -116,6 +140,14 @@ func (m *mockOrderServiceClient) ListOrders(ctx context.Context, req *pbOrder.Li
 	return nil, args.Error(1)
 }
 
+func (m *mockOrderServiceClient) CancelOrder(ctx context.Context, req *pbOrder.CancelOrderRequest, opts ...grpc.CallOption) (*pbOrder.CancelOrderResponse, error) {
+	args := m.Called(ctx, req)
+	if resp := args.Get(0); resp != nil {
+		return resp.(*pbOrder.CancelOrderResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 // Test setup helper
 func setupTest() (*RESTHandler, *mockInventoryServiceClient, *gin.Engine) {
 	gin.SetMode(gin.TestMode)
@@ -233,7 +265,7 @@ func TestGetProduct(t *testing.T) {
 			productID:    "nonexistent",
 			mockResponse: nil,
 			mockError:    status.Error(codes.NotFound, "product not found"),
-			expectedCode: http.StatusInternalServerError,
+			expectedCode: http.StatusNotFound,
 		},
 	}
 
@@ -299,7 +331,7 @@ func TestUpdateProduct(t *testing.T) {
 			},
 			mockResponse: nil,
 			mockError:    status.Error(codes.NotFound, "product not found"),
-			expectedCode: http.StatusInternalServerError,
+			expectedCode: http.StatusNotFound,
 		},
 	}
 
@@ -356,7 +388,7 @@ func TestDeleteProduct(t *testing.T) {
 			productID:    "nonexistent",
 			mockResponse: nil,
 			mockError:    status.Error(codes.NotFound, "product not found"),
-			expectedCode: http.StatusInternalServerError,
+			expectedCode: http.StatusNotFound,
 		},
 	}
 
@@ -381,3 +413,45 @@ func TestDeleteProduct(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateOrderCannotCancel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockOrder := new(mockOrderServiceClient)
+	handler := NewRESTHandler(new(mockInventoryServiceClient), mockOrder)
+	router := gin.New()
+	router.PATCH("/orders/:id", handler.UpdateOrder)
+
+	// Cancelling goes through POST /orders/:id/cancel, which needs order:cancel_any
+	req := httptest.NewRequest("PATCH", "/orders/order123", bytes.NewBufferString(`{"status":"cancelled"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockOrder.AssertNotCalled(t, "UpdateOrder", mock.Anything, mock.Anything)
+}
+
+func TestCreateOrderRejectsInvalidItems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockOrder := new(mockOrderServiceClient)
+	handler := NewRESTHandler(new(mockInventoryServiceClient), mockOrder)
+	router := gin.New()
+	router.POST("/orders", func(c *gin.Context) { c.Set("userID", "user-1") }, handler.CreateOrder)
+
+	bodies := map[string]string{
+		"No Items":          `{"items":[]}`,
+		"Negative Quantity": `{"items":[{"product_id":"product123","quantity":-1}]}`,
+		"Zero Quantity":     `{"items":[{"product_id":"product123","quantity":0}]}`,
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/orders", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+	mockOrder.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+}
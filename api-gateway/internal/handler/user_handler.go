@@ -1,39 +1,44 @@
 package handler
 
 import (
+	"log"
 	"net/http"
+	"time"
 
+	"E-Commerce/api-gateway/internal/auth"
+	"E-Commerce/api-gateway/internal/problem"
 	pb "E-Commerce/user-service/proto"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type UserHandler struct {
 	userClient pb.UserServiceClient
+	denylist   auth.Denylist
 }
 
-func NewUserHandler(userClient pb.UserServiceClient) *UserHandler {
-	return &UserHandler{userClient: userClient}
+func NewUserHandler(userClient pb.UserServiceClient, denylist auth.Denylist) *UserHandler {
+	return &UserHandler{userClient: userClient, denylist: denylist}
 }
 
 func (h *UserHandler) Register(c *gin.Context) {
 	var req struct {
 		Email    string `json:"email" binding:"required,email"`
 		Password string `json:"password" binding:"required"`
-		Role     string `json:"role"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeBindError(c, err)
 		return
 	}
 
 	resp, err := h.userClient.RegisterUser(c.Request.Context(), &pb.RegisterUserRequest{
 		Email:    req.Email,
 		Password: req.Password,
-		Role:     req.Role,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -46,7 +51,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 		Password string `json:"password" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeBindError(c, err)
 		return
 	}
 
@@ -54,18 +59,81 @@ func (h *UserHandler) Login(c *gin.Context) {
 		Email:    req.Email,
 		Password: req.Password,
 	})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.Unauthenticated, codes.NotFound:
+		problem.Write(c, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Invalid credentials")
+		return
+	default:
+		// An outage must not look like a wrong password
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Refresh exchanges a refresh token for new tokens. The old refresh
+// token cannot be used again.
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	resp, err := h.userClient.RefreshToken(c.Request.Context(), &pb.RefreshTokenRequest{
+		RefreshToken: req.RefreshToken,
+	})
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		writeGRPCError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// Logout revokes the access token the request was made with and, if one
+// is given, the refresh token of the same login.
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeBindError(c, err)
+			return
+		}
+	}
+
+	if req.RefreshToken != "" {
+		_, err := h.userClient.RevokeRefreshToken(c.Request.Context(), &pb.RevokeRefreshTokenRequest{
+			UserId:       c.GetString("userID"),
+			RefreshToken: req.RefreshToken,
+		})
+		if err != nil {
+			writeGRPCError(c, err)
+			return
+		}
+	}
+
+	expiresAt, _ := c.Get("tokenExpiresAt")
+	exp, _ := expiresAt.(time.Time)
+	if err := h.denylist.Deny(c.Request.Context(), c.GetString("tokenID"), exp); err != nil {
+		log.Printf("Failed to revoke access token: %v", err)
+		problem.Write(c, http.StatusServiceUnavailable, problem.CodeUnavailable, "The token could not be revoked, please try again")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authentication is required")
 		return
 	}
 
@@ -73,7 +141,7 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 		UserId: userID.(string),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -83,7 +151,7 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 func (h *UserHandler) UpdateCurrentUser(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authentication is required")
 		return
 	}
 
@@ -91,7 +159,7 @@ func (h *UserHandler) UpdateCurrentUser(c *gin.Context) {
 		NewPassword string `json:"new_password" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeBindError(c, err)
 		return
 	}
 
@@ -100,9 +168,35 @@ func (h *UserHandler) UpdateCurrentUser(c *gin.Context) {
 		NewPassword: req.NewPassword,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetUserRole changes the role of the user in the path. Only callers with
+// user:manage_roles reach it, and the change is recorded in the
+// user-service's audit log.
+func (h *UserHandler) SetUserRole(c *gin.Context) {
+	var req struct {
+		Role   string `json:"role" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	resp, err := h.userClient.SetUserRole(c.Request.Context(), &pb.SetUserRoleRequest{
+		UserId: c.Param("id"),
+		Role:   req.Role,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
-}
\ No newline at end of file
+}
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"E-Commerce/api-gateway/internal/problem"
+	pbUser "E-Commerce/user-service/proto"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mock user service client; calls without a method here panic
+type mockUserServiceClient struct {
+	pbUser.UserServiceClient
+	mock.Mock
+}
+
+func (m *mockUserServiceClient) RefreshToken(ctx context.Context, req *pbUser.RefreshTokenRequest, opts ...grpc.CallOption) (*pbUser.AuthResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbUser.AuthResponse), args.Error(1)
+}
+
+func (m *mockUserServiceClient) RevokeRefreshToken(ctx context.Context, req *pbUser.RevokeRefreshTokenRequest, opts ...grpc.CallOption) (*pbUser.RevokeRefreshTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbUser.RevokeRefreshTokenResponse), args.Error(1)
+}
+
+func (m *mockUserServiceClient) AuthenticateUser(ctx context.Context, req *pbUser.AuthenticateUserRequest, opts ...grpc.CallOption) (*pbUser.AuthResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbUser.AuthResponse), args.Error(1)
+}
+
+func (m *mockUserServiceClient) RegisterUser(ctx context.Context, req *pbUser.RegisterUserRequest, opts ...grpc.CallOption) (*pbUser.AuthResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbUser.AuthResponse), args.Error(1)
+}
+
+func (m *mockUserServiceClient) SetUserRole(ctx context.Context, req *pbUser.SetUserRoleRequest, opts ...grpc.CallOption) (*pbUser.UserProfile, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbUser.UserProfile), args.Error(1)
+}
+
+type fakeDenylist struct {
+	denied map[string]time.Time
+	err    error
+}
+
+func (d *fakeDenylist) Deny(ctx context.Context, jti string, expiresAt time.Time) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.denied[jti] = expiresAt
+	return nil
+}
+
+func (d *fakeDenylist) IsDenied(ctx context.Context, jti string) (bool, error) {
+	_, ok := d.denied[jti]
+	return ok, d.err
+}
+
+func setupUserTest() (*mockUserServiceClient, *fakeDenylist, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	mockUser := new(mockUserServiceClient)
+	denylist := &fakeDenylist{denied: map[string]time.Time{}}
+	h := NewUserHandler(mockUser, denylist)
+
+	router := gin.New()
+	router.POST("/auth/register", h.Register)
+	router.POST("/auth/login", h.Login)
+	router.POST("/auth/refresh", h.Refresh)
+	// Stands in for middleware.Auth
+	authenticated := func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Set("tokenID", "jti-1")
+		c.Set("tokenExpiresAt", time.Unix(2000000000, 0))
+	}
+	router.POST("/auth/logout", authenticated, h.Logout)
+	router.PUT("/admin/users/:id/role", authenticated, h.SetUserRole)
+	return mockUser, denylist, router
+}
+
+func post(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	return send(router, "POST", path, body)
+}
+
+func send(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockResponse *pbUser.AuthResponse
+		mockError    error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			body:         `{"refresh_token":"rt-1"}`,
+			mockResponse: &pbUser.AuthResponse{Token: "access-2", RefreshToken: "rt-2", ExpiresIn: 900},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Reused Token",
+			body:         `{"refresh_token":"rt-1"}`,
+			mockError:    status.Error(codes.Unauthenticated, "invalid refresh token"),
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Missing Token",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUser, _, router := setupUserTest()
+			if tt.mockResponse != nil || tt.mockError != nil {
+				mockUser.On("RefreshToken", mock.Anything, &pbUser.RefreshTokenRequest{RefreshToken: "rt-1"}).
+					Return(tt.mockResponse, tt.mockError).Once()
+			}
+
+			w := post(router, "/auth/refresh", tt.body)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"refresh_token":"rt-2"`)
+			}
+			mockUser.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+		problemCode  string
+	}{
+		{"Wrong Password", status.Error(codes.Unauthenticated, "invalid credentials"), http.StatusUnauthorized, problem.CodeInvalidCredentials},
+		{"Unknown User", status.Error(codes.NotFound, "user not found"), http.StatusUnauthorized, problem.CodeInvalidCredentials},
+		{"User Service Down", status.Error(codes.Unavailable, "connection refused"), http.StatusServiceUnavailable, problem.CodeUnavailable},
+		{"Timeout", status.Error(codes.DeadlineExceeded, "deadline exceeded"), http.StatusGatewayTimeout, problem.CodeTimeout},
+		{"Internal Error", status.Error(codes.Internal, "pq: connection refused"), http.StatusInternalServerError, problem.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUser, _, router := setupUserTest()
+			mockUser.On("AuthenticateUser", mock.Anything, &pbUser.AuthenticateUserRequest{Email: "user@test.com", Password: "secret"}).
+				Return(nil, tt.mockError).Once()
+
+			w := post(router, "/auth/login", `{"email":"user@test.com","password":"secret"}`)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Contains(t, w.Body.String(), `"code":"`+tt.problemCode+`"`)
+			mockUser.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogoutRevokesBothTokens(t *testing.T) {
+	mockUser, denylist, router := setupUserTest()
+	mockUser.On("RevokeRefreshToken", mock.Anything, &pbUser.RevokeRefreshTokenRequest{UserId: "user-1", RefreshToken: "rt-1"}).
+		Return(&pbUser.RevokeRefreshTokenResponse{}, nil).Once()
+
+	w := post(router, "/auth/logout", `{"refresh_token":"rt-1"}`)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, time.Unix(2000000000, 0), denylist.denied["jti-1"])
+	mockUser.AssertExpectations(t)
+}
+
+func TestLogoutWithoutRefreshToken(t *testing.T) {
+	mockUser, denylist, router := setupUserTest()
+
+	w := post(router, "/auth/logout", "")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, denylist.denied, "jti-1")
+	mockUser.AssertNotCalled(t, "RevokeRefreshToken", mock.Anything, mock.Anything)
+}
+
+func TestLogoutFailures(t *testing.T) {
+	t.Run("Refresh Token Of Another User", func(t *testing.T) {
+		mockUser, denylist, router := setupUserTest()
+		mockUser.On("RevokeRefreshToken", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.NotFound, "refresh token not found")).Once()
+
+		w := post(router, "/auth/logout", `{"refresh_token":"rt-other"}`)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, denylist.denied)
+	})
+
+	t.Run("Denylist Unavailable", func(t *testing.T) {
+		_, denylist, router := setupUserTest()
+		denylist.err = errors.New("redis: connection refused")
+
+		w := post(router, "/auth/logout", "")
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	})
+}
+
+func TestRegisterIgnoresRequestedRole(t *testing.T) {
+	mockUser, _, router := setupUserTest()
+	mockUser.On("RegisterUser", mock.Anything, &pbUser.RegisterUserRequest{Email: "eve@test.com", Password: "secret"}).
+		Return(&pbUser.AuthResponse{User: &pbUser.User{Id: "user-2", Role: "user"}}, nil).Once()
+
+	w := post(router, "/auth/register", `{"email":"eve@test.com","password":"secret","role":"admin"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"role":"user"`)
+	mockUser.AssertExpectations(t)
+}
+
+func TestSetUserRole(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockResponse *pbUser.UserProfile
+		mockError    error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			body:         `{"role":"admin","reason":"new store manager"}`,
+			mockResponse: &pbUser.UserProfile{Id: "user-2", Role: "admin"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Unknown Role",
+			body:         `{"role":"owner"}`,
+			mockError:    status.Error(codes.InvalidArgument, `invalid role "owner"`),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Own Role",
+			body:         `{"role":"user"}`,
+			mockError:    status.Error(codes.FailedPrecondition, "users cannot change their own role"),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Missing Role",
+			body:         `{"reason":"none"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUser, _, router := setupUserTest()
+			if tt.mockResponse != nil || tt.mockError != nil {
+				mockUser.On("SetUserRole", mock.Anything, mock.MatchedBy(func(req *pbUser.SetUserRoleRequest) bool {
+					return req.UserId == "user-2"
+				})).Return(tt.mockResponse, tt.mockError).Once()
+			}
+
+			w := send(router, "PUT", "/admin/users/user-2/role", tt.body)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockUser.AssertExpectations(t)
+		})
+	}
+}
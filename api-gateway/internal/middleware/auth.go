@@ -1,53 +1,71 @@
 package middleware
 
 import (
+    "log"
     "net/http"
     "strings"
     "E-Commerce/api-gateway/internal/auth"
+    "E-Commerce/api-gateway/internal/problem"
+    "E-Commerce/pkg/authz"
     "github.com/gin-gonic/gin"
 )
 
-func Auth() gin.HandlerFunc {
+// Auth admits requests carrying a valid access token that has not been
+// revoked. It fails closed: if the denylist cannot be read, the request
+// is refused rather than risk admitting a revoked token. The token is
+// forwarded to the backends, which check the caller's permissions again.
+func Auth(verifier *authz.Verifier, denylist auth.Denylist) gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-            c.Abort()
+            problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authorization header required")
             return
         }
 
         parts := strings.Split(authHeader, " ")
         if len(parts) != 2 || parts[0] != "Bearer" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
-            c.Abort()
+            problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthenticated, "Invalid token format")
             return
         }
 
-        claims, err := auth.ValidateToken(parts[1])
+        claims, err := verifier.Verify(c.Request.Context(), parts[1])
         if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-            c.Abort()
+            problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthenticated, "Invalid token")
+            return
+        }
+
+        denied, err := denylist.IsDenied(c.Request.Context(), claims.ID)
+        if err != nil {
+            log.Printf("Failed to check token revocation: %v", err)
+            problem.Write(c, http.StatusServiceUnavailable, problem.CodeUnavailable, "Unable to verify token")
+            return
+        }
+        if denied {
+            problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthenticated, "Token has been revoked")
             return
         }
 
         c.Set("userID", claims.UserID)
         c.Set("role", claims.Role)
+        c.Set("tokenID", claims.ID)
+        c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+        c.Request = c.Request.WithContext(authz.ForwardToken(c.Request.Context(), parts[1]))
         c.Next()
     }
 }
 
-func RequireRole(role string) gin.HandlerFunc {
+// RequirePermission admits requests whose role grants p. The backends
+// enforce the same permission, so this only saves them the round trip.
+func RequirePermission(p authz.Permission) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userRole, exists := c.Get("role")
+        role, exists := c.Get("role")
         if !exists {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-            c.Abort()
+            problem.Write(c, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authentication required")
             return
         }
 
-        if userRole != role {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
-            c.Abort()
+        if userRole, _ := role.(string); !authz.Has(userRole, p) {
+            problem.Write(c, http.StatusForbidden, problem.CodePermissionDenied, "Insufficient permissions")
             return
         }
 
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"E-Commerce/api-gateway/internal/auth"
+	"E-Commerce/api-gateway/internal/problem"
+	"E-Commerce/pkg/authz"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+type fakeDenylist struct {
+	denied map[string]bool
+	err    error
+}
+
+func (d *fakeDenylist) Deny(ctx context.Context, jti string, expiresAt time.Time) error {
+	d.denied[jti] = true
+	return nil
+}
+
+func (d *fakeDenylist) IsDenied(ctx context.Context, jti string) (bool, error) {
+	return d.denied[jti], d.err
+}
+
+// setupAuthRouter returns a router behind Auth and a function issuing
+// tokens it accepts.
+func setupAuthRouter(t *testing.T, denylist auth.Denylist) (*gin.Engine, func(jti, role string) string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := authz.NewVerifier(authz.NewKeySet(func(ctx context.Context) ([]authz.PublicKey, error) {
+		return []authz.PublicKey{{ID: "k1", Algorithm: "EdDSA", Key: public}}, nil
+	}, time.Hour))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/users/me", Auth(verifier, denylist), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("userID"))
+	})
+	r.POST("/products", Auth(verifier, denylist), RequirePermission(authz.ProductWrite), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	r.GET("/forwarded", Auth(verifier, denylist), func(c *gin.Context) {
+		md, _ := metadata.FromOutgoingContext(c.Request.Context())
+		c.String(http.StatusOK, strings.Join(md.Get(authz.MetadataKey), ","))
+	})
+
+	issue := func(jti, role string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, &authz.Claims{
+			UserID: "user-1",
+			Role:   role,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        jti,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(private)
+		require.NoError(t, err)
+		return signed
+	}
+	return r, issue
+}
+
+func get(r *gin.Engine, token string) *httptest.ResponseRecorder {
+	return request(r, http.MethodGet, "/users/me", token)
+}
+
+func request(r *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthRejectsRevokedTokens(t *testing.T) {
+	denylist := &fakeDenylist{denied: map[string]bool{"revoked": true}}
+	r, issue := setupAuthRouter(t, denylist)
+
+	w := get(r, issue("active", authz.RoleUser))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user-1", w.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, get(r, issue("revoked", authz.RoleUser)).Code)
+	assert.Equal(t, http.StatusUnauthorized, get(r, issue("", authz.RoleUser)).Code, "tokens without a jti cannot be revoked")
+}
+
+func TestAuthFailsClosedWithoutDenylist(t *testing.T) {
+	denylist := &fakeDenylist{denied: map[string]bool{}, err: errors.New("redis: connection refused")}
+	r, issue := setupAuthRouter(t, denylist)
+
+	assert.Equal(t, http.StatusServiceUnavailable, get(r, issue("active", authz.RoleUser)).Code)
+}
+
+func TestRequirePermission(t *testing.T) {
+	r, issue := setupAuthRouter(t, &fakeDenylist{denied: map[string]bool{}})
+
+	assert.Equal(t, http.StatusCreated, request(r, http.MethodPost, "/products", issue("a", authz.RoleCatalogManager)).Code)
+	assert.Equal(t, http.StatusCreated, request(r, http.MethodPost, "/products", issue("b", authz.RoleAdmin)).Code)
+	assert.Equal(t, http.StatusForbidden, request(r, http.MethodPost, "/products", issue("c", authz.RoleFulfillment)).Code)
+	assert.Equal(t, http.StatusForbidden, request(r, http.MethodPost, "/products", issue("d", authz.RoleUser)).Code)
+}
+
+func TestAuthForwardsTokenToBackends(t *testing.T) {
+	r, issue := setupAuthRouter(t, &fakeDenylist{denied: map[string]bool{}})
+	token := issue("active", authz.RoleUser)
+
+	w := request(r, http.MethodGet, "/forwarded", token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Bearer "+token, w.Body.String())
+}
+
+func TestAuthRejectionsAreProblems(t *testing.T) {
+	r, issue := setupAuthRouter(t, &fakeDenylist{denied: map[string]bool{"revoked": true}})
+	unavailable, issueUnchecked := setupAuthRouter(t, &fakeDenylist{err: errors.New("redis: connection refused")})
+
+	tests := []struct {
+		name       string
+		router     *gin.Engine
+		method     string
+		path       string
+		token      string
+		httpStatus int
+		code       string
+	}{
+		{"Invalid Token", r, http.MethodGet, "/users/me", "garbage", http.StatusUnauthorized, problem.CodeUnauthenticated},
+		{"Revoked Token", r, http.MethodGet, "/users/me", issue("revoked", authz.RoleUser), http.StatusUnauthorized, problem.CodeUnauthenticated},
+		{"Denylist Unavailable", unavailable, http.MethodGet, "/users/me", issueUnchecked("active", authz.RoleUser), http.StatusServiceUnavailable, problem.CodeUnavailable},
+		{"Missing Permission", r, http.MethodPost, "/products", issue("active", authz.RoleUser), http.StatusForbidden, problem.CodePermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			req.Header.Set(RequestIDHeader, "req-123")
+			w := httptest.NewRecorder()
+			tt.router.ServeHTTP(w, req)
+
+			var body problem.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.httpStatus, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.code, body.Code)
+			assert.Equal(t, tt.path, body.Instance)
+			assert.Equal(t, "req-123", body.RequestID)
+		})
+	}
+}
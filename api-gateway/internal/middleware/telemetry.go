@@ -1,28 +1,59 @@
 package middleware
 
 import (
-	"E-Commerce/api-gateway/internal/observability"
+	"E-Commerce/pkg/observability"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// unmatchedRoute labels requests that matched no route, so probes for
+// random paths share one series.
+const unmatchedRoute = "unmatched"
+
+// knownMethods are the methods recorded under their own name; anything
+// else a client sends is recorded as OTHER.
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
 func Telemetry() gin.HandlerFunc {
 	tracer := otel.Tracer("api-gateway")
 
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
 		method := c.Request.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+		// The route template, e.g. /products/:id, keeps one series per
+		// route rather than one per product
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		// Continue the caller's trace, if it sent one
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
 
 		// Create a span for this request
-		ctx, span := tracer.Start(c.Request.Context(), "http_request",
+		ctx, span := tracer.Start(ctx, method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
 			trace.WithAttributes(
-				attribute.String("http.method", method),
-				attribute.String("http.path", path),
+				attribute.String("http.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("http.path", c.Request.URL.Path),
 			),
 		)
 		defer span.End()
@@ -30,22 +61,30 @@ func Telemetry() gin.HandlerFunc {
 		// Update context with span
 		c.Request = c.Request.WithContext(ctx)
 
+		inFlight := observability.RequestsInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
 		// Process request
 		c.Next()
 
-		// Record metrics after request is processed
+		// Record metrics after request is processed, linked to the trace
 		duration := time.Since(start).Seconds()
 		status := c.Writer.Status()
+		class := observability.StatusClass(status)
+		exemplar := observability.Exemplar(ctx)
 
-		// Record request duration
-		observability.RequestDuration.WithLabelValues(method, path).Observe(duration)
-
-		// Record request count
-		observability.RequestCounter.WithLabelValues(
-			method,
-			path,
-			string(rune(status)),
-		).Inc()
+		observability.Observe(observability.RequestDuration.WithLabelValues(method, route, class), duration, exemplar)
+		observability.Inc(observability.RequestCounter.WithLabelValues(method, route, class), exemplar)
+		if c.Request.ContentLength >= 0 {
+			observability.RequestSize.WithLabelValues(method, route).Observe(float64(c.Request.ContentLength))
+		}
+		// Size is -1 when nothing was written
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		observability.ResponseSize.WithLabelValues(method, route).Observe(float64(size))
 
 		// Add response status to span
 		span.SetAttributes(attribute.Int("http.status_code", status))
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"E-Commerce/pkg/observability"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func setupTelemetryRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	observability.RequestCounter.Reset()
+	observability.RequestDuration.Reset()
+	observability.RequestsInFlight.Reset()
+	observability.RequestSize.Reset()
+	observability.ResponseSize.Reset()
+
+	r := gin.New()
+	r.Use(Telemetry())
+	r.GET("/products/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "product "+c.Param("id"))
+	})
+	r.POST("/orders", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	r.GET("/orders/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+	return r
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTelemetryLabelsByRouteTemplate(t *testing.T) {
+	r := setupTelemetryRouter()
+
+	for i := 0; i < 50; i++ {
+		serve(r, http.MethodGet, fmt.Sprintf("/products/%08d-0000-0000-0000-000000000000", i), "")
+	}
+
+	assert.Equal(t, 1, testutil.CollectAndCount(observability.RequestCounter))
+	assert.Equal(t, 50.0, testutil.ToFloat64(observability.RequestCounter.WithLabelValues("GET", "/products/:id", "2xx")))
+	assert.Equal(t, 1, testutil.CollectAndCount(observability.RequestDuration))
+}
+
+func TestTelemetryLabelsStatusClass(t *testing.T) {
+	r := setupTelemetryRouter()
+
+	serve(r, http.MethodPost, "/orders", `{"items":[]}`)
+	serve(r, http.MethodGet, "/orders/42", "")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(observability.RequestCounter.WithLabelValues("POST", "/orders", "2xx")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(observability.RequestCounter.WithLabelValues("GET", "/orders/:id", "4xx")))
+}
+
+func TestTelemetryCardinalityStaysBounded(t *testing.T) {
+	r := setupTelemetryRouter()
+
+	// Unknown paths and made-up methods must not create series of their own
+	for i := 0; i < 50; i++ {
+		serve(r, http.MethodGet, fmt.Sprintf("/probe/%d", i), "")
+		serve(r, fmt.Sprintf("METHOD%d", i), fmt.Sprintf("/products/%d", i), "")
+	}
+
+	assert.Equal(t, 2, testutil.CollectAndCount(observability.RequestCounter))
+	assert.Equal(t, 50.0, testutil.ToFloat64(observability.RequestCounter.WithLabelValues("GET", "unmatched", "4xx")))
+	assert.Equal(t, 50.0, testutil.ToFloat64(observability.RequestCounter.WithLabelValues("OTHER", "unmatched", "4xx")))
+	assert.LessOrEqual(t, testutil.CollectAndCount(observability.RequestSize), 2)
+	assert.LessOrEqual(t, testutil.CollectAndCount(observability.ResponseSize), 2)
+}
+
+func TestTelemetryRecordsSizesAndInFlight(t *testing.T) {
+	r := setupTelemetryRouter()
+
+	w := serve(r, http.MethodGet, "/products/1", "")
+	serve(r, http.MethodPost, "/orders", `{"items":[]}`)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(observability.RequestsInFlight.WithLabelValues("GET", "/products/:id")))
+	response := histogram(t, observability.ResponseSize.WithLabelValues("GET", "/products/:id"))
+	assert.Equal(t, float64(w.Body.Len()), response.GetSampleSum())
+	request := histogram(t, observability.RequestSize.WithLabelValues("POST", "/orders"))
+	assert.Equal(t, float64(len(`{"items":[]}`)), request.GetSampleSum())
+}
+
+func TestTelemetryLinksMetricsToTrace(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+	r := setupTelemetryRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var m dto.Metric
+	require.NoError(t, observability.RequestCounter.WithLabelValues("GET", "/products/:id", "2xx").(prometheus.Metric).Write(&m))
+	exemplar := m.GetCounter().GetExemplar()
+	require.NotNil(t, exemplar)
+	require.Len(t, exemplar.GetLabel(), 1)
+	assert.Equal(t, "trace_id", exemplar.GetLabel()[0].GetName())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", exemplar.GetLabel()[0].GetValue())
+}
+
+func histogram(t *testing.T, o prometheus.Observer) *dto.Histogram {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, o.(prometheus.Metric).Write(&m))
+	return m.GetHistogram()
+}
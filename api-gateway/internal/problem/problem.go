@@ -0,0 +1,65 @@
+// Package problem writes the gateway's RFC 7807 error responses, so the
+// handlers and the middleware in front of them answer failures alike.
+package problem
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of RFC 7807 error responses.
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 error response. Code is stable and meant for
+// clients to branch on; Title and Detail are for humans and may change.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Stable error codes of the gateway's problem responses.
+const (
+	CodeInvalidArgument    = "invalid_argument"
+	CodeUnauthenticated    = "unauthenticated"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodePermissionDenied   = "permission_denied"
+	CodeNotFound           = "not_found"
+	CodeAlreadyExists      = "already_exists"
+	CodeFailedPrecondition = "failed_precondition"
+	CodeConflict           = "conflict"
+	CodeRateLimited        = "rate_limited"
+	CodeCancelled          = "cancelled"
+	CodeTimeout            = "timeout"
+	CodeUnavailable        = "unavailable"
+	CodeNotImplemented     = "not_implemented"
+	CodeInternal           = "internal"
+)
+
+// titles covers statuses http.StatusText has no text for.
+var titles = map[int]string{
+	499: "Client Closed Request",
+}
+
+// Write aborts the request with a problem response.
+func Write(c *gin.Context, httpStatus int, code, detail string) {
+	title := http.StatusText(httpStatus)
+	if t, ok := titles[httpStatus]; ok {
+		title = t
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(httpStatus, Problem{
+		Type:      "about:blank",
+		Title:     title,
+		Status:    httpStatus,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: c.GetString("requestID"),
+	})
+}
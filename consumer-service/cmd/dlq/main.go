@@ -0,0 +1,82 @@
+// Command dlq inspects and replays order events that consumer-service moved
+// to the dead-letter queue.
+//
+//	dlq list [-limit N]
+//	dlq replay [-order ORDER_ID] [-limit N]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"E-Commerce/consumer-service/config"
+	"E-Commerce/consumer-service/internal/repository"
+	"E-Commerce/consumer-service/internal/service"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dlq list [-limit N] [-body]")
+	fmt.Fprintln(os.Stderr, "       dlq replay [-order ORDER_ID] [-limit N]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.LoadWithoutFlags()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	retry := cfg.RetryPolicy()
+	orderCreated, err := repository.NewSubscriber(cfg.Broker, cfg.RabbitMQURL, cfg.NATSURL, retry.Delays())
+	if err != nil {
+		log.Fatalf("Failed to connect to the event broker: %v", err)
+	}
+	defer orderCreated.Close()
+	svc := service.NewConsumerService(orderCreated, nil, nil, retry)
+
+	switch os.Args[1] {
+	case "list":
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		limit := fs.Int("limit", 50, "maximum number of events to show")
+		body := fs.Bool("body", false, "print the event payloads")
+		fs.Parse(os.Args[2:])
+
+		letters, err := svc.ListDeadLetters(*limit)
+		if err != nil {
+			log.Fatalf("Failed to read dead letters: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ORDER\tMESSAGE\tATTEMPTS\tREASON\tDEAD-LETTERED\tLAST ERROR")
+		for _, l := range letters {
+			at := ""
+			if !l.DeadLetteredAt.IsZero() {
+				at = l.DeadLetteredAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", l.OrderID, l.MessageID, l.Attempts, l.Reason, at, l.LastError)
+			if *body {
+				fmt.Fprintf(w, "\t%s\n", l.Body)
+			}
+		}
+		w.Flush()
+	case "replay":
+		fs := flag.NewFlagSet("replay", flag.ExitOnError)
+		orderID := fs.String("order", "", "only replay events for this order")
+		limit := fs.Int("limit", 1000, "maximum number of events to replay")
+		fs.Parse(os.Args[2:])
+
+		n, err := svc.ReplayDeadLetters(*orderID, *limit)
+		if err != nil {
+			log.Fatalf("Failed to replay dead letters (%d replayed): %v", n, err)
+		}
+		log.Printf("Replayed %d dead-lettered events", n)
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"E-Commerce/consumer-service/internal/handler"
+	"E-Commerce/consumer-service/internal/service"
+	"E-Commerce/pkg/settings"
+)
+
+func loadFrom(t *testing.T, vars map[string]string) (*Config, error) {
+	t.Helper()
+	return load(func(cfg interface{}) error {
+		return settings.LoadFrom(cfg, settings.Source{EnvPrefix: EnvPrefix, LookupEnv: func(key string) (string, bool) {
+			v, ok := vars[key]
+			return v, ok
+		}})
+	})
+}
+
+func TestDefaultsMatchPolicies(t *testing.T) {
+	cfg, err := loadFrom(t, nil)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if got := cfg.RetryPolicy(); got != service.DefaultRetryPolicy() {
+		t.Errorf("got retry policy %+v, want %+v", got, service.DefaultRetryPolicy())
+	}
+	if got := cfg.WorkerConfig(); got != handler.DefaultWorkerConfig() {
+		t.Errorf("got worker config %+v, want %+v", got, handler.DefaultWorkerConfig())
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	_, err := loadFrom(t, map[string]string{
+		"CONSUMER_SERVICE_WORKERS":          "0",
+		"CONSUMER_SERVICE_PREFETCH":         "-1",
+		"CONSUMER_SERVICE_RETRY_BASE_DELAY": "1m",
+		"CONSUMER_SERVICE_RETRY_MAX_DELAY":  "1s",
+	})
+
+	var settingsErr *settings.Error
+	if !errors.As(err, &settingsErr) {
+		t.Fatalf("got %v, want a *settings.Error", err)
+	}
+	want := []string{
+		"workers must be positive",
+		"prefetch must not be negative",
+		"retry_max_delay (1s) is shorter than retry_base_delay (1m0s)",
+	}
+	if !reflect.DeepEqual(settingsErr.Problems, want) {
+		t.Errorf("got problems %q, want %q", settingsErr.Problems, want)
+	}
+}
@@ -3,11 +3,13 @@ module E-Commerce/consumer-service
 go 1.21
 
 require (
-	github.com/streadway/amqp v1.0.0
+	github.com/go-redis/redis/v8 v8.11.5
 	google.golang.org/grpc v1.58.3
 )
 
 require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/golang/protobuf v1.5.3 // indirect
 	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sys v0.13.0 // indirect
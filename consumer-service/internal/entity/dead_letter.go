@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// DeadLetter is an order event that was moved to the dead-letter queue
+// after it could not be processed.
+type DeadLetter struct {
+	MessageID      string
+	OrderID        string
+	Attempts       int
+	Reason         string
+	LastError      string
+	DeadLetteredAt time.Time
+	Body           []byte
+}
@@ -2,37 +2,132 @@ package handler
 
 import (
     "E-Commerce/consumer-service/internal/service"
+    "E-Commerce/pkg/broker"
+    "context"
+    "hash/fnv"
     "log"
-    "os"
     "os/signal"
+    "sync"
     "syscall"
+    "time"
 )
 
+type WorkerConfig struct {
+    // Workers is how many events are processed concurrently.
+    Workers int
+    // Prefetch is how many unacknowledged events the broker hands out at
+    // once; it should be at least Workers to keep every worker busy.
+    Prefetch int
+    // ShutdownTimeout bounds how long shutdown waits for in-flight events.
+    ShutdownTimeout time.Duration
+    // OrderedByOrderID sends all events of an order to the same worker so
+    // they are processed in the order they were received. Retried events
+    // rejoin at the back of the queue, so this does not order retries.
+    OrderedByOrderID bool
+}
+
+func DefaultWorkerConfig() WorkerConfig {
+    return WorkerConfig{
+        Workers:         4,
+        Prefetch:        16,
+        ShutdownTimeout: 30 * time.Second,
+    }
+}
+
 type ConsumerHandler struct {
     svc service.ConsumerService
+    cfg WorkerConfig
 }
 
-func NewConsumerHandler(svc service.ConsumerService) *ConsumerHandler {
-    return &ConsumerHandler{svc: svc}
+func NewConsumerHandler(svc service.ConsumerService, cfg WorkerConfig) *ConsumerHandler {
+    return &ConsumerHandler{svc: svc, cfg: cfg}
 }
 
+// Start consumes events until SIGINT or SIGTERM and then shuts down
+// gracefully.
 func (h *ConsumerHandler) Start() error {
-    msgs, err := h.svc.ConsumeOrderCreated()
+    ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+    defer stop()
+    return h.Run(ctx)
+}
+
+// Run processes events with the configured worker pool until ctx is done.
+// It then stops consuming, waits up to ShutdownTimeout for in-flight events
+// and closes the channel and connection. Events that are still unsettled at
+// that point are redelivered by the broker.
+func (h *ConsumerHandler) Run(ctx context.Context) error {
+    msgs, err := h.svc.ConsumeOrderCreated(h.cfg.Prefetch)
     if err != nil {
         return err
     }
 
+    var wg sync.WaitGroup
+    if h.cfg.OrderedByOrderID {
+        h.runOrdered(msgs, &wg)
+    } else {
+        for i := 0; i < h.cfg.Workers; i++ {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                h.work(msgs)
+            }()
+        }
+    }
+    log.Printf("Consuming order events with %d workers (prefetch %d, ordered by order ID: %t)",
+        h.cfg.Workers, h.cfg.Prefetch, h.cfg.OrderedByOrderID)
+
+    <-ctx.Done()
+    log.Printf("Shutting down, waiting up to %s for in-flight events", h.cfg.ShutdownTimeout)
+    if err := h.svc.StopConsuming(); err != nil {
+        log.Printf("Error cancelling consumer: %v", err)
+    }
+
+    drained := make(chan struct{})
+    go func() {
+        wg.Wait()
+        close(drained)
+    }()
+    select {
+    case <-drained:
+        log.Printf("All in-flight events finished")
+    case <-time.After(h.cfg.ShutdownTimeout):
+        log.Printf("Shutdown timeout reached with events still in flight; they will be redelivered")
+    }
+
+    return h.svc.Close()
+}
+
+// runOrdered fans msgs out to one queue per worker, picked by a hash of the
+// order ID, so events of the same order never run concurrently.
+func (h *ConsumerHandler) runOrdered(msgs <-chan broker.Delivery, wg *sync.WaitGroup) {
+    queues := make([]chan broker.Delivery, h.cfg.Workers)
+    for i := range queues {
+        queues[i] = make(chan broker.Delivery)
+        wg.Add(1)
+        go func(q <-chan broker.Delivery) {
+            defer wg.Done()
+            h.work(q)
+        }(queues[i])
+    }
+
+    wg.Add(1)
     go func() {
+        defer wg.Done()
         for msg := range msgs {
-            if err := h.svc.ProcessOrderCreated(msg); err != nil {
-                log.Printf("Error processing message: %v", err)
-            }
+            hash := fnv.New32a()
+            hash.Write([]byte(service.OrderIDOf(msg.Message)))
+            queues[hash.Sum32()%uint32(len(queues))] <- msg
+        }
+        for _, q := range queues {
+            close(q)
         }
     }()
+}
 
-    sigChan := make(chan os.Signal, 1)
-    signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-    <-sigChan
-
-    return nil
-}
\ No newline at end of file
+func (h *ConsumerHandler) work(msgs <-chan broker.Delivery) {
+    for msg := range msgs {
+        if err := h.svc.ProcessOrderCreated(msg); err != nil {
+            log.Printf("Error processing message: %v", err)
+        }
+    }
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"E-Commerce/consumer-service/internal/entity"
+	"E-Commerce/consumer-service/internal/service"
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/memory"
+	"E-Commerce/pkg/events"
+	"E-Commerce/pkg/messaging"
+)
+
+// slowGRPCRepository takes delay per stock update and records how many
+// updates run at once, overall and per order.
+type slowGRPCRepository struct {
+	delay time.Duration
+
+	mu        sync.Mutex
+	running   int
+	maxRun    int
+	perOrder  map[string]int
+	overlap   bool
+	completed int
+}
+
+func (r *slowGRPCRepository) UpdateStock(ctx context.Context, productID string, quantity int, orderID string) error {
+	r.mu.Lock()
+	r.running++
+	if r.running > r.maxRun {
+		r.maxRun = r.running
+	}
+	r.perOrder[orderID]++
+	if r.perOrder[orderID] > 1 {
+		r.overlap = true
+	}
+	r.mu.Unlock()
+
+	time.Sleep(r.delay)
+
+	r.mu.Lock()
+	r.running--
+	r.perOrder[orderID]--
+	r.completed++
+	r.mu.Unlock()
+	return nil
+}
+
+type nopLedgerRepository struct{}
+
+func (nopLedgerRepository) IsProcessed(key string) (bool, error)      { return false, nil }
+func (nopLedgerRepository) MarkProcessed(key, messageID string) error { return nil }
+
+func setup(t *testing.T, delay time.Duration, cfg WorkerConfig) (*memory.Broker, *slowGRPCRepository, *ConsumerHandler) {
+	t.Helper()
+	b := memory.NewBroker()
+	t.Cleanup(func() { b.Close() })
+	b.Bind(messaging.OrderCreatedQueue, messaging.OrderCreatedRoutingKey)
+
+	grpcRepo := &slowGRPCRepository{delay: delay, perOrder: make(map[string]int)}
+	svc := service.NewConsumerService(b.Subscriber(messaging.OrderCreatedQueue), grpcRepo, nopLedgerRepository{}, service.DefaultRetryPolicy())
+	return b, grpcRepo, NewConsumerHandler(svc, cfg)
+}
+
+func publish(t *testing.T, b *memory.Broker, orderIDs ...string) {
+	t.Helper()
+	pub := b.Publisher()
+	for _, orderID := range orderIDs {
+		body, _ := json.Marshal(entity.OrderCreatedEvent{
+			Version:   events.OrderCreatedVersion,
+			OrderID:   orderID,
+			Items:     []events.LineItem{{ProductID: "product-1", Quantity: 1}},
+			CreatedAt: time.Now(),
+		})
+		err := pub.Publish(context.Background(), messaging.OrderCreatedRoutingKey, broker.Message{Body: body})
+		if err != nil {
+			t.Fatalf("failed to publish: %v", err)
+		}
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunProcessesConcurrentlyWithinPrefetch(t *testing.T) {
+	cfg := WorkerConfig{Workers: 4, Prefetch: 2, ShutdownTimeout: time.Second}
+	b, grpcRepo, h := setup(t, 20*time.Millisecond, cfg)
+	publish(t, b, "o1", "o2", "o3", "o4", "o5", "o6")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- h.Run(ctx) }()
+
+	waitFor(t, "all events", func() bool {
+		grpcRepo.mu.Lock()
+		defer grpcRepo.mu.Unlock()
+		return grpcRepo.completed == 6
+	})
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+
+	if grpcRepo.maxRun != 2 {
+		t.Errorf("got at most %d events in flight, want the prefetch limit of 2", grpcRepo.maxRun)
+	}
+}
+
+func TestRunDrainsInFlightEventsOnShutdown(t *testing.T) {
+	cfg := WorkerConfig{Workers: 2, Prefetch: 2, ShutdownTimeout: time.Second}
+	b, grpcRepo, h := setup(t, 50*time.Millisecond, cfg)
+	publish(t, b, "o1", "o2", "o3", "o4", "o5")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- h.Run(ctx) }()
+
+	waitFor(t, "events in flight", func() bool {
+		grpcRepo.mu.Lock()
+		defer grpcRepo.mu.Unlock()
+		return grpcRepo.running == 2
+	})
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+
+	// Both in-flight events finished and were acknowledged; the rest were
+	// never started and are still queued.
+	if grpcRepo.completed != 2 {
+		t.Errorf("got %d completed events, want 2", grpcRepo.completed)
+	}
+	if n := b.Len(messaging.OrderCreatedQueue); n != 3 {
+		t.Errorf("got %d queued events after shutdown, want 3", n)
+	}
+	if n := b.Unsettled(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("%d events left unacknowledged", n)
+	}
+}
+
+func TestRunOrderedByOrderID(t *testing.T) {
+	cfg := WorkerConfig{Workers: 3, Prefetch: 6, ShutdownTimeout: time.Second, OrderedByOrderID: true}
+	b, grpcRepo, h := setup(t, 10*time.Millisecond, cfg)
+	publish(t, b, "o1", "o1", "o2", "o1", "o3", "o2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- h.Run(ctx) }()
+
+	waitFor(t, "all events", func() bool {
+		grpcRepo.mu.Lock()
+		defer grpcRepo.mu.Unlock()
+		return grpcRepo.completed == 6
+	})
+	cancel()
+	<-done
+
+	if grpcRepo.overlap {
+		t.Error("events of the same order were processed concurrently")
+	}
+}
@@ -2,12 +2,19 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"E-Commerce/inventory-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ErrStockAlreadyApplied means inventory-service already applied this
+// order's stock change for the product.
+var ErrStockAlreadyApplied = errors.New("stock change already applied")
+
 type GRPCRepository interface {
-	UpdateStock(productID string, quantity int) error
+	UpdateStock(ctx context.Context, productID string, quantity int, orderID string) error
 }
 
 type grpcRepository struct {
@@ -18,10 +25,14 @@ func NewGRPCRepository(client proto.InventoryServiceClient) GRPCRepository {
 	return &grpcRepository{client: client}
 }
 
-func (r *grpcRepository) UpdateStock(productID string, quantity int) error {
-	_, err := r.client.UpdateStock(context.Background(), &proto.UpdateStockRequest{
+func (r *grpcRepository) UpdateStock(ctx context.Context, productID string, quantity int, orderID string) error {
+	_, err := r.client.UpdateStock(ctx, &proto.UpdateStockRequest{
 		ProductId: productID,
 		Quantity:  int32(quantity),
+		OrderId:   orderID,
 	})
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrStockAlreadyApplied
+	}
 	return err
-}
\ No newline at end of file
+}
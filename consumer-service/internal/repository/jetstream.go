@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/jetstream"
+	"E-Commerce/pkg/messaging"
+)
+
+// jetStreamAckWait is how long an order event may be processed before
+// JetStream hands it to another subscriber.
+const jetStreamAckWait = 30 * time.Second
+
+func NewJetStreamSubscriber(url string) (broker.Subscriber, error) {
+	client, err := jetstream.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	return NewJetStreamSubscriberFromClient(client)
+}
+
+// NewJetStreamSubscriberFromClient subscribes to order.created events through the
+// durable consumer shared by every consumer-service instance. Retries are
+// redelivered by JetStream, so no retry delays need declaring. The client
+// is closed if creating the streams fails.
+func NewJetStreamSubscriberFromClient(client jetstream.Client) (broker.Subscriber, error) {
+	return jetstream.NewSubscriber(client, jetstream.SubscriberConfig{
+		Stream:           jetstream.OrderEvents,
+		DeadLetterStream: jetstream.OrderEventsDeadLetters,
+		Topic:            messaging.OrderCreatedRoutingKey,
+		Consumer: jetstream.ConsumerConfig{
+			Durable: messaging.OrderCreatedQueue,
+			AckWait: jetStreamAckWait,
+		},
+		Timeout: 5 * time.Second,
+	})
+}
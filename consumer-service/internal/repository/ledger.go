@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const processedKeyPrefix = "consumer:processed:"
+
+// LedgerRepository remembers which events were already processed so that
+// redelivered or duplicate events can be acknowledged without side effects.
+// Entries expire after the retention window.
+type LedgerRepository interface {
+	IsProcessed(key string) (bool, error)
+	MarkProcessed(key, messageID string) error
+}
+
+type redisLedgerRepository struct {
+	redis     *redis.Client
+	retention time.Duration
+}
+
+func NewLedgerRepository(redis *redis.Client, retention time.Duration) LedgerRepository {
+	return &redisLedgerRepository{
+		redis:     redis,
+		retention: retention,
+	}
+}
+
+func (r *redisLedgerRepository) IsProcessed(key string) (bool, error) {
+	n, err := r.redis.Exists(context.Background(), processedKeyPrefix+key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// MarkProcessed stores the ID of the message that processed key, which
+// helps tell a broker redelivery from a second publish when debugging.
+func (r *redisLedgerRepository) MarkProcessed(key, messageID string) error {
+	return r.redis.Set(context.Background(), processedKeyPrefix+key, messageID, r.retention).Err()
+}
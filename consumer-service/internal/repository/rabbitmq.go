@@ -1,90 +1,35 @@
 package repository
 
 import (
-	"github.com/streadway/amqp"
-)
-
-type RabbitMQRepository interface {
-    ConsumeOrderCreated() (<-chan amqp.Delivery, error)
-    Close() error
-}
-
-type rabbitMQRepository struct {
-    conn    *amqp.Connection
-    channel *amqp.Channel
-}
-
-func NewRabbitMQRepository(url string) (RabbitMQRepository, error) {
-    conn, err := amqp.Dial(url)
-    if err != nil {
-        return nil, err
-    }
+    "fmt"
+    "os"
+    "time"
 
-    ch, err := conn.Channel()
-    if err != nil {
-        conn.Close()
-        return nil, err
-    }
+    "E-Commerce/pkg/broker"
+    "E-Commerce/pkg/broker/rabbitmq"
+    "E-Commerce/pkg/messaging"
+)
 
-    err = ch.ExchangeDeclare(
-        "order_events", // name
-        "topic",        // type
-        true,          // durable
-        false,         // auto-deleted
-        false,         // internal
-        false,         // no-wait
-        nil,          // arguments
-    )
+func NewRabbitMQSubscriber(url string, retryDelays []time.Duration) (broker.Subscriber, error) {
+    conn, err := messaging.Dial(url)
     if err != nil {
-        ch.Close()
-        conn.Close()
         return nil, err
     }
-
-    return &rabbitMQRepository{
-        conn:    conn,
-        channel: ch,
-    }, nil
+    return NewRabbitMQSubscriberFromConnection(conn, retryDelays)
 }
 
-func (r *rabbitMQRepository) ConsumeOrderCreated() (<-chan amqp.Delivery, error) {
-    q, err := r.channel.QueueDeclare(
-        "order_created_consumer", // name
-        true,                    // durable
-        false,                   // delete when unused
-        false,                   // exclusive
-        false,                   // no-wait
-        nil,                     // arguments
-    )
-    if err != nil {
-        return nil, err
-    }
-
-    err = r.channel.QueueBind(
-        q.Name,         // queue name
-        "order.created", // routing key
-        "order_events", // exchange
-        false,
-        nil,
-    )
-    if err != nil {
-        return nil, err
-    }
-
-    return r.channel.Consume(
-        q.Name, // queue
-        "",     // consumer
-        false,  // auto-ack
-        false,  // exclusive
-        false,  // no-local
-        false,  // no-wait
-        nil,    // args
-    )
+// NewRabbitMQSubscriberFromConnection subscribes to the order.created queue
+// of the shared order events topology, with one delay queue per retry delay
+// and the order events dead-letter queue. The connection is closed if the
+// declaration fails.
+func NewRabbitMQSubscriberFromConnection(conn messaging.Connection, retryDelays []time.Duration) (broker.Subscriber, error) {
+    return rabbitmq.NewSubscriber(conn, rabbitmq.SubscriberConfig{
+        Topology:             messaging.OrderEvents,
+        Queue:                messaging.OrderCreatedQueue,
+        DeadLetterExchange:   messaging.DeadLetterExchange,
+        DeadLetterRoutingKey: messaging.OrderCreatedRoutingKey,
+        DeadLetterQueue:      messaging.OrderCreatedDeadLetterQueue,
+        RetryDelays:          retryDelays,
+        ConsumerTag:          fmt.Sprintf("consumer-service-%d", os.Getpid()),
+    })
 }
-
-func (r *rabbitMQRepository) Close() error {
-    if err := r.channel.Close(); err != nil {
-        return err
-    }
-    return r.conn.Close()
-}
\ No newline at end of file
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"E-Commerce/pkg/broker"
+)
+
+// NewSubscriber connects to the order events backend named by backend,
+// "rabbitmq" at rabbitMQURL or "jetstream" at natsURL.
+func NewSubscriber(backend, rabbitMQURL, natsURL string, retryDelays []time.Duration) (broker.Subscriber, error) {
+	switch backend {
+	case "rabbitmq":
+		return NewRabbitMQSubscriber(rabbitMQURL, retryDelays)
+	case "jetstream":
+		return NewJetStreamSubscriber(natsURL)
+	default:
+		return nil, fmt.Errorf("unknown broker %q, want rabbitmq or jetstream", backend)
+	}
+}
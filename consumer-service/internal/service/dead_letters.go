@@ -0,0 +1,41 @@
+package service
+
+import (
+	"time"
+
+	"E-Commerce/consumer-service/internal/entity"
+	"E-Commerce/pkg/broker"
+)
+
+func (s *consumerService) ListDeadLetters(limit int) ([]*entity.DeadLetter, error) {
+	msgs, err := s.orderCreated.PeekDeadLetters(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := make([]*entity.DeadLetter, len(msgs))
+	for i, msg := range msgs {
+		letter := &entity.DeadLetter{
+			MessageID: msg.ID,
+			OrderID:   OrderIDOf(msg),
+			Attempts:  msg.Attempt(),
+			Reason:    msg.Header(broker.DeadLetterReasonHeader),
+			LastError: msg.Header(broker.LastErrorHeader),
+			Body:      msg.Body,
+		}
+		if at := msg.Header(broker.DeadLetteredAtHeader); at != "" {
+			letter.DeadLetteredAt, _ = time.Parse(time.RFC3339, at)
+		}
+		letters[i] = letter
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters moves dead-lettered events back onto the consumer queue
+// with a fresh attempt budget. An empty orderID replays every event.
+func (s *consumerService) ReplayDeadLetters(orderID string, limit int) (int, error) {
+	match := func(msg broker.Message) bool {
+		return orderID == "" || OrderIDOf(msg) == orderID
+	}
+	return s.orderCreated.ReplayDeadLetters(match, limit)
+}
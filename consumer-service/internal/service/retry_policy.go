@@ -0,0 +1,48 @@
+package service
+
+import "time"
+
+// RetryPolicy bounds how often a failed order event is retried. After a
+// failed attempt n the event waits BaseDelay*2^(n-1), capped at MaxDelay,
+// before it is delivered again; after MaxAttempts failed attempts it is
+// dead-lettered.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+	}
+}
+
+// Delay returns how long to wait after the given failed attempt.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// Delays lists the distinct delays the policy can produce, one delay queue
+// being needed for each.
+func (p RetryPolicy) Delays() []time.Duration {
+	var delays []time.Duration
+	seen := make(map[time.Duration]bool)
+	for attempt := 1; attempt < p.MaxAttempts; attempt++ {
+		d := p.Delay(attempt)
+		if !seen[d] {
+			seen[d] = true
+			delays = append(delays, d)
+		}
+	}
+	return delays
+}
@@ -2,46 +2,222 @@ package service
 
 import (
     "encoding/json"
+    "errors"
+    "fmt"
     "E-Commerce/consumer-service/internal/entity"
     "E-Commerce/consumer-service/internal/repository"
-    "github.com/streadway/amqp"
+    "E-Commerce/pkg/broker"
+    "E-Commerce/pkg/events"
+    "E-Commerce/pkg/tracing"
     "log"
+    "time"
+)
+
+// Reasons recorded on dead-lettered messages
+const (
+    DeadLetterMalformed   = "malformed"
+    DeadLetterInvalid     = "invalid"
+    DeadLetterMaxAttempts = "max_attempts"
 )
 
 type ConsumerService interface {
-    ConsumeOrderCreated() (<-chan amqp.Delivery, error)
-    ProcessOrderCreated(msg amqp.Delivery) error
+    ConsumeOrderCreated(prefetch int) (<-chan broker.Delivery, error)
+    StopConsuming() error
+    // ProcessOrderCreated always settles msg: it is acknowledged, scheduled
+    // for a retry or dead-lettered. The returned error is for logging only.
+    ProcessOrderCreated(msg broker.Delivery) error
+    ListDeadLetters(limit int) ([]*entity.DeadLetter, error)
+    ReplayDeadLetters(orderID string, limit int) (int, error)
+    Close() error
 }
 
 type consumerService struct {
-    rabbitRepo repository.RabbitMQRepository
-    grpcRepo   repository.GRPCRepository
+    orderCreated broker.Subscriber
+    grpcRepo     repository.GRPCRepository
+    ledger       repository.LedgerRepository
+    retry        RetryPolicy
 }
 
-func NewConsumerService(rabbitRepo repository.RabbitMQRepository, grpcRepo repository.GRPCRepository) ConsumerService {
+// NewConsumerService processes the order.created events of orderCreated,
+// whose retry delays must include every delay of retry.
+func NewConsumerService(orderCreated broker.Subscriber, grpcRepo repository.GRPCRepository, ledger repository.LedgerRepository, retry RetryPolicy) ConsumerService {
     return &consumerService{
-        rabbitRepo: rabbitRepo,
-        grpcRepo:   grpcRepo,
+        orderCreated: orderCreated,
+        grpcRepo:     grpcRepo,
+        ledger:       ledger,
+        retry:        retry,
     }
 }
 
-func (s *consumerService) ConsumeOrderCreated() (<-chan amqp.Delivery, error) {
-    return s.rabbitRepo.ConsumeOrderCreated()
+func (s *consumerService) ConsumeOrderCreated(prefetch int) (<-chan broker.Delivery, error) {
+    return s.orderCreated.Subscribe(prefetch)
 }
 
-func (s *consumerService) ProcessOrderCreated(msg amqp.Delivery) error {
-    var event entity.OrderCreatedEvent
-    if err := json.Unmarshal(msg.Body, &event); err != nil {
+func (s *consumerService) StopConsuming() error {
+    return s.orderCreated.Unsubscribe()
+}
+
+func (s *consumerService) Close() error {
+    return s.orderCreated.Close()
+}
+
+func (s *consumerService) ProcessOrderCreated(msg broker.Delivery) error {
+    // Processing continues the trace of the request that placed the order
+    ctx, span := tracing.StartConsumer(msg.Message, "order.created process")
+    defer span.End()
+
+    event, err := decodeOrderCreated(msg.Message)
+    if errors.Is(err, events.ErrUnsupportedVersion) || errors.Is(err, events.ErrInvalidEvent) {
+        log.Printf("Rejecting order.created event: %v", err)
+        s.deadLetter(msg, DeadLetterInvalid, err)
+        return err
+    }
+    if err != nil {
         log.Printf("Error unmarshaling message: %v", err)
+        // Redelivering a message that cannot be decoded would never succeed
+        s.deadLetter(msg, DeadLetterMalformed, err)
         return err
     }
 
-    for _, productID := range event.Products {
-        if err := s.grpcRepo.UpdateStock(productID, -1); err != nil {
-            log.Printf("Error updating stock for product %s: %v", productID, err)
+    // Events are delivered at least once. The ledger catches most
+    // duplicates up front; inventory-service rejecting a second stock change
+    // for the same order covers the rest, e.g. while the ledger is down.
+    key := orderCreatedKey(event.OrderID)
+    processed, err := s.ledger.IsProcessed(key)
+    if err != nil {
+        log.Printf("Error checking processed ledger for order %s: %v", event.OrderID, err)
+    }
+    if processed {
+        log.Printf("Skipping duplicate order.created event for order %s (message %s)", event.OrderID, msg.ID)
+        return msg.Ack()
+    }
+
+    // Stock of an order with a reservation was taken off when it was
+    // reserved; decrementing it again would count the order twice
+    if event.ReservationID != "" {
+        log.Printf("Stock for order %s is held by reservation %s, nothing to update", event.OrderID, event.ReservationID)
+        if err := s.ledger.MarkProcessed(key, msg.ID); err != nil {
+            log.Printf("Error recording order %s as processed: %v", event.OrderID, err)
+        }
+        return msg.Ack()
+    }
+
+    for _, item := range mergeLineItems(event.Items) {
+        err := s.grpcRepo.UpdateStock(ctx, item.ProductID, -item.Quantity, event.OrderID)
+        if errors.Is(err, repository.ErrStockAlreadyApplied) {
+            log.Printf("Stock for product %s was already updated for order %s", item.ProductID, event.OrderID)
+            continue
+        }
+        if err != nil {
+            log.Printf("Error updating stock for product %s: %v", item.ProductID, err)
+            s.retryLater(msg, err)
             return err
         }
     }
 
-    return msg.Ack(false)
-}
\ No newline at end of file
+    if err := s.ledger.MarkProcessed(key, msg.ID); err != nil {
+        log.Printf("Error recording order %s as processed: %v", event.OrderID, err)
+    }
+    return msg.Ack()
+}
+
+// decodeOrderCreated unwraps the CloudEvents envelope of msg, checking the
+// payload against the schema of its type. Bodies published before events
+// were wrapped are bare payloads and are decoded as such.
+func decodeOrderCreated(msg broker.Message) (*entity.OrderCreatedEvent, error) {
+    var event entity.OrderCreatedEvent
+    if msg.ContentType != events.ContentType {
+        if err := json.Unmarshal(msg.Body, &event); err != nil {
+            return nil, err
+        }
+        return &event, event.Validate()
+    }
+
+    envelope, err := events.Decode(msg.Body)
+    if err != nil {
+        return nil, err
+    }
+    if envelope.Type != events.OrderCreatedType {
+        return nil, fmt.Errorf("%w: %s is not an order.created event", events.ErrUnsupportedVersion, envelope.Type)
+    }
+    if err := json.Unmarshal(envelope.Data, &event); err != nil {
+        return nil, err
+    }
+    return &event, event.Validate()
+}
+
+// orderCreatedKey identifies an order.created event in the ledger. There is
+// one such event per order, so the order ID also catches an event that was
+// published twice under different message IDs.
+func orderCreatedKey(orderID string) string {
+    return "order.created:" + orderID
+}
+
+// retryLater schedules msg for another attempt after the policy's backoff,
+// or dead-letters it once it has used up its attempts.
+func (s *consumerService) retryLater(msg broker.Delivery, cause error) {
+    attempt := msg.Attempt()
+    if attempt >= s.retry.MaxAttempts {
+        log.Printf("Order event %s failed %d attempts, dead-lettering it", msg.ID, attempt)
+        s.deadLetter(msg, DeadLetterMaxAttempts, cause)
+        return
+    }
+
+    delay := s.retry.Delay(attempt)
+    err := msg.Retry(delay, map[string]interface{}{
+        broker.AttemptHeader:   int32(attempt + 1),
+        broker.LastErrorHeader: cause.Error(),
+    })
+    if err != nil {
+        // Hand the message back to the broker rather than losing it
+        log.Printf("Error scheduling retry, requeueing message: %v", err)
+        msg.Nack(true)
+        return
+    }
+    log.Printf("Retrying order event in %s (attempt %d of %d)", delay, attempt+1, s.retry.MaxAttempts)
+}
+
+func (s *consumerService) deadLetter(msg broker.Delivery, reason string, cause error) {
+    err := msg.DeadLetter(map[string]interface{}{
+        broker.AttemptHeader:          int32(msg.Attempt()),
+        broker.LastErrorHeader:        cause.Error(),
+        broker.DeadLetterReasonHeader: reason,
+        broker.DeadLetteredAtHeader:   time.Now().UTC().Format(time.RFC3339),
+    })
+    if err != nil {
+        log.Printf("Error dead-lettering message, requeueing it: %v", err)
+        msg.Nack(true)
+    }
+}
+
+// mergeLineItems sums the quantities of items that reference the same
+// product so each product's stock is adjusted once per order.
+func mergeLineItems(items []events.LineItem) []events.LineItem {
+    merged := make([]events.LineItem, 0, len(items))
+    index := make(map[string]int, len(items))
+    for _, item := range items {
+        if i, ok := index[item.ProductID]; ok {
+            merged[i].Quantity += item.Quantity
+            continue
+        }
+        index[item.ProductID] = len(merged)
+        merged = append(merged, item)
+    }
+    return merged
+}
+
+// OrderIDOf extracts the order ID from a message body, either a CloudEvents
+// envelope or a bare payload, returning "" for bodies that cannot be decoded.
+func OrderIDOf(msg broker.Message) string {
+    var event struct {
+        OrderID string `json:"order_id"`
+        Data    struct {
+            OrderID string `json:"order_id"`
+        } `json:"data"`
+    }
+    json.Unmarshal(msg.Body, &event)
+    if event.OrderID != "" {
+        return event.OrderID
+    }
+    return event.Data.OrderID
+}
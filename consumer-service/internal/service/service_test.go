@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"E-Commerce/consumer-service/internal/entity"
+	"E-Commerce/consumer-service/internal/repository"
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/jetstream"
+	"E-Commerce/pkg/broker/jetstream/jetstreamtest"
+	"E-Commerce/pkg/broker/memory"
+	"E-Commerce/pkg/events"
+	"E-Commerce/pkg/messaging"
+)
+
+type stockUpdate struct {
+	productID string
+	quantity  int
+	orderID   string
+}
+
+type fakeGRPCRepository struct {
+	mu      sync.Mutex
+	updates []stockUpdate
+	// failures is how many upcoming calls fail
+	failures int
+	// applied makes UpdateStock report changes as already applied
+	applied bool
+	// traceParents holds the trace context each update was made in
+	traceParents []string
+}
+
+type fakeLedgerRepository struct {
+	mu        sync.Mutex
+	processed map[string]string
+}
+
+func (f *fakeLedgerRepository) IsProcessed(key string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.processed[key]
+	return ok, nil
+}
+
+func (f *fakeLedgerRepository) MarkProcessed(key, messageID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.processed[key] = messageID
+	return nil
+}
+
+func (f *fakeGRPCRepository) UpdateStock(ctx context.Context, productID string, quantity int, orderID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("inventory-service unavailable")
+	}
+	if f.applied {
+		return repository.ErrStockAlreadyApplied
+	}
+	f.updates = append(f.updates, stockUpdate{productID: productID, quantity: quantity, orderID: orderID})
+	f.traceParents = append(f.traceParents, events.TraceParentFromContext(ctx))
+	return nil
+}
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    20 * time.Millisecond,
+}
+
+func testEvent(orderID string) entity.OrderCreatedEvent {
+	return entity.OrderCreatedEvent{
+		Version:   events.OrderCreatedVersion,
+		OrderID:   orderID,
+		UserID:    "user-1",
+		Items:     []events.LineItem{{ProductID: "product-1", Quantity: 2, UnitPrice: 10}},
+		Total:     20,
+		CreatedAt: time.Now(),
+	}
+}
+
+func newTestConsumer(t *testing.T) (*memory.Broker, ConsumerService, *fakeGRPCRepository, <-chan broker.Delivery) {
+	t.Helper()
+	b := memory.NewBroker()
+	t.Cleanup(func() { b.Close() })
+	b.Bind(messaging.OrderCreatedQueue, messaging.OrderCreatedRoutingKey)
+
+	grpcRepo := &fakeGRPCRepository{}
+	ledger := &fakeLedgerRepository{processed: make(map[string]string)}
+	svc := NewConsumerService(b.Subscriber(messaging.OrderCreatedQueue), grpcRepo, ledger, testRetryPolicy)
+
+	msgs, err := svc.ConsumeOrderCreated(0)
+	if err != nil {
+		t.Fatalf("failed to consume: %v", err)
+	}
+	return b, svc, grpcRepo, msgs
+}
+
+// envelope wraps event in a CloudEvents envelope of eventType without
+// validating it, so tests can also build events producer-service rejects.
+func envelope(t *testing.T, eventType string, event entity.OrderCreatedEvent) []byte {
+	t.Helper()
+	id := event.EventID
+	if id == "" {
+		id = "event-" + event.OrderID
+	}
+	e, err := events.NewEnvelope(context.Background(), eventType, "/producer-service", id, event.OrderID, event.CreatedAt, event)
+	if err != nil {
+		t.Fatalf("failed to wrap event: %v", err)
+	}
+	body, _ := json.Marshal(e)
+	return body
+}
+
+// publish sends event through messaging.PublishOrderCreated, the path
+// producer-service publishes every order.created event on.
+func publish(t *testing.T, b *memory.Broker, event entity.OrderCreatedEvent) {
+	t.Helper()
+	publishFrom(t, context.Background(), b, event)
+}
+
+func publishFrom(t *testing.T, ctx context.Context, b *memory.Broker, event entity.OrderCreatedEvent) {
+	t.Helper()
+	if event.EventID == "" {
+		event.EventID = "event-" + event.OrderID
+	}
+	if _, _, err := messaging.PublishOrderCreated(ctx, b.Publisher(), "/producer-service", event); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+}
+
+func publishBody(t *testing.T, b *memory.Broker, id, contentType string, body []byte) {
+	t.Helper()
+	msg := broker.Message{ID: id, ContentType: contentType, Body: body}
+	if err := b.Publisher().Publish(context.Background(), messaging.OrderCreatedRoutingKey, msg); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+}
+
+func receive(t *testing.T, msgs <-chan broker.Delivery) broker.Delivery {
+	t.Helper()
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("order.created event was not delivered to the consumer")
+	}
+	return broker.Delivery{}
+}
+
+// TestOrderCreatedReachesConsumer publishes an order.created event the way
+// producer-service does and checks that it is routed to the consumer queue,
+// processed and acknowledged.
+func TestOrderCreatedReachesConsumer(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+
+	publish(t, b, entity.OrderCreatedEvent{
+		Version: events.OrderCreatedVersion,
+		OrderID: "order-1",
+		UserID:  "user-1",
+		Items: []events.LineItem{
+			{ProductID: "product-1", Quantity: 3, UnitPrice: 10},
+			{ProductID: "product-2", Quantity: 1, UnitPrice: 5},
+			{ProductID: "product-1", Quantity: 2, UnitPrice: 10},
+		},
+		Total:     55,
+		CreatedAt: time.Now(),
+	})
+
+	if err := svc.ProcessOrderCreated(receive(t, msgs)); err != nil {
+		t.Fatalf("failed to process message: %v", err)
+	}
+
+	want := []stockUpdate{{"product-1", -5, "order-1"}, {"product-2", -1, "order-1"}}
+	if len(grpcRepo.updates) != len(want) {
+		t.Fatalf("got %d stock updates, want %d", len(grpcRepo.updates), len(want))
+	}
+	for i, u := range want {
+		if grpcRepo.updates[i] != u {
+			t.Errorf("update %d: got %+v, want %+v", i, grpcRepo.updates[i], u)
+		}
+	}
+	if n := b.Unsettled(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("%d messages left unacknowledged", n)
+	}
+	if n := b.Len(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("%d messages left in %s", n, messaging.OrderCreatedQueue)
+	}
+}
+
+func TestOrderCreatedRejectsUnknownVersion(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+
+	body := envelope(t, "com.ecommerce.order.created.v2", testEvent("order-1"))
+	publishBody(t, b, "event-1", events.ContentType, body)
+
+	err := svc.ProcessOrderCreated(receive(t, msgs))
+	if !errors.Is(err, events.ErrUnsupportedVersion) {
+		t.Fatalf("got error %v, want %v", err, events.ErrUnsupportedVersion)
+	}
+	if len(grpcRepo.updates) != 0 {
+		t.Errorf("stock was updated for an unsupported event: %+v", grpcRepo.updates)
+	}
+	if n := b.DeadLetters(messaging.OrderCreatedQueue); n != 1 {
+		t.Errorf("got %d dead-lettered messages, want 1", n)
+	}
+}
+
+func TestMalformedOrderCreatedIsDeadLettered(t *testing.T) {
+	b, svc, _, msgs := newTestConsumer(t)
+
+	publishBody(t, b, "", events.ContentType, []byte("{not json"))
+
+	if err := svc.ProcessOrderCreated(receive(t, msgs)); err == nil {
+		t.Fatal("expected an error for a malformed message")
+	}
+	letters, err := svc.ListDeadLetters(10)
+	if err != nil {
+		t.Fatalf("failed to list dead letters: %v", err)
+	}
+	if len(letters) != 1 || letters[0].Reason != DeadLetterMalformed {
+		t.Fatalf("got dead letters %+v, want one %s message", letters, DeadLetterMalformed)
+	}
+	if n := b.Unsettled(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("%d messages left unacknowledged", n)
+	}
+}
+
+func TestFailedOrderCreatedIsRetriedWithBackoff(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+	grpcRepo.failures = 2
+
+	publish(t, b, testEvent("order-1"))
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		msg := receive(t, msgs)
+		if got := msg.Attempt(); got != attempt {
+			t.Fatalf("got attempt %d, want %d", got, attempt)
+		}
+		err := svc.ProcessOrderCreated(msg)
+		if attempt < 3 && err == nil {
+			t.Fatalf("attempt %d: expected an error", attempt)
+		}
+		if attempt == 3 && err != nil {
+			t.Fatalf("attempt %d: %v", attempt, err)
+		}
+	}
+
+	if len(grpcRepo.updates) != 1 {
+		t.Fatalf("got %d stock updates, want 1", len(grpcRepo.updates))
+	}
+	if n := b.DeadLetters(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("got %d dead-lettered messages, want 0", n)
+	}
+}
+
+func TestExhaustedOrderCreatedIsDeadLetteredAndReplayed(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+	for _, orderID := range []string{"order-1", "order-2"} {
+		grpcRepo.failures = testRetryPolicy.MaxAttempts
+		publish(t, b, testEvent(orderID))
+		for i := 0; i < testRetryPolicy.MaxAttempts; i++ {
+			svc.ProcessOrderCreated(receive(t, msgs))
+		}
+	}
+
+	letters, err := svc.ListDeadLetters(10)
+	if err != nil {
+		t.Fatalf("failed to list dead letters: %v", err)
+	}
+	if len(letters) != 2 {
+		t.Fatalf("got %d dead letters, want 2", len(letters))
+	}
+	if l := letters[0]; l.OrderID != "order-1" || l.Reason != DeadLetterMaxAttempts || l.Attempts != testRetryPolicy.MaxAttempts || l.LastError == "" {
+		t.Errorf("unexpected dead letter %+v", l)
+	}
+	// Listing must not consume the dead letters
+	if n := b.DeadLetters(messaging.OrderCreatedQueue); n != 2 {
+		t.Fatalf("got %d dead-lettered messages after listing, want 2", n)
+	}
+
+	n, err := svc.ReplayDeadLetters("order-1", 10)
+	if err != nil || n != 1 {
+		t.Fatalf("ReplayDeadLetters = %d, %v; want 1, nil", n, err)
+	}
+	msg := receive(t, msgs)
+	if got := msg.Attempt(); got != 1 {
+		t.Errorf("replayed message is on attempt %d, want 1", got)
+	}
+	if err := svc.ProcessOrderCreated(msg); err != nil {
+		t.Fatalf("failed to process replayed message: %v", err)
+	}
+	if n := b.DeadLetters(messaging.OrderCreatedQueue); n != 1 {
+		t.Errorf("got %d dead-lettered messages after replay, want only order-2 left", n)
+	}
+}
+
+func TestDuplicateOrderCreatedIsAckedWithoutSideEffects(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+
+	event := testEvent("order-1")
+	event.EventID = "event-1"
+	publish(t, b, event)
+	publish(t, b, event)
+
+	for i := 0; i < 2; i++ {
+		if err := svc.ProcessOrderCreated(receive(t, msgs)); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	if len(grpcRepo.updates) != 1 {
+		t.Errorf("got %d stock updates, want 1", len(grpcRepo.updates))
+	}
+	if n := b.Unsettled(messaging.OrderCreatedQueue) + b.Len(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("%d messages left unsettled", n)
+	}
+}
+
+func TestStockAlreadyAppliedCountsAsProcessed(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+	grpcRepo.applied = true
+
+	publish(t, b, testEvent("order-1"))
+	if err := svc.ProcessOrderCreated(receive(t, msgs)); err != nil {
+		t.Fatalf("failed to process message: %v", err)
+	}
+	if n := b.Unsettled(messaging.OrderCreatedQueue) + b.DeadLetters(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("an event whose stock was already applied was not acknowledged")
+	}
+}
+
+// TestReservedOrderCreatedLeavesStockAlone checks that the stock of an
+// order placed against a reservation, which already took it off, is not
+// decremented a second time.
+func TestReservedOrderCreatedLeavesStockAlone(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+
+	reserved := testEvent("order-1")
+	reserved.ReservationID = "reservation-1"
+	publish(t, b, reserved)
+	publish(t, b, testEvent("order-2"))
+
+	for i := 0; i < 2; i++ {
+		if err := svc.ProcessOrderCreated(receive(t, msgs)); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	if len(grpcRepo.updates) != 1 || grpcRepo.updates[0].orderID != "order-2" {
+		t.Errorf("got stock updates %+v, want only the unreserved order-2", grpcRepo.updates)
+	}
+	if n := b.Unsettled(messaging.OrderCreatedQueue) + b.DeadLetters(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("%d messages left unsettled or dead-lettered", n)
+	}
+}
+
+// TestOrderCreatedOverJetStream runs the retry path on the JetStream
+// backend, where the attempt comes from the delivery count and the event
+// is only acknowledged once it was processed.
+func TestOrderCreatedOverJetStream(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	sub, err := repository.NewJetStreamSubscriberFromClient(srv.Connect())
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	pub, err := jetstream.NewPublisher(srv.Connect(), time.Second, jetstream.OrderEvents)
+	if err != nil {
+		t.Fatalf("failed to create publisher: %v", err)
+	}
+	grpcRepo := &fakeGRPCRepository{failures: 1}
+	ledger := &fakeLedgerRepository{processed: make(map[string]string)}
+	svc := NewConsumerService(sub, grpcRepo, ledger, testRetryPolicy)
+	msgs, err := svc.ConsumeOrderCreated(1)
+	if err != nil {
+		t.Fatalf("failed to consume: %v", err)
+	}
+
+	event := testEvent("order-1")
+	event.EventID = "event-1"
+	if _, _, err := messaging.PublishOrderCreated(context.Background(), pub, "/producer-service", event); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		d := receive(t, msgs)
+		if d.Key != event.OrderID || d.Attempt() != attempt {
+			t.Fatalf("got key %q attempt %d, want %q attempt %d", d.Key, d.Attempt(), event.OrderID, attempt)
+		}
+		err := svc.ProcessOrderCreated(d)
+		if (attempt == 1) != (err != nil) {
+			t.Fatalf("attempt %d: got error %v", attempt, err)
+		}
+	}
+	if len(grpcRepo.updates) != 1 {
+		t.Fatalf("got %d stock updates, want 1", len(grpcRepo.updates))
+	}
+	if n := srv.Pending(jetstream.OrderEventsStream, messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("%d events still pending after processing, want 0", n)
+	}
+}
+
+func TestOrderCreatedRejectsPayloadAgainstSchema(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+
+	event := testEvent("order-1")
+	event.CreatedAt = time.Time{}
+	body := envelope(t, events.OrderCreatedType, event)
+	// A created_at that is not a date-time passes Validate but not the schema
+	body = []byte(strings.Replace(string(body), `"created_at":"0001-01-01T00:00:00Z"`, `"created_at":"yesterday"`, 1))
+	publishBody(t, b, "event-1", events.ContentType, body)
+
+	err := svc.ProcessOrderCreated(receive(t, msgs))
+	if !errors.Is(err, events.ErrInvalidEvent) {
+		t.Fatalf("got error %v, want %v", err, events.ErrInvalidEvent)
+	}
+	if len(grpcRepo.updates) != 0 {
+		t.Errorf("stock was updated for an invalid event: %+v", grpcRepo.updates)
+	}
+	letters, _ := svc.ListDeadLetters(10)
+	if len(letters) != 1 || letters[0].Reason != DeadLetterInvalid || letters[0].OrderID != "order-1" {
+		t.Fatalf("got dead letters %+v, want one %s letter for order-1", letters, DeadLetterInvalid)
+	}
+}
+
+func TestLegacyOrderCreatedPayloadIsProcessed(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+
+	body, _ := json.Marshal(testEvent("order-1"))
+	publishBody(t, b, "event-1", "application/json", body)
+
+	if err := svc.ProcessOrderCreated(receive(t, msgs)); err != nil {
+		t.Fatalf("failed to process a bare payload: %v", err)
+	}
+	if len(grpcRepo.updates) != 1 {
+		t.Errorf("got %d stock updates, want 1", len(grpcRepo.updates))
+	}
+}
+
+func TestOrderCreatedContinuesPublisherTrace(t *testing.T) {
+	b, svc, grpcRepo, msgs := newTestConsumer(t)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := events.ContextWithTraceParent(context.Background(), "00-"+traceID+"-00f067aa0ba902b7-01")
+	publishFrom(t, ctx, b, testEvent("order-1"))
+
+	if err := svc.ProcessOrderCreated(receive(t, msgs)); err != nil {
+		t.Fatalf("failed to process message: %v", err)
+	}
+	if len(grpcRepo.traceParents) != 1 || !strings.Contains(grpcRepo.traceParents[0], traceID) {
+		t.Fatalf("stock updated with trace context %v, want trace %s", grpcRepo.traceParents, traceID)
+	}
+}
@@ -1,36 +1,76 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
+	"time"
 
 	"E-Commerce/inventory-service/config"
 	"E-Commerce/inventory-service/internal/handler"
 	"E-Commerce/inventory-service/internal/repository"
 	"E-Commerce/inventory-service/internal/service"
 	pb "E-Commerce/inventory-service/proto"
+	"E-Commerce/pkg/authz"
+	"E-Commerce/pkg/observability"
+	"E-Commerce/pkg/tracing"
+	pbUser "E-Commerce/user-service/proto"
+	"E-Commerce/user-service/signingkeys"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
 )
 
 func main() {
-	cfg := config.NewConfig()
-	defer cfg.DB.Close()
-	defer cfg.Redis.Close()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	shutdown := observability.Init("inventory-service", cfg.MetricsAddr)
+	defer shutdown()
+
+	db, err := sqlx.Connect("postgres", cfg.PostgresURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+	defer rdb.Close()
 
-	repo := repository.NewProductRepository(cfg.DB, cfg.Redis)
-	svc := service.NewInventoryService(repo)
+	repo := repository.NewProductRepository(db, rdb)
+	reservationRepo := repository.NewReservationRepository(db, rdb)
+	svc := service.NewInventoryService(repo, reservationRepo)
 	h := handler.NewInventoryGRPCServer(svc)
 
-	lis, err := net.Listen("tcp", ":50055")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.NewReservationSweeper(svc, time.Minute).Run(ctx)
+
+	lis, err := net.Listen("tcp", cfg.ListenAddr)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	// Catalog changes need a forwarded token allowed to make them; stock
+	// and reservations are managed by the order-service on its own behalf
+	userConn, err := grpc.Dial(cfg.UserAddr, grpc.WithInsecure(), tracing.DialOption())
+	if err != nil {
+		log.Fatalf("Failed to connect to User Service: %v", err)
+	}
+	defer userConn.Close()
+	verifier := authz.NewVerifier(authz.NewKeySet(signingkeys.Fetch(pbUser.NewUserServiceClient(userConn)), cfg.SigningKeysRefreshInterval))
+	rules := authz.Rules{
+		pb.InventoryService_CreateProduct_FullMethodName: authz.ProductWrite,
+		pb.InventoryService_UpdateProduct_FullMethodName: authz.ProductWrite,
+		pb.InventoryService_DeleteProduct_FullMethodName: authz.ProductWrite,
+	}
+	s := grpc.NewServer(append(observability.ServerOptions(), authz.ServerOption(verifier, rules))...)
 	pb.RegisterInventoryServiceServer(s, h)
 
-	log.Println("inventory-service started, listening on :50055")
+	log.Printf("inventory-service started, listening on %s", cfg.ListenAddr)
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 	}
@@ -1,34 +1,32 @@
 package config
 
 import (
-	"log"
-	"os"
+	"time"
 
-	"github.com/go-redis/redis/v8"
-	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"E-Commerce/pkg/settings"
 )
 
+// EnvPrefix namespaces the environment variables, e.g.
+// INVENTORY_SERVICE_POSTGRES_URL.
+const EnvPrefix = "INVENTORY_SERVICE"
+
+// Config holds the inventory-service settings. Each can be set in the YAML
+// file, the environment or a flag; see pkg/settings.
 type Config struct {
-	DB    *sqlx.DB
-	Redis *redis.Client
+	ListenAddr                 string        `setting:"listen_addr" default:":50051" required:"true" usage:"gRPC listen address"`
+	MetricsAddr                string        `setting:"metrics_addr" default:":9101" usage:"Prometheus /metrics listen address; empty disables it"`
+	PostgresURL                string        `setting:"postgres_url" required:"true" usage:"PostgreSQL DSN of the inventory database"`
+	RedisAddr                  string        `setting:"redis_addr" default:"localhost:6379" required:"true" usage:"Redis address of the product cache"`
+	UserAddr                   string        `setting:"user_addr" default:"localhost:50053" required:"true" usage:"user-service gRPC address, for the keys access tokens are verified with"`
+	SigningKeysRefreshInterval time.Duration `setting:"signing_keys_refresh_interval" default:"5m" required:"true" usage:"how long the user-service's public keys are cached"`
 }
 
-func NewConfig() *Config {
-	dsn := os.Getenv("POSTGRES_URL")
-	db, err := sqlx.Connect("postgres", dsn)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     "localhost:6379",
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
-
-	return &Config{
-		DB:    db,
-		Redis: rdb,
+// Load reads the settings and fails naming every one that is missing or
+// invalid.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := settings.Load(cfg, EnvPrefix); err != nil {
+		return nil, err
 	}
+	return cfg, nil
 }
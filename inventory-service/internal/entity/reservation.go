@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReservationStatusReserved  = "reserved"
+	ReservationStatusCommitted = "committed"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+)
+
+type ReservationItem struct {
+	ID            uuid.UUID `db:"id"`
+	ReservationID uuid.UUID `db:"reservation_id"`
+	ProductID     uuid.UUID `db:"product_id"`
+	Quantity      int       `db:"quantity"`
+}
+
+type Reservation struct {
+	ID        uuid.UUID          `db:"id"`
+	OrderID   string             `db:"order_id"`
+	Status    string             `db:"status"`
+	ExpiresAt time.Time          `db:"expires_at"`
+	CreatedAt time.Time          `db:"created_at"`
+	Items     []*ReservationItem `db:"-"`
+}
@@ -2,18 +2,18 @@ package handler
 
 import (
 	"E-Commerce/inventory-service/internal/entity"
+	"E-Commerce/inventory-service/internal/repository"
 	"E-Commerce/inventory-service/internal/service"
 	pb "E-Commerce/inventory-service/proto"
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-var ErrProductNotFound = errors.New("product not found")
-
 type InventoryGRPCServer struct {
 	pb.UnimplementedInventoryServiceServer
 	svc service.InventoryService
@@ -56,14 +56,11 @@ func (s *InventoryGRPCServer) GetProduct(ctx context.Context, req *pb.GetProduct
 	}
 	p, err := s.svc.GetProduct(id)
 	if err != nil {
-		if errors.Is(err, ErrProductNotFound) {
-			return &pb.GetProductResponse{Product: nil}, nil
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return nil, status.Error(codes.NotFound, "product not found")
 		}
 		return nil, status.Error(codes.Internal, "failed to get product")
 	}
-	if p == nil {
-		return &pb.GetProductResponse{Product: nil}, nil
-	}
 	return &pb.GetProductResponse{
 		Product: &pb.Product{
 			Id:          p.ID.String(),
@@ -93,7 +90,7 @@ func (s *InventoryGRPCServer) UpdateProduct(ctx context.Context, req *pb.UpdateP
 
 	err = s.svc.UpdateProduct(p)
 	if err != nil {
-		if errors.Is(err, ErrProductNotFound) {
+		if errors.Is(err, repository.ErrProductNotFound) {
 			return nil, status.Error(codes.NotFound, "product not found")
 		}
 		return nil, status.Error(codes.Internal, "failed to update product")
@@ -118,7 +115,7 @@ func (s *InventoryGRPCServer) DeleteProduct(ctx context.Context, req *pb.DeleteP
 	}
 	err = s.svc.DeleteProduct(id)
 	if err != nil {
-		if errors.Is(err, ErrProductNotFound) {
+		if errors.Is(err, repository.ErrProductNotFound) {
 			return nil, status.Error(codes.NotFound, "product not found")
 		}
 		return nil, status.Error(codes.Internal, "failed to delete product")
@@ -157,7 +154,7 @@ func (s *InventoryGRPCServer) CheckStock(ctx context.Context, req *pb.CheckStock
 	}
 	available, err := s.svc.CheckStock(pid, int(req.Quantity))
 	if err != nil {
-		if errors.Is(err, ErrProductNotFound) {
+		if errors.Is(err, repository.ErrProductNotFound) {
 			return nil, status.Error(codes.NotFound, "product not found")
 		}
 		return nil, status.Error(codes.Internal, "failed to check stock")
@@ -172,10 +169,77 @@ func (s *InventoryGRPCServer) UpdateStock(ctx context.Context, req *pb.UpdateSto
 	}
 	err = s.svc.UpdateStock(pid, int(req.Quantity), req.OrderId)
 	if err != nil {
-		if errors.Is(err, ErrProductNotFound) {
+		if errors.Is(err, repository.ErrProductNotFound) {
 			return nil, status.Error(codes.NotFound, "product not found")
 		}
+		if errors.Is(err, repository.ErrStockAlreadyApplied) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
 		return nil, status.Error(codes.Internal, "failed to update stock")
 	}
 	return &pb.UpdateStockResponse{Success: true}, nil
 }
+
+func (s *InventoryGRPCServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	items := make([]*entity.ReservationItem, len(req.Items))
+	for i, item := range req.Items {
+		pid, err := uuid.Parse(item.ProductId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid product ID")
+		}
+		items[i] = &entity.ReservationItem{
+			ProductID: pid,
+			Quantity:  int(item.Quantity),
+		}
+	}
+
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	res, err := s.svc.ReserveStock(req.OrderId, items, ttl)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidReservation):
+			return nil, status.Error(codes.InvalidArgument, "reservation needs an order ID and positive quantities")
+		case errors.Is(err, repository.ErrInsufficientStock):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, repository.ErrProductNotFound):
+			return nil, status.Error(codes.NotFound, "product not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to reserve stock")
+	}
+	return &pb.ReserveStockResponse{
+		ReservationId: res.ID.String(),
+		ExpiresAt:     res.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (s *InventoryGRPCServer) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+	id, err := uuid.Parse(req.ReservationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid reservation ID")
+	}
+	if err := s.svc.CommitReservation(id); err != nil {
+		return nil, reservationError(err, "failed to commit reservation")
+	}
+	return &pb.CommitReservationResponse{Success: true}, nil
+}
+
+func (s *InventoryGRPCServer) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	id, err := uuid.Parse(req.ReservationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid reservation ID")
+	}
+	if err := s.svc.ReleaseReservation(id); err != nil {
+		return nil, reservationError(err, "failed to release reservation")
+	}
+	return &pb.ReleaseReservationResponse{Success: true}, nil
+}
+
+func reservationError(err error, msg string) error {
+	switch {
+	case errors.Is(err, repository.ErrReservationNotFound):
+		return status.Error(codes.NotFound, "reservation not found")
+	case errors.Is(err, repository.ErrReservationNotActive), errors.Is(err, repository.ErrInsufficientStock):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, msg)
+}
@@ -2,15 +2,20 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"E-Commerce/inventory-service/internal/entity"
+	"E-Commerce/inventory-service/internal/repository"
 	"E-Commerce/inventory-service/internal/service"
 	pb "E-Commerce/inventory-service/proto"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Mock repository
@@ -56,15 +61,42 @@ func (m *mockProductRepository) UpdateStock(productID uuid.UUID, quantity int, o
 	return args.Error(0)
 }
 
+// Mock reservation repository
+type mockReservationRepository struct {
+	mock.Mock
+}
+
+func (m *mockReservationRepository) Reserve(res *entity.Reservation) error {
+	args := m.Called(res)
+	return args.Error(0)
+}
+
+func (m *mockReservationRepository) Commit(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *mockReservationRepository) Release(id uuid.UUID, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *mockReservationRepository) ListExpired(before time.Time, limit int) ([]uuid.UUID, error) {
+	args := m.Called(before, limit)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
 type InventoryTestSuite struct {
 	suite.Suite
-	repo   *mockProductRepository
-	server *InventoryGRPCServer
+	repo         *mockProductRepository
+	reservations *mockReservationRepository
+	server       *InventoryGRPCServer
 }
 
 func (s *InventoryTestSuite) SetupTest() {
 	s.repo = new(mockProductRepository)
-	svc := service.NewInventoryService(s.repo)
+	s.reservations = new(mockReservationRepository)
+	svc := service.NewInventoryService(s.repo, s.reservations)
 	s.server = NewInventoryGRPCServer(svc)
 }
 
@@ -213,7 +245,7 @@ func (s *InventoryTestSuite) TestProductCRUD() {
 	s.repo.On("Delete", productID).Return(nil)
 
 	// Setup mock for Get after delete
-	s.repo.On("Get", productID).Return(nil, nil).Once()
+	s.repo.On("Get", productID).Return(nil, fmt.Errorf("%w: %s", repository.ErrProductNotFound, productID)).Once()
 
 	// Test Delete
 	deleteReq := &pb.DeleteProductRequest{
@@ -225,14 +257,102 @@ func (s *InventoryTestSuite) TestProductCRUD() {
 	s.True(deleteResp.Success)
 
 	// Test Get after delete
-	getResp, err = s.server.GetProduct(ctx, getReq)
-	s.NoError(err)
-	s.Nil(getResp.Product)
+	_, err = s.server.GetProduct(ctx, getReq)
+	s.Equal(codes.NotFound, status.Code(err))
 
 	// Verify all mocked calls were made
 	s.repo.AssertExpectations(s.T())
 }
 
+func (s *InventoryTestSuite) TestReserveStock() {
+	ctx := context.Background()
+	orderID := uuid.New().String()
+	first, second := uuid.New(), uuid.New()
+
+	// Duplicate products are merged into one line before reserving
+	s.reservations.On("Reserve", mock.MatchedBy(func(res *entity.Reservation) bool {
+		return res.OrderID == orderID && len(res.Items) == 2 &&
+			res.Status == entity.ReservationStatusReserved &&
+			res.ExpiresAt.After(time.Now().Add(4*time.Minute))
+	})).Return(nil).Once()
+
+	resp, err := s.server.ReserveStock(ctx, &pb.ReserveStockRequest{
+		OrderId: orderID,
+		Items: []*pb.ReservationItem{
+			{ProductId: first.String(), Quantity: 2},
+			{ProductId: second.String(), Quantity: 1},
+			{ProductId: first.String(), Quantity: 3},
+		},
+		TtlSeconds: 300,
+	})
+	s.NoError(err)
+	s.NotEmpty(resp.ReservationId)
+
+	res := s.reservations.Calls[0].Arguments.Get(0).(*entity.Reservation)
+	for _, item := range res.Items {
+		if item.ProductID == first {
+			s.Equal(5, item.Quantity)
+		}
+	}
+
+	// Insufficient stock surfaces as FailedPrecondition
+	s.reservations.On("Reserve", mock.AnythingOfType("*entity.Reservation")).
+		Return(fmt.Errorf("%w: product %s has 0, requested 1", repository.ErrInsufficientStock, first)).Once()
+
+	_, err = s.server.ReserveStock(ctx, &pb.ReserveStockRequest{
+		OrderId: orderID,
+		Items:   []*pb.ReservationItem{{ProductId: first.String(), Quantity: 1}},
+	})
+	s.Equal(codes.FailedPrecondition, status.Code(err))
+
+	// Non-positive quantities are rejected before touching the repository
+	_, err = s.server.ReserveStock(ctx, &pb.ReserveStockRequest{
+		OrderId: orderID,
+		Items:   []*pb.ReservationItem{{ProductId: first.String(), Quantity: 0}},
+	})
+	s.Equal(codes.InvalidArgument, status.Code(err))
+
+	// Committing and releasing map repository errors to gRPC codes
+	resID := uuid.New()
+	s.reservations.On("Commit", resID).Return(nil).Once()
+	commitResp, err := s.server.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: resID.String()})
+	s.NoError(err)
+	s.True(commitResp.Success)
+
+	// An expired reservation whose stock ran out cannot be committed
+	expiredID := uuid.New()
+	s.reservations.On("Commit", expiredID).Return(fmt.Errorf("%w: reservation %s expired", repository.ErrInsufficientStock, expiredID)).Once()
+	_, err = s.server.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: expiredID.String()})
+	s.Equal(codes.FailedPrecondition, status.Code(err))
+
+	s.reservations.On("Release", resID, entity.ReservationStatusReleased).Return(repository.ErrReservationNotActive).Once()
+	_, err = s.server.ReleaseReservation(ctx, &pb.ReleaseReservationRequest{ReservationId: resID.String()})
+	s.Equal(codes.FailedPrecondition, status.Code(err))
+
+	s.reservations.AssertExpectations(s.T())
+}
+
+func (s *InventoryTestSuite) TestDuplicateStockUpdate() {
+	ctx := context.Background()
+	productID := uuid.New()
+	orderID := uuid.New().String()
+
+	s.repo.On("UpdateStock", productID, -2, orderID).Return(nil).Once()
+	s.repo.On("UpdateStock", productID, -2, orderID).
+		Return(fmt.Errorf("%w: product %s, order %s", repository.ErrStockAlreadyApplied, productID, orderID)).Once()
+
+	req := &pb.UpdateStockRequest{ProductId: productID.String(), Quantity: -2, OrderId: orderID}
+	resp, err := s.server.UpdateStock(ctx, req)
+	s.NoError(err)
+	s.True(resp.Success)
+
+	// The second application for the same order and product is rejected
+	_, err = s.server.UpdateStock(ctx, req)
+	s.Equal(codes.AlreadyExists, status.Code(err))
+
+	s.repo.AssertExpectations(s.T())
+}
+
 func TestInventoryService(t *testing.T) {
 	suite.Run(t, new(InventoryTestSuite))
 }
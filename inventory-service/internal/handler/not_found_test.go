@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"E-Commerce/inventory-service/internal/repository"
+	"E-Commerce/inventory-service/internal/service"
+	pb "E-Commerce/inventory-service/proto"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// emptyDB is a database/sql driver for a database without any rows: queries
+// return nothing and statements change nothing.
+type emptyDB struct{}
+
+func (emptyDB) Connect(context.Context) (driver.Conn, error) { return emptyDB{}, nil }
+func (emptyDB) Driver() driver.Driver                        { return emptyDB{} }
+func (emptyDB) Open(string) (driver.Conn, error)             { return emptyDB{}, nil }
+func (emptyDB) Prepare(string) (driver.Stmt, error)          { return emptyDB{}, nil }
+func (emptyDB) Begin() (driver.Tx, error)                    { return emptyDB{}, nil }
+func (emptyDB) Commit() error                                { return nil }
+func (emptyDB) Rollback() error                              { return nil }
+func (emptyDB) Close() error                                 { return nil }
+func (emptyDB) NumInput() int                                { return -1 }
+func (emptyDB) Exec([]driver.Value) (driver.Result, error)   { return driver.RowsAffected(0), nil }
+func (emptyDB) Query([]driver.Value) (driver.Rows, error)    { return emptyDB{}, nil }
+func (emptyDB) Columns() []string                            { return []string{"stock"} }
+func (emptyDB) Next([]driver.Value) error                    { return io.EOF }
+
+func TestUnknownProductIsNotFound(t *testing.T) {
+	db := sqlx.NewDb(sql.OpenDB(emptyDB{}), "postgres")
+	// Nothing listens here, so every cache lookup misses
+	cache := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer cache.Close()
+	server := NewInventoryGRPCServer(service.NewInventoryService(
+		repository.NewProductRepository(db, cache),
+		repository.NewReservationRepository(db, cache),
+	))
+	ctx := context.Background()
+	id := uuid.NewString()
+
+	calls := map[string]func() error{
+		"GetProduct": func() error {
+			_, err := server.GetProduct(ctx, &pb.GetProductRequest{Id: id})
+			return err
+		},
+		"UpdateProduct": func() error {
+			_, err := server.UpdateProduct(ctx, &pb.UpdateProductRequest{Id: id, Name: "Renamed"})
+			return err
+		},
+		"DeleteProduct": func() error {
+			_, err := server.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: id})
+			return err
+		},
+		"CheckStock": func() error {
+			_, err := server.CheckStock(ctx, &pb.CheckStockRequest{ProductId: id, Quantity: 1})
+			return err
+		},
+		"UpdateStock": func() error {
+			_, err := server.UpdateStock(ctx, &pb.UpdateStockRequest{ProductId: id, Quantity: -1})
+			return err
+		},
+		"ReserveStock": func() error {
+			_, err := server.ReserveStock(ctx, &pb.ReserveStockRequest{
+				OrderId: uuid.NewString(),
+				Items:   []*pb.ReservationItem{{ProductId: id, Quantity: 1}},
+			})
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, codes.NotFound, status.Code(call()))
+		})
+	}
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"E-Commerce/inventory-service/internal/entity"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -13,6 +15,14 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+var (
+	// ErrProductNotFound is returned when no product has the given ID.
+	ErrProductNotFound = errors.New("product not found")
+	// ErrStockAlreadyApplied is returned when an order tries to change a
+	// product's stock a second time.
+	ErrStockAlreadyApplied = errors.New("stock change already applied for this order")
+)
+
 type ProductRepository interface {
 	Create(p *entity.Product) error
 	Get(id uuid.UUID) (*entity.Product, error)
@@ -63,6 +73,9 @@ func (r *productRepository) Get(id uuid.UUID) (*entity.Product, error) {
 	// If not in cache, get from database
 	var product entity.Product
 	err = r.db.Get(&product, "SELECT * FROM products WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrProductNotFound, id)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +89,7 @@ func (r *productRepository) Get(id uuid.UUID) (*entity.Product, error) {
 }
 
 func (r *productRepository) Update(p *entity.Product) error {
-	_, err := r.db.NamedExec(`
+	result, err := r.db.NamedExec(`
 		UPDATE products 
 		SET name = :name, description = :description, price = :price, 
 			stock = :stock, category_id = :category_id
@@ -86,6 +99,9 @@ func (r *productRepository) Update(p *entity.Product) error {
 	if err != nil {
 		return err
 	}
+	if err := productAffected(result, p.ID); err != nil {
+		return err
+	}
 
 	// Invalidate cache
 	ctx := context.Background()
@@ -95,10 +111,13 @@ func (r *productRepository) Update(p *entity.Product) error {
 }
 
 func (r *productRepository) Delete(id uuid.UUID) error {
-	_, err := r.db.Exec("DELETE FROM products WHERE id = $1", id)
+	result, err := r.db.Exec("DELETE FROM products WHERE id = $1", id)
 	if err != nil {
 		return err
 	}
+	if err := productAffected(result, id); err != nil {
+		return err
+	}
 
 	// Invalidate cache
 	ctx := context.Background()
@@ -140,6 +159,9 @@ func (r *productRepository) List(categoryID string, page, pageSize int) ([]*enti
 func (r *productRepository) CheckStock(productID uuid.UUID, quantity int) (bool, error) {
 	var stock int
 	err := r.db.Get(&stock, "SELECT stock FROM products WHERE id = $1", productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("%w: %s", ErrProductNotFound, productID)
+	}
 	if err != nil {
 		return false, err
 	}
@@ -153,13 +175,30 @@ func (r *productRepository) UpdateStock(productID uuid.UUID, quantity int, order
 	}
 	defer tx.Rollback()
 
-	// Get current stock within transaction
+	// Get current stock within transaction, locking the row so concurrent
+	// updates of the same product are serialized
 	var currentStock int
-	err = tx.Get(&currentStock, "SELECT stock FROM products WHERE id = $1", productID)
+	err = tx.Get(&currentStock, "SELECT stock FROM products WHERE id = $1 FOR UPDATE", productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrProductNotFound, productID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get current stock: %v", err)
 	}
 
+	// A stock change for an order is applied at most once per product, so
+	// redelivered events and retried cancellations cannot double count
+	if orderID != "" {
+		applied, err := orderStockApplied(tx, productID, orderID, quantity)
+		if err != nil {
+			return err
+		}
+		if applied {
+			log.Printf("Rejecting duplicate stock update for product %s (change: %d) [order: %s]", productID, quantity, orderID)
+			return fmt.Errorf("%w: product %s, order %s", ErrStockAlreadyApplied, productID, orderID)
+		}
+	}
+
 	// Calculate new stock
 	newStock := currentStock + quantity
 	if newStock < 0 {
@@ -209,6 +248,49 @@ func (r *productRepository) UpdateStock(productID uuid.UUID, quantity int, order
 	return nil
 }
 
+// productAffected returns ErrProductNotFound when a statement on product id
+// changed no rows.
+func productAffected(result sql.Result, id uuid.UUID) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrProductNotFound, id)
+	}
+	return nil
+}
+
+// orderStockApplied reports whether the order already changed the product's
+// stock in the direction of quantity. A decrement counts as applied while
+// the order holds stock through an ORDER deduction or an unreleased
+// reservation, which is what keeps an order.created event from taking stock
+// a second time after CreateOrder reserved it. A restock counts as applied
+// once a RESTOCK was logged.
+func orderStockApplied(tx *sqlx.Tx, productID uuid.UUID, orderID string, quantity int) (bool, error) {
+	if quantity > 0 {
+		var restocks int
+		err := tx.Get(&restocks, `
+			SELECT COUNT(*) FROM stock_logs
+			WHERE order_id = $1 AND product_id = $2 AND operation_type = 'RESTOCK'`,
+			orderID, productID)
+		if err != nil {
+			return false, fmt.Errorf("failed to check stock logs: %v", err)
+		}
+		return restocks > 0, nil
+	}
+
+	var held int
+	err := tx.Get(&held, `
+		SELECT COALESCE(SUM(change_amount), 0) FROM stock_logs
+		WHERE order_id = $1 AND product_id = $2 AND operation_type IN ('ORDER', 'RESERVE', 'RELEASE')`,
+		orderID, productID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check stock logs: %v", err)
+	}
+	return held < 0, nil
+}
+
 func getOperationType(quantity int) string {
 	if quantity > 0 {
 		return "RESTOCK"
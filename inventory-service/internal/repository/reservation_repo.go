@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"E-Commerce/inventory-service/internal/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationNotActive = errors.New("reservation is no longer active")
+)
+
+type ReservationRepository interface {
+	Reserve(res *entity.Reservation) error
+	Commit(id uuid.UUID) error
+	Release(id uuid.UUID, status string) error
+	ListExpired(before time.Time, limit int) ([]uuid.UUID, error)
+}
+
+type reservationRepository struct {
+	db    *sqlx.DB
+	redis *redis.Client
+}
+
+func NewReservationRepository(db *sqlx.DB, redis *redis.Client) ReservationRepository {
+	return &reservationRepository{
+		db:    db,
+		redis: redis,
+	}
+}
+
+// Reserve holds stock for every item of res in a single transaction. The
+// product rows are locked in the order of res.Items, so callers should sort
+// the items to keep concurrent reservations from deadlocking.
+func (r *reservationRepository) Reserve(res *entity.Reservation) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.NamedExec(`
+		INSERT INTO reservations (id, order_id, status, expires_at, created_at)
+		VALUES (:id, :order_id, :status, :expires_at, :created_at)`,
+		res)
+	if err != nil {
+		return fmt.Errorf("failed to create reservation: %v", err)
+	}
+
+	for _, item := range res.Items {
+		var currentStock int
+		err = tx.Get(&currentStock, "SELECT stock FROM products WHERE id = $1 FOR UPDATE", item.ProductID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrProductNotFound, item.ProductID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get current stock for product %s: %w", item.ProductID, err)
+		}
+		if currentStock < item.Quantity {
+			return fmt.Errorf("%w: product %s has %d, requested %d", ErrInsufficientStock, item.ProductID, currentStock, item.Quantity)
+		}
+
+		if err := adjustStock(tx, item.ProductID, currentStock, -item.Quantity, "RESERVE", res.OrderID); err != nil {
+			return err
+		}
+
+		item.ID = uuid.New()
+		item.ReservationID = res.ID
+		_, err = tx.NamedExec(`
+			INSERT INTO reservation_items (id, reservation_id, product_id, quantity)
+			VALUES (:id, :reservation_id, :product_id, :quantity)`,
+			item)
+		if err != nil {
+			return fmt.Errorf("failed to create reservation item: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.invalidateProducts(res.Items)
+	log.Printf("Reserved stock for order %s (reservation %s, %d items, expires %s)",
+		res.OrderID, res.ID, len(res.Items), res.ExpiresAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// Commit turns a held reservation into a permanent stock decrement. The
+// stock was already taken off at reservation time, so only the status
+// changes. A reservation that expired before its commit arrived takes its
+// stock again, or fails with ErrInsufficientStock if the stock is gone.
+// Committing twice is a no-op.
+func (r *reservationRepository) Commit(id uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	res, err := lockReservation(tx, id)
+	if err != nil {
+		return err
+	}
+	var retaken []*entity.ReservationItem
+	switch res.Status {
+	case entity.ReservationStatusCommitted:
+		return nil
+	case entity.ReservationStatusReserved:
+	case entity.ReservationStatusExpired:
+		// The sweeper gave the stock back, but the order was placed
+		if retaken, err = retakeStock(tx, res); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: reservation %s is %s", ErrReservationNotActive, id, res.Status)
+	}
+
+	_, err = tx.Exec("UPDATE reservations SET status = $1 WHERE id = $2", entity.ReservationStatusCommitted, id)
+	if err != nil {
+		return fmt.Errorf("failed to commit reservation: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	if retaken != nil {
+		r.invalidateProducts(retaken)
+		log.Printf("Took stock again for expired reservation %s of order %s", id, res.OrderID)
+	}
+	return nil
+}
+
+// retakeStock takes the stock of an expired reservation off again, in the
+// same order Reserve locks products in.
+func retakeStock(tx *sqlx.Tx, res *entity.Reservation) ([]*entity.ReservationItem, error) {
+	var items []*entity.ReservationItem
+	err := tx.Select(&items, "SELECT * FROM reservation_items WHERE reservation_id = $1 ORDER BY product_id", res.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation items: %v", err)
+	}
+
+	for _, item := range items {
+		var currentStock int
+		err = tx.Get(&currentStock, "SELECT stock FROM products WHERE id = $1 FOR UPDATE", item.ProductID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrProductNotFound, item.ProductID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current stock for product %s: %w", item.ProductID, err)
+		}
+		if currentStock < item.Quantity {
+			return nil, fmt.Errorf("%w: reservation %s expired and product %s has %d, requested %d",
+				ErrInsufficientStock, res.ID, item.ProductID, currentStock, item.Quantity)
+		}
+		if err := adjustStock(tx, item.ProductID, currentStock, -item.Quantity, "RESERVE", res.OrderID); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// Release gives the held stock back and marks the reservation with status
+// (released or expired). Releasing an already released or expired
+// reservation is a no-op.
+func (r *reservationRepository) Release(id uuid.UUID, status string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	res, err := lockReservation(tx, id)
+	if err != nil {
+		return err
+	}
+	switch res.Status {
+	case entity.ReservationStatusReleased, entity.ReservationStatusExpired:
+		return nil
+	case entity.ReservationStatusReserved:
+	default:
+		return fmt.Errorf("%w: reservation %s is %s", ErrReservationNotActive, id, res.Status)
+	}
+
+	var items []*entity.ReservationItem
+	err = tx.Select(&items, "SELECT * FROM reservation_items WHERE reservation_id = $1 ORDER BY product_id", id)
+	if err != nil {
+		return fmt.Errorf("failed to get reservation items: %v", err)
+	}
+
+	for _, item := range items {
+		var currentStock int
+		err = tx.Get(&currentStock, "SELECT stock FROM products WHERE id = $1 FOR UPDATE", item.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to get current stock for product %s: %w", item.ProductID, err)
+		}
+		if err := adjustStock(tx, item.ProductID, currentStock, item.Quantity, "RELEASE", res.OrderID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE reservations SET status = $1 WHERE id = $2", status, id)
+	if err != nil {
+		return fmt.Errorf("failed to release reservation: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.invalidateProducts(items)
+	log.Printf("Released reservation %s for order %s (%s)", id, res.OrderID, status)
+
+	return nil
+}
+
+func (r *reservationRepository) ListExpired(before time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Select(&ids, `
+		SELECT id FROM reservations
+		WHERE status = $1 AND expires_at < $2
+		ORDER BY expires_at
+		LIMIT $3`,
+		entity.ReservationStatusReserved, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *reservationRepository) invalidateProducts(items []*entity.ReservationItem) {
+	ctx := context.Background()
+	for _, item := range items {
+		r.redis.Del(ctx, productCacheKeyPrefix+item.ProductID.String())
+	}
+}
+
+func lockReservation(tx *sqlx.Tx, id uuid.UUID) (*entity.Reservation, error) {
+	var res entity.Reservation
+	err := tx.Get(&res, "SELECT * FROM reservations WHERE id = $1 FOR UPDATE", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation: %v", err)
+	}
+	return &res, nil
+}
+
+// adjustStock applies change to a product row that the caller has already
+// locked and records the movement in stock_logs.
+func adjustStock(tx *sqlx.Tx, productID uuid.UUID, currentStock, change int, operationType, orderID string) error {
+	newStock := currentStock + change
+	_, err := tx.Exec("UPDATE products SET stock = $1 WHERE id = $2", newStock, productID)
+	if err != nil {
+		return fmt.Errorf("failed to update stock: %v", err)
+	}
+
+	stockLog := &entity.StockLog{
+		ID:            uuid.New(),
+		ProductID:     productID,
+		PreviousStock: currentStock,
+		NewStock:      newStock,
+		ChangeAmount:  change,
+		OperationType: operationType,
+		CreatedAt:     time.Now(),
+		OrderID:       orderID,
+	}
+	_, err = tx.NamedExec(`
+		INSERT INTO stock_logs
+		(id, product_id, previous_stock, new_stock, change_amount, operation_type, created_at, order_id)
+		VALUES
+		(:id, :product_id, :previous_stock, :new_stock, :change_amount, :operation_type, :created_at, :order_id)`,
+		stockLog)
+	if err != nil {
+		return fmt.Errorf("failed to create stock log: %v", err)
+	}
+	return nil
+}
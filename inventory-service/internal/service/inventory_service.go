@@ -3,10 +3,19 @@ package service
 import (
 	"E-Commerce/inventory-service/internal/entity"
 	"E-Commerce/inventory-service/internal/repository"
+	"errors"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// DefaultReservationTTL is used when a caller does not ask for a specific
+// reservation lifetime.
+const DefaultReservationTTL = 15 * time.Minute
+
+var ErrInvalidReservation = errors.New("invalid reservation")
+
 type InventoryService interface {
 	CreateProduct(p *entity.Product) error
 	GetProduct(id uuid.UUID) (*entity.Product, error)
@@ -15,14 +24,22 @@ type InventoryService interface {
 	ListProducts(categoryID string, page, pageSize int) ([]*entity.Product, int, error)
 	CheckStock(productID uuid.UUID, quantity int) (bool, error)
 	UpdateStock(productID uuid.UUID, quantity int, orderID string) error
+	ReserveStock(orderID string, items []*entity.ReservationItem, ttl time.Duration) (*entity.Reservation, error)
+	CommitReservation(id uuid.UUID) error
+	ReleaseReservation(id uuid.UUID) error
+	ReleaseExpiredReservations(limit int) (int, error)
 }
 
 type inventoryService struct {
-	repo repository.ProductRepository
+	repo         repository.ProductRepository
+	reservations repository.ReservationRepository
 }
 
-func NewInventoryService(repo repository.ProductRepository) InventoryService {
-	return &inventoryService{repo: repo}
+func NewInventoryService(repo repository.ProductRepository, reservations repository.ReservationRepository) InventoryService {
+	return &inventoryService{
+		repo:         repo,
+		reservations: reservations,
+	}
 }
 
 func (s *inventoryService) CreateProduct(p *entity.Product) error {
@@ -53,3 +70,75 @@ func (s *inventoryService) CheckStock(productID uuid.UUID, quantity int) (bool,
 func (s *inventoryService) UpdateStock(productID uuid.UUID, quantity int, orderID string) error {
 	return s.repo.UpdateStock(productID, quantity, orderID)
 }
+
+func (s *inventoryService) ReserveStock(orderID string, items []*entity.ReservationItem, ttl time.Duration) (*entity.Reservation, error) {
+	if orderID == "" || len(items) == 0 {
+		return nil, ErrInvalidReservation
+	}
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+
+	// Merge duplicate products and lock rows in a stable order so two
+	// reservations touching the same products cannot deadlock.
+	merged := make(map[uuid.UUID]*entity.ReservationItem)
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, ErrInvalidReservation
+		}
+		if m, ok := merged[item.ProductID]; ok {
+			m.Quantity += item.Quantity
+			continue
+		}
+		merged[item.ProductID] = &entity.ReservationItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	sorted := make([]*entity.ReservationItem, 0, len(merged))
+	for _, item := range merged {
+		sorted = append(sorted, item)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ProductID.String() < sorted[j].ProductID.String()
+	})
+
+	now := time.Now()
+	res := &entity.Reservation{
+		ID:        uuid.New(),
+		OrderID:   orderID,
+		Status:    entity.ReservationStatusReserved,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		Items:     sorted,
+	}
+	if err := s.reservations.Reserve(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *inventoryService) CommitReservation(id uuid.UUID) error {
+	return s.reservations.Commit(id)
+}
+
+func (s *inventoryService) ReleaseReservation(id uuid.UUID) error {
+	return s.reservations.Release(id, entity.ReservationStatusReleased)
+}
+
+// ReleaseExpiredReservations returns the stock of up to limit reservations
+// whose TTL has passed without a commit, and reports how many were released.
+func (s *inventoryService) ReleaseExpiredReservations(limit int) (int, error) {
+	ids, err := s.reservations.ListExpired(time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+	released := 0
+	for _, id := range ids {
+		if err := s.reservations.Release(id, entity.ReservationStatusExpired); err != nil {
+			if errors.Is(err, repository.ErrReservationNotActive) {
+				continue
+			}
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
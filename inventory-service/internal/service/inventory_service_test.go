@@ -0,0 +1,129 @@
+package service
+
+import (
+	"E-Commerce/inventory-service/internal/entity"
+	"E-Commerce/inventory-service/internal/repository"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testService wires the service to the PostgreSQL server at
+// INVENTORY_SERVICE_TEST_POSTGRES_URL, in a freshly migrated schema of the
+// test's own. The test is skipped without a server.
+func testService(t *testing.T) (InventoryService, *sqlx.DB) {
+	t.Helper()
+	url := os.Getenv("INVENTORY_SERVICE_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("INVENTORY_SERVICE_TEST_POSTGRES_URL is not set")
+	}
+
+	admin, err := sqlx.Connect("postgres", url)
+	require.NoError(t, err)
+	_, err = admin.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+	require.NoError(t, err)
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+	db, err := sqlx.Connect("postgres", url+sep+"search_path="+schema+",public")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	files, err := filepath.Glob("../../migrations/*.up.sql")
+	require.NoError(t, err)
+	sort.Strings(files)
+	for _, file := range files {
+		query, err := os.ReadFile(file)
+		require.NoError(t, err)
+		_, err = db.Exec(string(query))
+		require.NoError(t, err, "migration %s", filepath.Base(file))
+	}
+
+	// Nothing listens here, so every cache lookup misses
+	cache := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	t.Cleanup(func() { cache.Close() })
+	svc := NewInventoryService(
+		repository.NewProductRepository(db, cache),
+		repository.NewReservationRepository(db, cache),
+	)
+	return svc, db
+}
+
+func stockOf(t *testing.T, svc InventoryService, id uuid.UUID) int {
+	t.Helper()
+	p, err := svc.GetProduct(id)
+	require.NoError(t, err)
+	return p.Stock
+}
+
+func TestCommitAfterReservationExpired(t *testing.T) {
+	svc, db := testService(t)
+
+	tests := []struct {
+		name string
+		// sold is how much of the released stock other orders take before
+		// the commit arrives
+		sold      int
+		wantErr   error
+		wantStock int
+	}{
+		{name: "Stock Still There", sold: 0, wantStock: 2},
+		{name: "Stock Sold Meanwhile", sold: 4, wantErr: repository.ErrInsufficientStock, wantStock: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := &entity.Product{Name: "Widget", Price: 10, Stock: 5}
+			require.NoError(t, svc.CreateProduct(product))
+			orderID := uuid.NewString()
+
+			res, err := svc.ReserveStock(orderID, []*entity.ReservationItem{{ProductID: product.ID, Quantity: 3}}, time.Millisecond)
+			require.NoError(t, err)
+			assert.Equal(t, 2, stockOf(t, svc, product.ID))
+
+			// The sweeper runs before the outbox relay gets to the commit
+			time.Sleep(10 * time.Millisecond)
+			released, err := svc.ReleaseExpiredReservations(sweepBatchSize)
+			require.NoError(t, err)
+			assert.Equal(t, 1, released)
+			assert.Equal(t, 5, stockOf(t, svc, product.ID))
+
+			if tt.sold > 0 {
+				require.NoError(t, svc.UpdateStock(product.ID, -tt.sold, uuid.NewString()))
+			}
+
+			err = svc.CommitReservation(res.ID)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantStock, stockOf(t, svc, product.ID))
+
+			var status string
+			require.NoError(t, db.Get(&status, "SELECT status FROM reservations WHERE id = $1", res.ID))
+			if tt.wantErr == nil {
+				assert.Equal(t, entity.ReservationStatusCommitted, status)
+				// A redelivered order.created event must not take the stock twice
+				assert.ErrorIs(t, svc.UpdateStock(product.ID, -3, orderID), repository.ErrStockAlreadyApplied)
+			} else {
+				assert.Equal(t, entity.ReservationStatusExpired, status)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const sweepBatchSize = 100
+
+// ReservationSweeper periodically releases reservations that expired
+// without being committed, so abandoned checkouts give their stock back.
+type ReservationSweeper struct {
+	svc      InventoryService
+	interval time.Duration
+}
+
+func NewReservationSweeper(svc InventoryService, interval time.Duration) *ReservationSweeper {
+	return &ReservationSweeper{svc: svc, interval: interval}
+}
+
+// Run sweeps until ctx is cancelled.
+func (w *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.sweep()
+		}
+	}
+}
+
+func (w *ReservationSweeper) sweep() {
+	for {
+		released, err := w.svc.ReleaseExpiredReservations(sweepBatchSize)
+		if err != nil {
+			log.Printf("Failed to release expired reservations: %v", err)
+			return
+		}
+		if released > 0 {
+			log.Printf("Released %d expired reservations", released)
+		}
+		if released < sweepBatchSize {
+			return
+		}
+	}
+}
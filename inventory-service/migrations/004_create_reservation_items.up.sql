@@ -0,0 +1,6 @@
+CREATE TABLE reservation_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    reservation_id UUID NOT NULL REFERENCES reservations (id),
+    product_id UUID NOT NULL REFERENCES products (id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
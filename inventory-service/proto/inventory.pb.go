@@ -841,6 +841,346 @@ func (x *UpdateStockResponse) GetSuccess() bool {
 	return false
 }
 
+type ReservationItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReservationItem) Reset() {
+	*x = ReservationItem{}
+	mi := &file_proto_inventory_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReservationItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservationItem) ProtoMessage() {}
+
+func (x *ReservationItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservationItem.ProtoReflect.Descriptor instead.
+func (*ReservationItem) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReservationItem) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ReservationItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type ReserveStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Items         []*ReservationItem     `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	TtlSeconds    int32                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockRequest) Reset() {
+	*x = ReserveStockRequest{}
+	mi := &file_proto_inventory_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockRequest) ProtoMessage() {}
+
+func (x *ReserveStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockRequest.ProtoReflect.Descriptor instead.
+func (*ReserveStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReserveStockRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *ReserveStockRequest) GetItems() []*ReservationItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ReserveStockRequest) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type ReserveStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId string                 `protobuf:"bytes,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockResponse) Reset() {
+	*x = ReserveStockResponse{}
+	mi := &file_proto_inventory_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockResponse) ProtoMessage() {}
+
+func (x *ReserveStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockResponse.ProtoReflect.Descriptor instead.
+func (*ReserveStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReserveStockResponse) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+func (x *ReserveStockResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type CommitReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId string                 `protobuf:"bytes,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitReservationRequest) Reset() {
+	*x = CommitReservationRequest{}
+	mi := &file_proto_inventory_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitReservationRequest) ProtoMessage() {}
+
+func (x *CommitReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitReservationRequest.ProtoReflect.Descriptor instead.
+func (*CommitReservationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CommitReservationRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+type CommitReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitReservationResponse) Reset() {
+	*x = CommitReservationResponse{}
+	mi := &file_proto_inventory_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitReservationResponse) ProtoMessage() {}
+
+func (x *CommitReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitReservationResponse.ProtoReflect.Descriptor instead.
+func (*CommitReservationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CommitReservationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ReleaseReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId string                 `protobuf:"bytes,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseReservationRequest) Reset() {
+	*x = ReleaseReservationRequest{}
+	mi := &file_proto_inventory_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseReservationRequest) ProtoMessage() {}
+
+func (x *ReleaseReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseReservationRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseReservationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ReleaseReservationRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+type ReleaseReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseReservationResponse) Reset() {
+	*x = ReleaseReservationResponse{}
+	mi := &file_proto_inventory_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseReservationResponse) ProtoMessage() {}
+
+func (x *ReleaseReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseReservationResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseReservationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ReleaseReservationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
 var File_proto_inventory_proto protoreflect.FileDescriptor
 
 const file_proto_inventory_proto_rawDesc = "" +
@@ -901,7 +1241,28 @@ const file_proto_inventory_proto_rawDesc = "" +
 	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x19\n" +
 	"\border_id\x18\x03 \x01(\tR\aorderId\"/\n" +
 	"\x13UpdateStockResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xc3\x04\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"L\n" +
+	"\x0fReservationItem\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x83\x01\n" +
+	"\x13ReserveStockRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x120\n" +
+	"\x05items\x18\x02 \x03(\v2\x1a.inventory.ReservationItemR\x05items\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x05R\n" +
+	"ttlSeconds\"\\\n" +
+	"\x14ReserveStockResponse\x12%\n" +
+	"\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\tR\texpiresAt\"A\n" +
+	"\x18CommitReservationRequest\x12%\n" +
+	"\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\"5\n" +
+	"\x19CommitReservationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"B\n" +
+	"\x19ReleaseReservationRequest\x12%\n" +
+	"\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\"6\n" +
+	"\x1aReleaseReservationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess2\xd7\x06\n" +
 	"\x10InventoryService\x12R\n" +
 	"\rCreateProduct\x12\x1f.inventory.CreateProductRequest\x1a .inventory.CreateProductResponse\x12I\n" +
 	"\n" +
@@ -911,7 +1272,10 @@ const file_proto_inventory_proto_rawDesc = "" +
 	"\fListProducts\x12\x1e.inventory.ListProductsRequest\x1a\x1f.inventory.ListProductsResponse\x12I\n" +
 	"\n" +
 	"CheckStock\x12\x1c.inventory.CheckStockRequest\x1a\x1d.inventory.CheckStockResponse\x12L\n" +
-	"\vUpdateStock\x12\x1d.inventory.UpdateStockRequest\x1a\x1e.inventory.UpdateStockResponseB\tZ\a./protob\x06proto3"
+	"\vUpdateStock\x12\x1d.inventory.UpdateStockRequest\x1a\x1e.inventory.UpdateStockResponse\x12O\n" +
+	"\fReserveStock\x12\x1e.inventory.ReserveStockRequest\x1a\x1f.inventory.ReserveStockResponse\x12^\n" +
+	"\x11CommitReservation\x12#.inventory.CommitReservationRequest\x1a$.inventory.CommitReservationResponse\x12a\n" +
+	"\x12ReleaseReservation\x12$.inventory.ReleaseReservationRequest\x1a%.inventory.ReleaseReservationResponseB\tZ\a./protob\x06proto3"
 
 var (
 	file_proto_inventory_proto_rawDescOnce sync.Once
@@ -925,48 +1289,62 @@ func file_proto_inventory_proto_rawDescGZIP() []byte {
 	return file_proto_inventory_proto_rawDescData
 }
 
-var file_proto_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_proto_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_proto_inventory_proto_goTypes = []any{
-	(*Product)(nil),               // 0: inventory.Product
-	(*CreateProductRequest)(nil),  // 1: inventory.CreateProductRequest
-	(*CreateProductResponse)(nil), // 2: inventory.CreateProductResponse
-	(*GetProductRequest)(nil),     // 3: inventory.GetProductRequest
-	(*GetProductResponse)(nil),    // 4: inventory.GetProductResponse
-	(*UpdateProductRequest)(nil),  // 5: inventory.UpdateProductRequest
-	(*UpdateProductResponse)(nil), // 6: inventory.UpdateProductResponse
-	(*DeleteProductRequest)(nil),  // 7: inventory.DeleteProductRequest
-	(*DeleteProductResponse)(nil), // 8: inventory.DeleteProductResponse
-	(*ListProductsRequest)(nil),   // 9: inventory.ListProductsRequest
-	(*ListProductsResponse)(nil),  // 10: inventory.ListProductsResponse
-	(*CheckStockRequest)(nil),     // 11: inventory.CheckStockRequest
-	(*CheckStockResponse)(nil),    // 12: inventory.CheckStockResponse
-	(*UpdateStockRequest)(nil),    // 13: inventory.UpdateStockRequest
-	(*UpdateStockResponse)(nil),   // 14: inventory.UpdateStockResponse
+	(*Product)(nil),                    // 0: inventory.Product
+	(*CreateProductRequest)(nil),       // 1: inventory.CreateProductRequest
+	(*CreateProductResponse)(nil),      // 2: inventory.CreateProductResponse
+	(*GetProductRequest)(nil),          // 3: inventory.GetProductRequest
+	(*GetProductResponse)(nil),         // 4: inventory.GetProductResponse
+	(*UpdateProductRequest)(nil),       // 5: inventory.UpdateProductRequest
+	(*UpdateProductResponse)(nil),      // 6: inventory.UpdateProductResponse
+	(*DeleteProductRequest)(nil),       // 7: inventory.DeleteProductRequest
+	(*DeleteProductResponse)(nil),      // 8: inventory.DeleteProductResponse
+	(*ListProductsRequest)(nil),        // 9: inventory.ListProductsRequest
+	(*ListProductsResponse)(nil),       // 10: inventory.ListProductsResponse
+	(*CheckStockRequest)(nil),          // 11: inventory.CheckStockRequest
+	(*CheckStockResponse)(nil),         // 12: inventory.CheckStockResponse
+	(*UpdateStockRequest)(nil),         // 13: inventory.UpdateStockRequest
+	(*UpdateStockResponse)(nil),        // 14: inventory.UpdateStockResponse
+	(*ReservationItem)(nil),            // 15: inventory.ReservationItem
+	(*ReserveStockRequest)(nil),        // 16: inventory.ReserveStockRequest
+	(*ReserveStockResponse)(nil),       // 17: inventory.ReserveStockResponse
+	(*CommitReservationRequest)(nil),   // 18: inventory.CommitReservationRequest
+	(*CommitReservationResponse)(nil),  // 19: inventory.CommitReservationResponse
+	(*ReleaseReservationRequest)(nil),  // 20: inventory.ReleaseReservationRequest
+	(*ReleaseReservationResponse)(nil), // 21: inventory.ReleaseReservationResponse
 }
 var file_proto_inventory_proto_depIdxs = []int32{
 	0,  // 0: inventory.CreateProductResponse.product:type_name -> inventory.Product
 	0,  // 1: inventory.GetProductResponse.product:type_name -> inventory.Product
 	0,  // 2: inventory.UpdateProductResponse.product:type_name -> inventory.Product
 	0,  // 3: inventory.ListProductsResponse.products:type_name -> inventory.Product
-	1,  // 4: inventory.InventoryService.CreateProduct:input_type -> inventory.CreateProductRequest
-	3,  // 5: inventory.InventoryService.GetProduct:input_type -> inventory.GetProductRequest
-	5,  // 6: inventory.InventoryService.UpdateProduct:input_type -> inventory.UpdateProductRequest
-	7,  // 7: inventory.InventoryService.DeleteProduct:input_type -> inventory.DeleteProductRequest
-	9,  // 8: inventory.InventoryService.ListProducts:input_type -> inventory.ListProductsRequest
-	11, // 9: inventory.InventoryService.CheckStock:input_type -> inventory.CheckStockRequest
-	13, // 10: inventory.InventoryService.UpdateStock:input_type -> inventory.UpdateStockRequest
-	2,  // 11: inventory.InventoryService.CreateProduct:output_type -> inventory.CreateProductResponse
-	4,  // 12: inventory.InventoryService.GetProduct:output_type -> inventory.GetProductResponse
-	6,  // 13: inventory.InventoryService.UpdateProduct:output_type -> inventory.UpdateProductResponse
-	8,  // 14: inventory.InventoryService.DeleteProduct:output_type -> inventory.DeleteProductResponse
-	10, // 15: inventory.InventoryService.ListProducts:output_type -> inventory.ListProductsResponse
-	12, // 16: inventory.InventoryService.CheckStock:output_type -> inventory.CheckStockResponse
-	14, // 17: inventory.InventoryService.UpdateStock:output_type -> inventory.UpdateStockResponse
-	11, // [11:18] is the sub-list for method output_type
-	4,  // [4:11] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	15, // 4: inventory.ReserveStockRequest.items:type_name -> inventory.ReservationItem
+	1,  // 5: inventory.InventoryService.CreateProduct:input_type -> inventory.CreateProductRequest
+	3,  // 6: inventory.InventoryService.GetProduct:input_type -> inventory.GetProductRequest
+	5,  // 7: inventory.InventoryService.UpdateProduct:input_type -> inventory.UpdateProductRequest
+	7,  // 8: inventory.InventoryService.DeleteProduct:input_type -> inventory.DeleteProductRequest
+	9,  // 9: inventory.InventoryService.ListProducts:input_type -> inventory.ListProductsRequest
+	11, // 10: inventory.InventoryService.CheckStock:input_type -> inventory.CheckStockRequest
+	13, // 11: inventory.InventoryService.UpdateStock:input_type -> inventory.UpdateStockRequest
+	16, // 12: inventory.InventoryService.ReserveStock:input_type -> inventory.ReserveStockRequest
+	18, // 13: inventory.InventoryService.CommitReservation:input_type -> inventory.CommitReservationRequest
+	20, // 14: inventory.InventoryService.ReleaseReservation:input_type -> inventory.ReleaseReservationRequest
+	2,  // 15: inventory.InventoryService.CreateProduct:output_type -> inventory.CreateProductResponse
+	4,  // 16: inventory.InventoryService.GetProduct:output_type -> inventory.GetProductResponse
+	6,  // 17: inventory.InventoryService.UpdateProduct:output_type -> inventory.UpdateProductResponse
+	8,  // 18: inventory.InventoryService.DeleteProduct:output_type -> inventory.DeleteProductResponse
+	10, // 19: inventory.InventoryService.ListProducts:output_type -> inventory.ListProductsResponse
+	12, // 20: inventory.InventoryService.CheckStock:output_type -> inventory.CheckStockResponse
+	14, // 21: inventory.InventoryService.UpdateStock:output_type -> inventory.UpdateStockResponse
+	17, // 22: inventory.InventoryService.ReserveStock:output_type -> inventory.ReserveStockResponse
+	19, // 23: inventory.InventoryService.CommitReservation:output_type -> inventory.CommitReservationResponse
+	21, // 24: inventory.InventoryService.ReleaseReservation:output_type -> inventory.ReleaseReservationResponse
+	15, // [15:25] is the sub-list for method output_type
+	5,  // [5:15] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_proto_inventory_proto_init() }
@@ -980,7 +1358,7 @@ func file_proto_inventory_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_inventory_proto_rawDesc), len(file_proto_inventory_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
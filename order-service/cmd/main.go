@@ -1,17 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
-	"os"
 
 	pbInventory "E-Commerce/inventory-service/proto"
+	"E-Commerce/order-service/config"
 	"E-Commerce/order-service/internal/handler"
 	"E-Commerce/order-service/internal/repository"
 	"E-Commerce/order-service/internal/service"
-	"E-Commerce/order-service/internal/utils"
 	pb "E-Commerce/order-service/proto"
+	"E-Commerce/pkg/authz"
+	"E-Commerce/pkg/observability"
+	"E-Commerce/pkg/secrets"
+	"E-Commerce/pkg/tracing"
+	pbProducer "E-Commerce/producer-service/proto"
+	pbUser "E-Commerce/user-service/proto"
+	"E-Commerce/user-service/signingkeys"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -19,42 +26,67 @@ import (
 )
 
 func main() {
-	dsn := os.Getenv("POSTGRES_URL")
-	db, err := sqlx.Connect("postgres", dsn)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	store, err := secrets.Open(cfg.Config, config.SMTPPassword)
+	if err != nil {
+		log.Fatalf("Failed to load secrets: %v", err)
+	}
+
+	shutdown := observability.Init("order-service", cfg.MetricsAddr)
+	defer shutdown()
+
+	db, err := sqlx.Connect("postgres", cfg.PostgresURL)
 	if err != nil {
 		panic(err)
 	}
 
-	conn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+	conn, err := grpc.Dial(cfg.InventoryAddr, grpc.WithInsecure(), tracing.DialOption())
 	if err != nil {
 		panic(err)
 	}
 	ic := pbInventory.NewInventoryServiceClient(conn)
 
-	// Email configuration from environment variables
-	emailConfig := utils.EmailConfig{
-		SenderEmail:    "e_book_aitu@zohomail.com",
-		SenderPassword: "gakon2006",
-		SMTPHost:       "smtp.zoho.com",
-		SMTPPort:       "587",
+	producerConn, err := grpc.Dial(cfg.ProducerAddr, grpc.WithInsecure(), tracing.DialOption())
+	if err != nil {
+		panic(err)
 	}
+	pc := pbProducer.NewProducerServiceClient(producerConn)
 
-	// Validate email configuration
-	if emailConfig.SenderEmail == "" || emailConfig.SenderPassword == "" ||
-		emailConfig.SMTPHost == "" || emailConfig.SMTPPort == "" {
-		log.Fatal("Missing email configuration. Please set SMTP_EMAIL, SMTP_PASSWORD, SMTP_HOST, and SMTP_PORT environment variables")
+	userConn, err := grpc.Dial(cfg.UserAddr, grpc.WithInsecure(), tracing.DialOption())
+	if err != nil {
+		panic(err)
 	}
+	uc := pbUser.NewUserServiceClient(userConn)
 
 	repo := repository.NewOrderRepository(db)
-	svc := service.NewOrderService(repo, ic, emailConfig)
+	svc := service.NewOrderService(repo, ic, uc, cfg.EmailConfig(store))
 	h := handler.NewOrderGRPCServer(svc)
 
-	lis, err := net.Listen("tcp", ":50052")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relay := service.NewOutboxRelay(repository.NewOutboxRepository(db), pc, ic, service.DefaultOutboxRelayConfig())
+	go relay.Run(ctx)
+	go store.Watch(ctx, cfg.SecretsReloadInterval)
+
+	lis, err := net.Listen("tcp", cfg.ListenAddr)
 	if err != nil {
 		panic(err)
 	}
-	s := grpc.NewServer()
+	// Calls on behalf of users need their forwarded token
+	verifier := authz.NewVerifier(authz.NewKeySet(signingkeys.Fetch(uc), cfg.SigningKeysRefreshInterval))
+	rules := authz.Rules{
+		pb.OrderService_CreateOrder_FullMethodName: authz.Authenticated,
+		pb.OrderService_GetOrder_FullMethodName:    authz.Authenticated,
+		pb.OrderService_ListOrders_FullMethodName:  authz.Authenticated,
+		pb.OrderService_CancelOrder_FullMethodName: authz.Authenticated,
+		pb.OrderService_UpdateOrder_FullMethodName: authz.OrderUpdateStatus,
+	}
+	s := grpc.NewServer(append(observability.ServerOptions(), authz.ServerOption(verifier, rules))...)
 	pb.RegisterOrderServiceServer(s, h)
-	fmt.Println("Order Service running on :50052")
+	fmt.Printf("Order Service running on %s\n", cfg.ListenAddr)
 	s.Serve(lis)
 }
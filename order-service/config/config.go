@@ -1,46 +1,57 @@
 package config
 
 import (
-	"log"
-	"os"
+	"time"
 
-	pbInventory "E-Commerce/inventory-service/proto"
 	"E-Commerce/order-service/internal/utils"
-
-	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
-	"google.golang.org/grpc"
+	"E-Commerce/pkg/secrets"
+	"E-Commerce/pkg/settings"
 )
 
+// EnvPrefix is prepended to each setting's environment variable, as in
+// ORDER_SERVICE_PRODUCER_ADDR.
+const EnvPrefix = "ORDER_SERVICE"
+
+// Config holds the order-service settings. Each can be set in the YAML
+// file, the environment or a flag; see pkg/settings.
 type Config struct {
-	DB              *sqlx.DB
-	InventoryClient pbInventory.InventoryServiceClient
-	EmailConfig     utils.EmailConfig
+	secrets.Config
+
+	ListenAddr                 string        `setting:"listen_addr" default:":50052" required:"true" usage:"gRPC listen address"`
+	MetricsAddr                string        `setting:"metrics_addr" default:":9102" usage:"Prometheus /metrics listen address; empty disables it"`
+	PostgresURL                string        `setting:"postgres_url" required:"true" usage:"PostgreSQL DSN of the order database"`
+	InventoryAddr              string        `setting:"inventory_addr" default:"localhost:50051" required:"true" usage:"inventory-service gRPC address"`
+	UserAddr                   string        `setting:"user_addr" default:"localhost:50053" required:"true" usage:"user-service gRPC address"`
+	ProducerAddr               string        `setting:"producer_addr" default:"localhost:50054" required:"true" usage:"producer-service gRPC address"`
+	SMTPHost                   string        `setting:"smtp_host" default:"smtp.zoho.com" required:"true" usage:"SMTP server for order confirmation emails"`
+	SMTPPort                   string        `setting:"smtp_port" default:"587" required:"true" usage:"SMTP server port"`
+	SMTPSender                 string        `setting:"smtp_sender" default:"e_book_aitu@zohomail.com" required:"true" usage:"address order confirmation emails are sent from"`
+	SigningKeysRefreshInterval time.Duration `setting:"signing_keys_refresh_interval" default:"5m" required:"true" usage:"how long the user-service's public keys are cached"`
 }
 
-func NewConfig() *Config {
-	dsn := os.Getenv("POSTGRES_URL")
-	db, err := sqlx.Connect("postgres", dsn)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-
-	conn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
-	if err != nil {
-		log.Fatalf("Failed to connect to Inventory Service: %v", err)
-	}
-	inventoryClient := pbInventory.NewInventoryServiceClient(conn)
-
-	emailConfig := utils.EmailConfig{
-		SenderEmail:    "e_book_aitu@zohomail.com",
-		SenderPassword: "gakon2006",
-		SMTPHost:       "smtp.zoho.com",
-		SMTPPort:       "587",
+// SMTPPassword is the secret the SMTP sender logs in with. It has no
+// default, so production refuses to start without it.
+var SMTPPassword = secrets.Spec{Name: "smtp_password"}
+
+// EmailConfig returns a function building the email configuration from
+// the current SMTP password in store.
+func (c *Config) EmailConfig(store *secrets.Store) func() utils.EmailConfig {
+	return func() utils.EmailConfig {
+		return utils.EmailConfig{
+			SenderEmail:    c.SMTPSender,
+			SenderPassword: store.Get(SMTPPassword.Name),
+			SMTPHost:       c.SMTPHost,
+			SMTPPort:       c.SMTPPort,
+		}
 	}
+}
 
-	return &Config{
-		DB:              db,
-		InventoryClient: inventoryClient,
-		EmailConfig:     emailConfig,
+// Load reads the settings and fails naming every one that is missing or
+// invalid.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := settings.Load(cfg, EnvPrefix); err != nil {
+		return nil, err
 	}
+	return cfg, nil
 }
@@ -15,10 +15,12 @@ type OrderItem struct {
 }
 
 type Order struct {
-	ID          uuid.UUID    `db:"id"`
-	UserID      string       `db:"user_id"`
-	Status      string       `db:"status"`
-	TotalAmount float64      `db:"total_amount"`
-	CreatedAt   time.Time    `db:"created_at"`
-	Items       []*OrderItem `db:"-"`
-}
\ No newline at end of file
+	ID            uuid.UUID            `db:"id"`
+	UserID        string               `db:"user_id"`
+	Status        string               `db:"status"`
+	TotalAmount   float64              `db:"total_amount"`
+	CreatedAt     time.Time            `db:"created_at"`
+	ReservationID string               `db:"reservation_id"`
+	Items         []*OrderItem         `db:"-"`
+	History       []*OrderStatusChange `db:"-"`
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusPaid       = "paid"
+	StatusFulfilling = "fulfilling"
+	StatusShipped    = "shipped"
+	StatusDelivered  = "delivered"
+	StatusCancelled  = "cancelled"
+	StatusRefunded   = "refunded"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown order status")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrNotOrderOwner     = errors.New("order belongs to another user")
+	ErrCancelNotAllowed  = errors.New("order can no longer be cancelled")
+)
+
+// orderTransitions lists, for each status, the statuses an order may move
+// to next. Cancelled and refunded are terminal.
+var orderTransitions = map[string][]string{
+	StatusPending:    {StatusPaid, StatusCancelled},
+	StatusPaid:       {StatusFulfilling, StatusCancelled, StatusRefunded},
+	StatusFulfilling: {StatusShipped, StatusCancelled, StatusRefunded},
+	StatusShipped:    {StatusDelivered},
+	StatusDelivered:  {StatusRefunded},
+	StatusCancelled:  {},
+	StatusRefunded:   {},
+}
+
+// ValidateTransition reports whether an order in status from may move to
+// status to.
+func ValidateTransition(from, to string) error {
+	if _, ok := orderTransitions[to]; !ok {
+		return ErrUnknownStatus
+	}
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return ErrInvalidTransition
+}
+
+type OrderStatusChange struct {
+	ID         uuid.UUID `db:"id"`
+	OrderID    uuid.UUID `db:"order_id"`
+	FromStatus string    `db:"from_status"`
+	ToStatus   string    `db:"to_status"`
+	Actor      string    `db:"actor"`
+	Reason     string    `db:"reason"`
+	CreatedAt  time.Time `db:"created_at"`
+	// OwnerOnly limits the change to what a customer may do to their own
+	// order: Actor must own it and it must still be pending.
+	OwnerOnly bool `db:"-"`
+}
+
+// Validate reports whether the change may be applied to an order owned by
+// owner and currently in status from. The repository calls it under the
+// order's row lock, so the order cannot move on in between.
+func (c *OrderStatusChange) Validate(owner, from string) error {
+	if c.OwnerOnly {
+		if owner != c.Actor {
+			return ErrNotOrderOwner
+		}
+		if from != StatusPending {
+			return ErrCancelNotAllowed
+		}
+	}
+	return ValidateTransition(from, c.ToStatus)
+}
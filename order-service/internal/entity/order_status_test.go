@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     error
+	}{
+		{StatusPending, StatusPaid, nil},
+		{StatusPending, StatusCancelled, nil},
+		{StatusPaid, StatusFulfilling, nil},
+		{StatusPaid, StatusRefunded, nil},
+		{StatusFulfilling, StatusShipped, nil},
+		{StatusShipped, StatusDelivered, nil},
+		{StatusDelivered, StatusRefunded, nil},
+		{StatusPending, StatusShipped, ErrInvalidTransition},
+		{StatusShipped, StatusCancelled, ErrInvalidTransition},
+		{StatusCancelled, StatusPaid, ErrInvalidTransition},
+		{StatusRefunded, StatusDelivered, ErrInvalidTransition},
+		{StatusDelivered, StatusDelivered, ErrInvalidTransition},
+		{StatusPending, "completed", ErrUnknownStatus},
+		{"completed", StatusDelivered, ErrInvalidTransition},
+	}
+	for _, tt := range tests {
+		err := ValidateTransition(tt.from, tt.to)
+		if tt.want == nil {
+			assert.NoError(t, err, "%s -> %s", tt.from, tt.to)
+		} else {
+			assert.ErrorIs(t, err, tt.want, "%s -> %s", tt.from, tt.to)
+		}
+	}
+}
+
+func TestStatusChangeValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change OrderStatusChange
+		owner  string
+		from   string
+		want   error
+	}{
+		{"Staff", OrderStatusChange{ToStatus: StatusCancelled, Actor: "admin-1"}, "user-1", StatusPaid, nil},
+		{"Staff Invalid Transition", OrderStatusChange{ToStatus: StatusCancelled, Actor: "admin-1"}, "user-1", StatusShipped, ErrInvalidTransition},
+		{"Owner", OrderStatusChange{ToStatus: StatusCancelled, Actor: "user-1", OwnerOnly: true}, "user-1", StatusPending, nil},
+		{"Owner After Payment", OrderStatusChange{ToStatus: StatusCancelled, Actor: "user-1", OwnerOnly: true}, "user-1", StatusPaid, ErrCancelNotAllowed},
+		{"Other User", OrderStatusChange{ToStatus: StatusCancelled, Actor: "user-2", OwnerOnly: true}, "user-1", StatusPending, ErrNotOrderOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.change.Validate(tt.owner, tt.from)
+			if tt.want == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"E-Commerce/pkg/events"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	// OutboxStatusFailed marks events the relay gave up on because they
+	// can never be published. They are kept for inspection.
+	OutboxStatusFailed = "failed"
+
+	EventOrderCreated = "order.created"
+	// EventReservationCommit commits the order's stock reservation in
+	// inventory-service. It is stored with the order so the commit is
+	// retried until it succeeds, before the reservation expires.
+	EventReservationCommit = "reservation.commit"
+)
+
+// OutboxEvent is a domain event stored alongside the change that produced
+// it and published later by the outbox relay.
+type OutboxEvent struct {
+	ID            uuid.UUID    `db:"id"`
+	AggregateID   string       `db:"aggregate_id"`
+	EventType     string       `db:"event_type"`
+	Payload       string       `db:"payload"` // JSON; a string so lib/pq does not send it as bytea
+	Status        string       `db:"status"`
+	Attempts      int          `db:"attempts"`
+	LastError     string       `db:"last_error"`
+	NextAttemptAt time.Time    `db:"next_attempt_at"`
+	CreatedAt     time.Time    `db:"created_at"`
+	SentAt        sql.NullTime `db:"sent_at"`
+	// TraceParent is the W3C trace context of the request that stored the
+	// event, so publishing it continues the same trace.
+	TraceParent string `db:"trace_parent"`
+}
+
+// OrderCreatedPayload is the versioned order.created event stored in the
+// outbox.
+type OrderCreatedPayload = events.OrderCreated
+
+// ReservationCommitPayload is the payload of a reservation.commit event.
+type ReservationCommitPayload struct {
+	OrderID       string `json:"order_id"`
+	ReservationID string `json:"reservation_id"`
+}
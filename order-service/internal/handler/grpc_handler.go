@@ -2,10 +2,17 @@ package handler
 
 import (
     "context"
+    "errors"
+    "time"
+
     "github.com/google/uuid"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
     pb "E-Commerce/order-service/proto"
     "E-Commerce/order-service/internal/service"
     "E-Commerce/order-service/internal/entity"
+    "E-Commerce/order-service/internal/repository"
+    "E-Commerce/pkg/authz"
 )
 
 type OrderGRPCServer struct {
@@ -22,73 +29,88 @@ func (s *OrderGRPCServer) CreateOrder(ctx context.Context, req *pb.CreateOrderRe
     for i, item := range req.Items {
         pid, err := uuid.Parse(item.ProductId)
         if err != nil {
-            return nil, err
+            return nil, status.Error(codes.InvalidArgument, "invalid product ID")
         }
         items[i] = &entity.OrderItem{
             ProductID: pid,
             Quantity:  int(item.Quantity),
         }
     }
-    order, err := s.svc.CreateOrder(req.UserId, items)
+    caller, _ := authz.FromContext(ctx)
+    order, err := s.svc.CreateOrder(ctx, caller.UserID, items)
     if err != nil {
-        return nil, err
+        return nil, orderError(err)
     }
-    pbOrder := &pb.Order{
-        Id:          order.ID.String(),
-        UserId:      order.UserID,
-        Status:      order.Status,
-        TotalAmount: float32(order.TotalAmount),
-        Items:       make([]*pb.OrderItem, len(order.Items)),
-    }
-    for i, item := range order.Items {
-        pbOrder.Items[i] = &pb.OrderItem{
-            ProductId: item.ProductID.String(),
-            Quantity:  int32(item.Quantity),
-            Price:     float32(item.Price),
-        }
-    }
-    return &pb.CreateOrderResponse{Order: pbOrder}, nil
+    return &pb.CreateOrderResponse{Order: toPBOrder(order)}, nil
 }
 
 func (s *OrderGRPCServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
     id, err := uuid.Parse(req.Id)
     if err != nil {
-        return nil, err
+        return nil, status.Error(codes.InvalidArgument, "invalid order ID")
     }
-    order, err := s.svc.GetOrder(id)
+    caller, _ := authz.FromContext(ctx)
+    order, err := s.svc.GetOrderAs(id, caller.UserID, caller.Role)
     if err != nil {
-        return nil, err
+        return nil, orderError(err)
     }
-    pbOrder := &pb.Order{
-        Id:          order.ID.String(),
-        UserId:      order.UserID,
-        Status:      order.Status,
-        TotalAmount: float32(order.TotalAmount),
-        Items:       make([]*pb.OrderItem, len(order.Items)),
-    }
-    for i, item := range order.Items {
-        pbOrder.Items[i] = &pb.OrderItem{
-            ProductId: item.ProductID.String(),
-            Quantity:  int32(item.Quantity),
-            Price:     float32(item.Price),
-        }
-    }
-    return &pb.GetOrderResponse{Order: pbOrder}, nil
+    return &pb.GetOrderResponse{Order: toPBOrder(order)}, nil
 }
 
 func (s *OrderGRPCServer) UpdateOrder(ctx context.Context, req *pb.UpdateOrderRequest) (*pb.UpdateOrderResponse, error) {
     id, err := uuid.Parse(req.Id)
     if err != nil {
-        return nil, err
+        return nil, status.Error(codes.InvalidArgument, "invalid order ID")
     }
-    err = s.svc.UpdateOrderStatus(id, req.Status)
+    caller, _ := authz.FromContext(ctx)
+    err = s.svc.UpdateOrderStatus(ctx, id, req.Status, caller.UserID, caller.Role, req.Reason)
     if err != nil {
-        return nil, err
+        return nil, orderError(err)
     }
     order, err := s.svc.GetOrder(id)
     if err != nil {
-        return nil, err
+        return nil, orderError(err)
     }
+    return &pb.UpdateOrderResponse{Order: toPBOrder(order)}, nil
+}
+
+func (s *OrderGRPCServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+    caller, _ := authz.FromContext(ctx)
+    userID := req.UserId
+    if userID == "" {
+        userID = caller.UserID
+    }
+    if userID != caller.UserID && !caller.Can(authz.OrderReadAny) {
+        return nil, status.Errorf(codes.PermissionDenied, "listing another user's orders requires permission %s", authz.OrderReadAny)
+    }
+    orders, total, err := s.svc.ListOrders(userID, int(req.Page), int(req.PageSize))
+    if err != nil {
+        return nil, orderError(err)
+    }
+    pbOrders := make([]*pb.Order, len(orders))
+    for i, order := range orders {
+        pbOrders[i] = toPBOrder(order)
+    }
+    return &pb.ListOrdersResponse{
+        Orders: pbOrders,
+        Total:  int32(total),
+    }, nil
+}
+
+func (s *OrderGRPCServer) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+    id, err := uuid.Parse(req.Id)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "invalid order ID")
+    }
+    caller, _ := authz.FromContext(ctx)
+    order, err := s.svc.CancelOrder(ctx, id, caller.UserID, caller.Role, req.Reason)
+    if err != nil {
+        return nil, orderError(err)
+    }
+    return &pb.CancelOrderResponse{Order: toPBOrder(order)}, nil
+}
+
+func toPBOrder(order *entity.Order) *pb.Order {
     pbOrder := &pb.Order{
         Id:          order.ID.String(),
         UserId:      order.UserID,
@@ -103,33 +125,37 @@ func (s *OrderGRPCServer) UpdateOrder(ctx context.Context, req *pb.UpdateOrderRe
             Price:     float32(item.Price),
         }
     }
-    return &pb.UpdateOrderResponse{Order: pbOrder}, nil
+    for _, change := range order.History {
+        pbOrder.Timeline = append(pbOrder.Timeline, &pb.OrderStatusChange{
+            FromStatus: change.FromStatus,
+            ToStatus:   change.ToStatus,
+            Actor:      change.Actor,
+            Reason:     change.Reason,
+            ChangedAt:  change.CreatedAt.Format(time.RFC3339),
+        })
+    }
+    return pbOrder
 }
 
-func (s *OrderGRPCServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
-    orders, total, err := s.svc.ListOrders(req.UserId, int(req.Page), int(req.PageSize))
-    if err != nil {
-        return nil, err
+func orderError(err error) error {
+    switch {
+    // Other users' orders are reported as missing, so IDs cannot be probed
+    case errors.Is(err, repository.ErrOrderNotFound), errors.Is(err, entity.ErrNotOrderOwner):
+        return status.Error(codes.NotFound, "order not found")
+    case errors.Is(err, service.ErrProductNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.Is(err, service.ErrInsufficientStock):
+        return status.Error(codes.FailedPrecondition, err.Error())
+    case errors.Is(err, entity.ErrUnknownStatus), errors.Is(err, service.ErrInvalidItems):
+        return status.Error(codes.InvalidArgument, err.Error())
+    case errors.Is(err, entity.ErrInvalidTransition), errors.Is(err, entity.ErrCancelNotAllowed):
+        return status.Error(codes.FailedPrecondition, err.Error())
+    case errors.Is(err, service.ErrCancelNotPermitted):
+        return status.Error(codes.PermissionDenied, err.Error())
     }
-    pbOrders := make([]*pb.Order, len(orders))
-    for i, order := range orders {
-        pbOrders[i] = &pb.Order{
-            Id:          order.ID.String(),
-            UserId:      order.UserID,
-            Status:      order.Status,
-            TotalAmount: float32(order.TotalAmount),
-            Items:       make([]*pb.OrderItem, len(order.Items)),
-        }
-        for j, item := range order.Items {
-            pbOrders[i].Items[j] = &pb.OrderItem{
-                ProductId: item.ProductID.String(),
-                Quantity:  int32(item.Quantity),
-                Price:     float32(item.Price),
-            }
-        }
+    if _, ok := status.FromError(err); ok {
+        return err
     }
-    return &pb.ListOrdersResponse{
-        Orders: pbOrders,
-        Total:  int32(total),
-    }, nil
-}
\ No newline at end of file
+    // The gateway answers Internal with a generic message
+    return status.Error(codes.Internal, err.Error())
+}
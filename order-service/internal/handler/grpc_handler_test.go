@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"E-Commerce/order-service/internal/entity"
+	"E-Commerce/order-service/internal/repository"
+	"E-Commerce/order-service/internal/service"
+	pb "E-Commerce/order-service/proto"
+	"E-Commerce/pkg/authz"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mock order service; calls without a method here panic
+type mockOrderService struct {
+	service.OrderService
+	mock.Mock
+}
+
+func (m *mockOrderService) CreateOrder(ctx context.Context, userID string, items []*entity.OrderItem) (*entity.Order, error) {
+	args := m.Called(userID)
+	order, _ := args.Get(0).(*entity.Order)
+	return order, args.Error(1)
+}
+
+func (m *mockOrderService) GetOrderAs(id uuid.UUID, actor, actorRole string) (*entity.Order, error) {
+	args := m.Called(id, actor, actorRole)
+	order, _ := args.Get(0).(*entity.Order)
+	return order, args.Error(1)
+}
+
+func (m *mockOrderService) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status, actor, actorRole, reason string) error {
+	return m.Called(id, status, actor, actorRole).Error(0)
+}
+
+func (m *mockOrderService) ListOrders(userID string, page, pageSize int) ([]*entity.Order, int, error) {
+	args := m.Called(userID)
+	orders, _ := args.Get(0).([]*entity.Order)
+	return orders, args.Int(1), args.Error(2)
+}
+
+func (m *mockOrderService) CancelOrder(ctx context.Context, id uuid.UUID, actor, actorRole, reason string) (*entity.Order, error) {
+	args := m.Called(id, actor, actorRole)
+	order, _ := args.Get(0).(*entity.Order)
+	return order, args.Error(1)
+}
+
+func TestOrderAccessByCaller(t *testing.T) {
+	order := &entity.Order{ID: uuid.New(), UserID: "user-1", Status: entity.StatusPending}
+
+	tests := []struct {
+		name   string
+		caller authz.Identity
+		err    error
+		code   codes.Code
+	}{
+		{name: "Owner", caller: authz.Identity{UserID: "user-1", Role: authz.RoleUser}, code: codes.OK},
+		{name: "Staff", caller: authz.Identity{UserID: "user-2", Role: authz.RoleSupport}, code: codes.OK},
+		{name: "Hidden Order", caller: authz.Identity{UserID: "user-2", Role: authz.RoleUser}, err: repository.ErrOrderNotFound, code: codes.NotFound},
+		{name: "Other Owner", caller: authz.Identity{UserID: "user-2", Role: authz.RoleUser}, err: entity.ErrNotOrderOwner, code: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockOrderService{}
+			var result *entity.Order
+			if tt.err == nil {
+				result = order
+			}
+			// The identity comes from the verified token, never the request
+			svc.On("GetOrderAs", order.ID, tt.caller.UserID, tt.caller.Role).Return(result, tt.err)
+			svc.On("CancelOrder", order.ID, tt.caller.UserID, tt.caller.Role).Return(result, tt.err)
+			server := NewOrderGRPCServer(svc)
+			ctx := authz.NewContext(context.Background(), tt.caller)
+
+			got, err := server.GetOrder(ctx, &pb.GetOrderRequest{Id: order.ID.String()})
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				require.NotNil(t, got)
+				assert.Equal(t, order.ID.String(), got.Order.Id)
+			}
+
+			cancelled, err := server.CancelOrder(ctx, &pb.CancelOrderRequest{Id: order.ID.String()})
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				require.NotNil(t, cancelled)
+				assert.Equal(t, order.ID.String(), cancelled.Order.Id)
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateOrderErrorCodes(t *testing.T) {
+	productID := uuid.NewString()
+	tests := []struct {
+		name      string
+		productID string
+		err       error
+		code      codes.Code
+	}{
+		{name: "Invalid Product ID", productID: "not-a-uuid", code: codes.InvalidArgument},
+		{name: "Unknown Product", productID: productID, err: fmt.Errorf("%w: %s", service.ErrProductNotFound, productID), code: codes.NotFound},
+		{name: "Insufficient Stock", productID: productID, err: fmt.Errorf("%w: requested 3", service.ErrInsufficientStock), code: codes.FailedPrecondition},
+		{name: "Invalid Quantity", productID: productID, err: fmt.Errorf("%w: positive quantities", service.ErrInvalidItems), code: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockOrderService{}
+			if tt.err != nil {
+				svc.On("CreateOrder", "user-1").Return(nil, tt.err)
+			}
+			ctx := authz.NewContext(context.Background(), authz.Identity{UserID: "user-1", Role: authz.RoleUser})
+
+			_, err := NewOrderGRPCServer(svc).CreateOrder(ctx, &pb.CreateOrderRequest{
+				Items: []*pb.OrderItem{{ProductId: tt.productID, Quantity: 3}},
+			})
+			assert.Equal(t, tt.code, status.Code(err))
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateOrderCancelNeedsCancelAny(t *testing.T) {
+	id := uuid.New()
+	caller := authz.Identity{UserID: "staff-1", Role: authz.RoleFulfillment}
+	svc := &mockOrderService{}
+	svc.On("UpdateOrderStatus", id, entity.StatusCancelled, caller.UserID, caller.Role).Return(service.ErrCancelNotPermitted)
+	ctx := authz.NewContext(context.Background(), caller)
+
+	_, err := NewOrderGRPCServer(svc).UpdateOrder(ctx, &pb.UpdateOrderRequest{Id: id.String(), Status: entity.StatusCancelled})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	svc.AssertExpectations(t)
+}
+
+func TestOrderRequestErrorCodes(t *testing.T) {
+	caller := authz.Identity{UserID: "user-1", Role: authz.RoleUser}
+	ctx := authz.NewContext(context.Background(), caller)
+	svc := &mockOrderService{}
+	svc.On("ListOrders", caller.UserID).Return(nil, 0, errors.New("pq: connection refused"))
+	server := NewOrderGRPCServer(svc)
+
+	_, err := server.GetOrder(ctx, &pb.GetOrderRequest{Id: "not-a-uuid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = server.UpdateOrder(ctx, &pb.UpdateOrderRequest{Id: "not-a-uuid", Status: entity.StatusPaid})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = server.CancelOrder(ctx, &pb.CancelOrderRequest{Id: "not-a-uuid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ListOrders(ctx, &pb.ListOrdersRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	svc.AssertExpectations(t)
+}
@@ -1,11 +1,18 @@
 package repository
 
 import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+
     "github.com/google/uuid"
     "github.com/jmoiron/sqlx"
     "E-Commerce/order-service/internal/entity"
 )
 
+var ErrOrderNotFound = errors.New("order not found")
+
 type orderRepository struct {
     db *sqlx.DB
 }
@@ -14,15 +21,17 @@ func NewOrderRepository(db *sqlx.DB) *orderRepository {
     return &orderRepository{db: db}
 }
 
-func (r *orderRepository) Create(order *entity.Order) error {
+// Create stores the order, its items and its outbox events in one
+// transaction, so the events exist if and only if the order does.
+func (r *orderRepository) Create(order *entity.Order, events ...*entity.OutboxEvent) error {
     tx, err := r.db.Beginx()
     if err != nil {
         return err
     }
     defer tx.Rollback()
 
-    query := `INSERT INTO orders (id, user_id, status, total_amount, created_at) 
-              VALUES (:id, :user_id, :status, :total_amount, :created_at)`
+    query := `INSERT INTO orders (id, user_id, status, total_amount, created_at, reservation_id) 
+              VALUES (:id, :user_id, :status, :total_amount, :created_at, :reservation_id)`
     _, err = tx.NamedExec(query, order)
     if err != nil {
         return err
@@ -38,12 +47,31 @@ func (r *orderRepository) Create(order *entity.Order) error {
         }
     }
 
+    err = insertStatusChange(tx, &entity.OrderStatusChange{
+        OrderID:   order.ID,
+        ToStatus:  order.Status,
+        Actor:     order.UserID,
+        CreatedAt: order.CreatedAt,
+    })
+    if err != nil {
+        return err
+    }
+
+    for _, event := range events {
+        if err := insertOutboxEvent(tx, event); err != nil {
+            return err
+        }
+    }
+
     return tx.Commit()
 }
 
 func (r *orderRepository) Get(id uuid.UUID) (*entity.Order, error) {
     var o entity.Order
     err := r.db.Get(&o, "SELECT * FROM orders WHERE id = $1", id)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, ErrOrderNotFound
+    }
     if err != nil {
         return nil, err
     }
@@ -54,8 +82,62 @@ func (r *orderRepository) Get(id uuid.UUID) (*entity.Order, error) {
     return &o, nil
 }
 
-func (r *orderRepository) UpdateStatus(id uuid.UUID, status string) error {
-    _, err := r.db.Exec("UPDATE orders SET status = $1 WHERE id = $2", status, id)
+// UpdateStatus moves an order to change.ToStatus if change.Validate allows
+// it and appends the change to order_status_history. The order is read
+// under a row lock so concurrent updates cannot both pass validation.
+func (r *orderRepository) UpdateStatus(id uuid.UUID, change *entity.OrderStatusChange) error {
+    tx, err := r.db.Beginx()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var locked struct {
+        Status string `db:"status"`
+        UserID string `db:"user_id"`
+    }
+    err = tx.Get(&locked, "SELECT status, user_id FROM orders WHERE id = $1 FOR UPDATE", id)
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrOrderNotFound
+    }
+    if err != nil {
+        return err
+    }
+
+    current := locked.Status
+    if err := change.Validate(locked.UserID, current); err != nil {
+        return fmt.Errorf("%w: %s -> %s", err, current, change.ToStatus)
+    }
+
+    _, err = tx.Exec("UPDATE orders SET status = $1 WHERE id = $2", change.ToStatus, id)
+    if err != nil {
+        return err
+    }
+
+    change.OrderID = id
+    change.FromStatus = current
+    change.CreatedAt = time.Now()
+    if err := insertStatusChange(tx, change); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+func (r *orderRepository) GetHistory(orderID uuid.UUID) ([]*entity.OrderStatusChange, error) {
+    var history []*entity.OrderStatusChange
+    err := r.db.Select(&history, "SELECT * FROM order_status_history WHERE order_id = $1 ORDER BY created_at", orderID)
+    if err != nil {
+        return nil, err
+    }
+    return history, nil
+}
+
+func insertStatusChange(tx *sqlx.Tx, change *entity.OrderStatusChange) error {
+    change.ID = uuid.New()
+    query := `INSERT INTO order_status_history (id, order_id, from_status, to_status, actor, reason, created_at)
+              VALUES (:id, :order_id, :from_status, :to_status, :actor, :reason, :created_at)`
+    _, err := tx.NamedExec(query, change)
     return err
 }
 
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"E-Commerce/order-service/internal/entity"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDB connects to the PostgreSQL server at ORDER_SERVICE_TEST_POSTGRES_URL
+// and gives the test a schema of its own, migrated up to and including
+// migration upTo, or fully if upTo is empty. The test is skipped without a
+// server.
+func testDB(t *testing.T, upTo string) *sqlx.DB {
+	t.Helper()
+	url := os.Getenv("ORDER_SERVICE_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("ORDER_SERVICE_TEST_POSTGRES_URL is not set")
+	}
+
+	admin, err := sqlx.Connect("postgres", url)
+	require.NoError(t, err)
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+	db, err := sqlx.Connect("postgres", url+sep+"search_path="+schema+",public")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	migrate(t, db, "", upTo)
+	return db
+}
+
+// migrate applies the migrations after from up to and including upTo, in
+// order. Empty bounds are open.
+func migrate(t *testing.T, db *sqlx.DB, from, upTo string) {
+	t.Helper()
+	files, err := filepath.Glob("../../migrations/*.up.sql")
+	require.NoError(t, err)
+	sort.Strings(files)
+	for _, file := range files {
+		name := filepath.Base(file)
+		if from != "" && name <= from {
+			continue
+		}
+		if upTo != "" && name > upTo {
+			break
+		}
+		query, err := os.ReadFile(file)
+		require.NoError(t, err)
+		_, err = db.Exec(string(query))
+		require.NoError(t, err, "migration %s", name)
+	}
+}
+
+func createOrder(t *testing.T, repo *orderRepository, userID string) *entity.Order {
+	t.Helper()
+	order := &entity.Order{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    entity.StatusPending,
+		CreatedAt: time.Now(),
+	}
+	require.NoError(t, repo.Create(order))
+	return order
+}
+
+func TestUpdateStatusRecordsHistory(t *testing.T) {
+	repo := NewOrderRepository(testDB(t, ""))
+	order := createOrder(t, repo, "user-1")
+
+	for _, to := range []string{entity.StatusPaid, entity.StatusFulfilling, entity.StatusShipped} {
+		require.NoError(t, repo.UpdateStatus(order.ID, &entity.OrderStatusChange{ToStatus: to, Actor: "admin-1", Reason: "step"}))
+	}
+
+	stored, err := repo.Get(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusShipped, stored.Status)
+
+	history, err := repo.GetHistory(order.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	var steps []string
+	for _, change := range history {
+		steps = append(steps, fmt.Sprintf("%s->%s", change.FromStatus, change.ToStatus))
+	}
+	assert.Equal(t, []string{"->pending", "pending->paid", "paid->fulfilling", "fulfilling->shipped"}, steps)
+	assert.Equal(t, "user-1", history[0].Actor)
+	assert.Equal(t, "admin-1", history[3].Actor)
+}
+
+func TestUpdateStatusRejectsInvalidChanges(t *testing.T) {
+	repo := NewOrderRepository(testDB(t, ""))
+
+	tests := []struct {
+		name   string
+		status string
+		change entity.OrderStatusChange
+		want   error
+	}{
+		{"Invalid Transition", entity.StatusPending, entity.OrderStatusChange{ToStatus: entity.StatusShipped, Actor: "admin-1"}, entity.ErrInvalidTransition},
+		{"Unknown Status", entity.StatusPending, entity.OrderStatusChange{ToStatus: "completed", Actor: "admin-1"}, entity.ErrUnknownStatus},
+		{"Other User", entity.StatusPending, entity.OrderStatusChange{ToStatus: entity.StatusCancelled, Actor: "user-2", OwnerOnly: true}, entity.ErrNotOrderOwner},
+		{"Owner After Payment", entity.StatusPaid, entity.OrderStatusChange{ToStatus: entity.StatusCancelled, Actor: "user-1", OwnerOnly: true}, entity.ErrCancelNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := createOrder(t, repo, "user-1")
+			if tt.status != entity.StatusPending {
+				require.NoError(t, repo.UpdateStatus(order.ID, &entity.OrderStatusChange{ToStatus: tt.status, Actor: "admin-1"}))
+			}
+
+			err := repo.UpdateStatus(order.ID, &tt.change)
+			assert.ErrorIs(t, err, tt.want)
+
+			stored, err := repo.Get(order.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, stored.Status, "a rejected change must leave the order as it was")
+		})
+	}
+
+	assert.ErrorIs(t, repo.UpdateStatus(uuid.New(), &entity.OrderStatusChange{ToStatus: entity.StatusPaid}), ErrOrderNotFound)
+}
+
+func TestMigrationMapsCompletedToDelivered(t *testing.T) {
+	db := testDB(t, "006_add_order_reservation.up.sql")
+	repo := NewOrderRepository(db)
+	completed := createOrder(t, repo, "user-1")
+	pending := createOrder(t, repo, "user-1")
+	_, err := db.Exec("UPDATE orders SET status = 'completed' WHERE id = $1", completed.ID)
+	require.NoError(t, err)
+
+	migrate(t, db, "006_add_order_reservation.up.sql", "")
+
+	stored, err := repo.Get(completed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusDelivered, stored.Status)
+	history, err := repo.GetHistory(completed.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, history)
+	last := history[len(history)-1]
+	assert.Equal(t, "completed", last.FromStatus)
+	assert.Equal(t, entity.StatusDelivered, last.ToStatus)
+
+	// Delivered orders can move on again
+	require.NoError(t, repo.UpdateStatus(completed.ID, &entity.OrderStatusChange{ToStatus: entity.StatusRefunded, Actor: "admin-1"}))
+
+	stored, err = repo.Get(pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusPending, stored.Status)
+}
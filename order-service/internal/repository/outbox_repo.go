@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"E-Commerce/order-service/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepository interface {
+	// ClaimPending returns up to limit due events and pushes their next
+	// attempt past lease, so a relay that dies mid-publish leaves them to be
+	// picked up again once the lease runs out.
+	ClaimPending(limit int, lease time.Duration) ([]*entity.OutboxEvent, error)
+	MarkSent(id uuid.UUID) error
+	MarkFailed(id uuid.UUID, nextAttemptAt time.Time, lastError string) error
+	// MarkDead stops retrying an event that can never be published.
+	MarkDead(id uuid.UUID, lastError string) error
+	PruneSent(olderThan time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) ClaimPending(limit int, lease time.Duration) ([]*entity.OutboxEvent, error) {
+	var events []*entity.OutboxEvent
+	now := time.Now()
+	err := r.db.Select(&events, `
+		UPDATE outbox SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE status = $2 AND next_attempt_at <= $3
+			ORDER BY created_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), entity.OutboxStatusPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkSent(id uuid.UUID) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox SET status = $1, sent_at = $2, attempts = attempts + 1, last_error = ''
+		WHERE id = $3`,
+		entity.OutboxStatusSent, time.Now(), id)
+	return err
+}
+
+func (r *outboxRepository) MarkFailed(id uuid.UUID, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $1, last_error = $2
+		WHERE id = $3`,
+		nextAttemptAt, lastError, id)
+	return err
+}
+
+func (r *outboxRepository) MarkDead(id uuid.UUID, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox SET status = $1, attempts = attempts + 1, last_error = $2
+		WHERE id = $3`,
+		entity.OutboxStatusFailed, lastError, id)
+	return err
+}
+
+func (r *outboxRepository) PruneSent(olderThan time.Time) (int64, error) {
+	res, err := r.db.Exec("DELETE FROM outbox WHERE status = $1 AND sent_at < $2", entity.OutboxStatusSent, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func insertOutboxEvent(tx *sqlx.Tx, event *entity.OutboxEvent) error {
+	query := `INSERT INTO outbox (id, aggregate_id, event_type, payload, status, next_attempt_at, created_at, trace_parent)
+	          VALUES (:id, :aggregate_id, :event_type, :payload, :status, :next_attempt_at, :created_at, :trace_parent)`
+	_, err := tx.NamedExec(query, event)
+	return err
+}
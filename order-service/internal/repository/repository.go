@@ -6,8 +6,9 @@ import (
 )
 
 type OrderRepository interface {
-    Create(order *entity.Order) error
+    Create(order *entity.Order, events ...*entity.OutboxEvent) error
     Get(id uuid.UUID) (*entity.Order, error)
-    UpdateStatus(id uuid.UUID, status string) error
+    UpdateStatus(id uuid.UUID, change *entity.OrderStatusChange) error
+    GetHistory(orderID uuid.UUID) ([]*entity.OrderStatusChange, error)
     List(userID string, page, pageSize int) ([]*entity.Order, int, error)
 }
\ No newline at end of file
@@ -0,0 +1,150 @@
+package service
+
+import (
+	pbInventory "E-Commerce/inventory-service/proto"
+	"E-Commerce/order-service/internal/entity"
+	"E-Commerce/order-service/internal/repository"
+	pbProducer "E-Commerce/producer-service/proto"
+	pbUser "E-Commerce/user-service/proto"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+)
+
+// fakeOrderRepository keeps orders in memory and validates status changes
+// like the real repository.
+type fakeOrderRepository struct {
+	orders  map[uuid.UUID]*entity.Order
+	history map[uuid.UUID][]*entity.OrderStatusChange
+}
+
+func newFakeOrderRepository(orders ...*entity.Order) *fakeOrderRepository {
+	r := &fakeOrderRepository{
+		orders:  make(map[uuid.UUID]*entity.Order),
+		history: make(map[uuid.UUID][]*entity.OrderStatusChange),
+	}
+	for _, o := range orders {
+		r.orders[o.ID] = o
+	}
+	return r
+}
+
+func (r *fakeOrderRepository) Create(order *entity.Order, events ...*entity.OutboxEvent) error {
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r *fakeOrderRepository) Get(id uuid.UUID) (*entity.Order, error) {
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
+	copied := *o
+	return &copied, nil
+}
+
+func (r *fakeOrderRepository) UpdateStatus(id uuid.UUID, change *entity.OrderStatusChange) error {
+	o, ok := r.orders[id]
+	if !ok {
+		return repository.ErrOrderNotFound
+	}
+	if err := change.Validate(o.UserID, o.Status); err != nil {
+		return fmt.Errorf("%w: %s -> %s", err, o.Status, change.ToStatus)
+	}
+	change.OrderID, change.FromStatus, change.CreatedAt = id, o.Status, time.Now()
+	o.Status = change.ToStatus
+	r.history[id] = append(r.history[id], change)
+	return nil
+}
+
+func (r *fakeOrderRepository) GetHistory(orderID uuid.UUID) ([]*entity.OrderStatusChange, error) {
+	return r.history[orderID], nil
+}
+
+func (r *fakeOrderRepository) List(userID string, page, pageSize int) ([]*entity.Order, int, error) {
+	var orders []*entity.Order
+	for _, o := range r.orders {
+		if o.UserID == userID {
+			orders = append(orders, o)
+		}
+	}
+	return orders, len(orders), nil
+}
+
+// Mock producer client; calls without a method here panic
+type mockProducerClient struct {
+	pbProducer.ProducerServiceClient
+	mock.Mock
+}
+
+func (m *mockProducerClient) NotifyOrderCreated(ctx context.Context, req *pbProducer.OrderCreatedRequest, opts ...grpc.CallOption) (*pbProducer.OrderCreatedResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbProducer.OrderCreatedResponse), args.Error(1)
+}
+
+// Mock inventory client; calls without a method here panic
+type mockInventoryClient struct {
+	pbInventory.InventoryServiceClient
+	mock.Mock
+}
+
+func (m *mockInventoryClient) CommitReservation(ctx context.Context, req *pbInventory.CommitReservationRequest, opts ...grpc.CallOption) (*pbInventory.CommitReservationResponse, error) {
+	args := m.Called(req.ReservationId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbInventory.CommitReservationResponse), args.Error(1)
+}
+
+func (m *mockInventoryClient) ReleaseReservation(ctx context.Context, req *pbInventory.ReleaseReservationRequest, opts ...grpc.CallOption) (*pbInventory.ReleaseReservationResponse, error) {
+	args := m.Called(req.ReservationId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbInventory.ReleaseReservationResponse), args.Error(1)
+}
+
+func (m *mockInventoryClient) UpdateStock(ctx context.Context, req *pbInventory.UpdateStockRequest, opts ...grpc.CallOption) (*pbInventory.UpdateStockResponse, error) {
+	args := m.Called(req.ProductId, req.Quantity, req.OrderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbInventory.UpdateStockResponse), args.Error(1)
+}
+
+func (m *mockInventoryClient) GetProduct(ctx context.Context, req *pbInventory.GetProductRequest, opts ...grpc.CallOption) (*pbInventory.GetProductResponse, error) {
+	args := m.Called(req.Id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbInventory.GetProductResponse), args.Error(1)
+}
+
+func (m *mockInventoryClient) ReserveStock(ctx context.Context, req *pbInventory.ReserveStockRequest, opts ...grpc.CallOption) (*pbInventory.ReserveStockResponse, error) {
+	args := m.Called(req.OrderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbInventory.ReserveStockResponse), args.Error(1)
+}
+
+// Mock user client; calls without a method here panic
+type mockUserClient struct {
+	pbUser.UserServiceClient
+	mock.Mock
+}
+
+func (m *mockUserClient) GetUserProfile(ctx context.Context, req *pbUser.GetUserProfileRequest, opts ...grpc.CallOption) (*pbUser.UserProfile, error) {
+	args := m.Called(req.UserId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pbUser.UserProfile), args.Error(1)
+}
@@ -5,59 +5,63 @@ import (
 	"E-Commerce/order-service/internal/entity"
 	"E-Commerce/order-service/internal/repository"
 	"E-Commerce/order-service/internal/utils"
-	pbProducer "E-Commerce/producer-service/proto"
+	"E-Commerce/pkg/authz"
+	"E-Commerce/pkg/events"
 	pbUser "E-Commerce/user-service/proto"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/google/uuid"
-	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// Reasons inventory-service turns an order down; the handler reports them
+// to the client instead of as internal errors.
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidItems      = errors.New("invalid order items")
+)
+
+// ErrCancelNotPermitted is returned when a status update would cancel an
+// order for a caller without order:cancel_any.
+var ErrCancelNotPermitted = fmt.Errorf("cancelling an order requires permission %s", authz.OrderCancelAny)
+
 type OrderService interface {
-	CreateOrder(userID string, items []*entity.OrderItem) (*entity.Order, error)
+	CreateOrder(ctx context.Context, userID string, items []*entity.OrderItem) (*entity.Order, error)
 	GetOrder(id uuid.UUID) (*entity.Order, error)
-	UpdateOrderStatus(id uuid.UUID, status string) error
+	GetOrderAs(id uuid.UUID, actor, actorRole string) (*entity.Order, error)
+	UpdateOrderStatus(ctx context.Context, id uuid.UUID, status, actor, actorRole, reason string) error
 	ListOrders(userID string, page, pageSize int) ([]*entity.Order, int, error)
+	CancelOrder(ctx context.Context, id uuid.UUID, actor, actorRole, reason string) (*entity.Order, error)
 }
 
 type orderService struct {
 	repo            repository.OrderRepository
 	inventoryClient pbInventory.InventoryServiceClient
-	producerClient  pbProducer.ProducerServiceClient
 	userClient      pbUser.UserServiceClient
-	emailConfig     utils.EmailConfig
+	emailConfig     func() utils.EmailConfig
 }
 
-func NewOrderService(repo repository.OrderRepository, inventoryClient pbInventory.InventoryServiceClient, emailConfig utils.EmailConfig) OrderService {
-	// Connect to producer service
-	producerConn, err := grpc.Dial("localhost:50054", grpc.WithInsecure())
-	if err != nil {
-		log.Fatalf("Failed to connect to producer-service: %v", err)
-	}
-	producerClient := pbProducer.NewProducerServiceClient(producerConn)
-
-	// Connect to user service
-	userConn, err := grpc.Dial("localhost:50053", grpc.WithInsecure())
-	if err != nil {
-		log.Fatalf("Failed to connect to user-service: %v", err)
-	}
-	userClient := pbUser.NewUserServiceClient(userConn)
-
+// NewOrderService creates the order service. emailConfig is called for
+// every confirmation email, so reloaded SMTP credentials are picked up.
+func NewOrderService(repo repository.OrderRepository, inventoryClient pbInventory.InventoryServiceClient, userClient pbUser.UserServiceClient, emailConfig func() utils.EmailConfig) OrderService {
 	return &orderService{
 		repo:            repo,
 		inventoryClient: inventoryClient,
-		producerClient:  producerClient,
 		userClient:      userClient,
 		emailConfig:     emailConfig,
 	}
 }
 
-func (s *orderService) CreateOrder(userID string, items []*entity.OrderItem) (*entity.Order, error) {
+func (s *orderService) CreateOrder(ctx context.Context, userID string, items []*entity.OrderItem) (*entity.Order, error) {
 	// Get user details for email
-	userResp, err := s.userClient.GetUserProfile(context.Background(), &pbUser.GetUserProfileRequest{
+	userResp, err := s.userClient.GetUserProfile(ctx, &pbUser.GetUserProfileRequest{
 		UserId: userID,
 	})
 	if err != nil {
@@ -65,33 +69,17 @@ func (s *orderService) CreateOrder(userID string, items []*entity.OrderItem) (*e
 	}
 	userEmail := userResp.Email
 
-	// Validate and check stock
+	// Look up current prices
 	for _, item := range items {
 		pid := item.ProductID
-		p, err := s.inventoryClient.GetProduct(context.Background(), &pbInventory.GetProductRequest{Id: pid.String()})
+		p, err := s.inventoryClient.GetProduct(ctx, &pbInventory.GetProductRequest{Id: pid.String()})
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("%w: %s", ErrProductNotFound, pid)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to get product: %v", err)
 		}
 		item.Price = float64(p.Product.Price)
-
-		resp, err := s.inventoryClient.CheckStock(context.Background(), &pbInventory.CheckStockRequest{
-			ProductId: pid.String(),
-			Quantity:  int32(item.Quantity),
-		})
-		if err != nil || !resp.Available {
-			return nil, fmt.Errorf("insufficient stock for product %s", pid)
-		}
-	}
-
-	// Update inventory
-	for _, item := range items {
-		_, err := s.inventoryClient.UpdateStock(context.Background(), &pbInventory.UpdateStockRequest{
-			ProductId: item.ProductID.String(),
-			Quantity:  -int32(item.Quantity),
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to update stock: %v", err)
-		}
 	}
 
 	// Calculate total
@@ -103,34 +91,108 @@ func (s *orderService) CreateOrder(userID string, items []*entity.OrderItem) (*e
 	order := &entity.Order{
 		ID:          uuid.New(),
 		UserID:      userID,
-		Status:      "pending",
+		Status:      entity.StatusPending,
 		TotalAmount: total,
 		CreatedAt:   time.Now(),
 		Items:       items,
 	}
 
-	// Create order in database
-	if err := s.repo.Create(order); err != nil {
-		return nil, fmt.Errorf("failed to create order: %v", err)
+	// Hold stock for every item at once; nothing is decremented for good
+	// until the order row exists.
+	reserveItems := make([]*pbInventory.ReservationItem, len(items))
+	for i, item := range items {
+		reserveItems[i] = &pbInventory.ReservationItem{
+			ProductId: item.ProductID.String(),
+			Quantity:  int32(item.Quantity),
+		}
+	}
+	reservation, err := s.inventoryClient.ReserveStock(ctx, &pbInventory.ReserveStockRequest{
+		OrderId: order.ID.String(),
+		Items:   reserveItems,
+	})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.FailedPrecondition:
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientStock, status.Convert(err).Message())
+	case codes.NotFound:
+		return nil, ErrProductNotFound
+	case codes.InvalidArgument:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidItems, status.Convert(err).Message())
+	default:
+		return nil, fmt.Errorf("failed to reserve stock: %v", err)
 	}
+	order.ReservationID = reservation.ReservationId
 
-	// Notify producer service
-	productIDs := make([]string, len(items))
+	eventID := uuid.New()
+	created := entity.OrderCreatedPayload{
+		Version:       events.OrderCreatedVersion,
+		EventID:       eventID.String(),
+		OrderID:       order.ID.String(),
+		UserID:        userID,
+		Items:         make([]events.LineItem, len(items)),
+		Total:         total,
+		CreatedAt:     order.CreatedAt,
+		ReservationID: order.ReservationID,
+	}
 	for i, item := range items {
-		productIDs[i] = item.ProductID.String()
+		created.Items[i] = events.LineItem{
+			ProductID: item.ProductID.String(),
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+		}
 	}
-	_, err = s.producerClient.NotifyOrderCreated(context.Background(), &pbProducer.OrderCreatedRequest{
-		OrderId:    order.ID.String(),
-		ProductIds: productIDs,
+	payload, err := json.Marshal(created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode order.created event: %v", err)
+	}
+	event := &entity.OutboxEvent{
+		ID:            eventID,
+		AggregateID:   order.ID.String(),
+		EventType:     entity.EventOrderCreated,
+		Payload:       string(payload),
+		Status:        entity.OutboxStatusPending,
+		NextAttemptAt: order.CreatedAt,
+		CreatedAt:     order.CreatedAt,
+		// The relay publishes the event in the trace of this request
+		TraceParent: events.TraceParentFromContext(ctx),
+	}
+
+	// The reservation is committed by the outbox relay, which retries until
+	// inventory answers. If the reservation expired first, inventory takes
+	// its stock again on commit
+	commitPayload, err := json.Marshal(entity.ReservationCommitPayload{
+		OrderID:       order.ID.String(),
+		ReservationID: reservation.ReservationId,
 	})
 	if err != nil {
-		log.Printf("Failed to notify producer-service: %v", err)
+		return nil, fmt.Errorf("failed to encode reservation.commit event: %v", err)
+	}
+	commit := &entity.OutboxEvent{
+		ID:            uuid.New(),
+		AggregateID:   order.ID.String(),
+		EventType:     entity.EventReservationCommit,
+		Payload:       string(commitPayload),
+		Status:        entity.OutboxStatusPending,
+		NextAttemptAt: order.CreatedAt,
+		CreatedAt:     order.CreatedAt,
+		TraceParent:   events.TraceParentFromContext(ctx),
+	}
+
+	// Create order in database; the outbox relay publishes the event
+	if err := s.repo.Create(order, commit, event); err != nil {
+		_, relErr := s.inventoryClient.ReleaseReservation(ctx, &pbInventory.ReleaseReservationRequest{
+			ReservationId: reservation.ReservationId,
+		})
+		if relErr != nil {
+			log.Printf("Failed to release reservation %s: %v", reservation.ReservationId, relErr)
+		}
+		return nil, fmt.Errorf("failed to create order: %v", err)
 	}
 
 	// Prepare and send order confirmation email
 	emailItems := make([]utils.OrderItemData, len(items))
 	for i, item := range items {
-		p, err := s.inventoryClient.GetProduct(context.Background(), &pbInventory.GetProductRequest{Id: item.ProductID.String()})
+		p, err := s.inventoryClient.GetProduct(ctx, &pbInventory.GetProductRequest{Id: item.ProductID.String()})
 		if err != nil {
 			log.Printf("Failed to get product details for email: %v", err)
 			continue
@@ -151,19 +213,142 @@ func (s *orderService) CreateOrder(userID string, items []*entity.OrderItem) (*e
 		OrderStatus: order.Status,
 	}
 
-	if err := utils.SendOrderConfirmationEmail(s.emailConfig, emailData); err != nil {
+	if err := utils.SendOrderConfirmationEmail(s.emailConfig(), emailData); err != nil {
 		log.Printf("Failed to send order confirmation email: %v", err)
 	}
 
 	return order, nil
 }
 
+// GetOrderAs returns an order to its owner or to staff with
+// order:read_any. Anyone else gets repository.ErrOrderNotFound, as if the
+// order did not exist, so order IDs cannot be probed.
+func (s *orderService) GetOrderAs(id uuid.UUID, actor, actorRole string) (*entity.Order, error) {
+	order, err := s.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != actor && !authz.Has(actorRole, authz.OrderReadAny) {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
+}
+
 func (s *orderService) GetOrder(id uuid.UUID) (*entity.Order, error) {
-	return s.repo.Get(id)
+	order, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	order.History, err = s.repo.GetHistory(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order history: %v", err)
+	}
+	return order, nil
 }
 
-func (s *orderService) UpdateOrderStatus(id uuid.UUID, status string) error {
-	return s.repo.UpdateStatus(id, status)
+// UpdateOrderStatus moves an order to status. Cancelling gives stock back,
+// so it goes through CancelOrder and needs order:cancel_any, not just
+// order:update_status.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status, actor, actorRole, reason string) error {
+	if status == entity.StatusCancelled {
+		if !authz.Has(actorRole, authz.OrderCancelAny) {
+			return ErrCancelNotPermitted
+		}
+		_, err := s.CancelOrder(ctx, id, actor, actorRole, reason)
+		return err
+	}
+	return s.repo.UpdateStatus(id, &entity.OrderStatusChange{
+		ToStatus: status,
+		Actor:    actor,
+		Reason:   reason,
+	})
+}
+
+// CancelOrder cancels an order and gives its stock back. Owners may cancel
+// while the order is pending; staff with order:cancel_any may cancel any
+// order at any stage before shipment. Calling it again for an already
+// cancelled order only retries returning the stock, which inventory applies
+// at most once.
+func (s *orderService) CancelOrder(ctx context.Context, id uuid.UUID, actor, actorRole, reason string) (*entity.Order, error) {
+	order, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The owner never changes, so a cancelled order of someone else can be
+	// turned away before its stock is touched
+	cancelAny := authz.Has(actorRole, authz.OrderCancelAny)
+	if !cancelAny && order.UserID != actor {
+		return nil, entity.ErrNotOrderOwner
+	}
+
+	if order.Status != entity.StatusCancelled {
+		// Whether the order may still be cancelled is decided under its row
+		// lock, so a concurrent update cannot slip in
+		err := s.repo.UpdateStatus(id, &entity.OrderStatusChange{
+			ToStatus:  entity.StatusCancelled,
+			Actor:     actor,
+			Reason:    reason,
+			OwnerOnly: !cancelAny,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.returnStock(ctx, order); err != nil {
+		return nil, err
+	}
+	return s.GetOrder(id)
+}
+
+// returnStock gives the stock of a cancelled order back. Stock still held
+// by an uncommitted reservation is released, which is a no-op once the
+// reservation was released or expired; restocking it as well would count
+// it twice. Only committed stock, and that of orders placed before
+// reservations, is restocked.
+func (s *orderService) returnStock(ctx context.Context, order *entity.Order) error {
+	if order.ReservationID != "" {
+		_, err := s.inventoryClient.ReleaseReservation(ctx, &pbInventory.ReleaseReservationRequest{
+			ReservationId: order.ReservationID,
+		})
+		switch status.Code(err) {
+		case codes.OK:
+			return nil
+		case codes.FailedPrecondition:
+			// The reservation was committed, so its stock is restocked
+		default:
+			return fmt.Errorf("failed to release reservation %s: %v", order.ReservationID, err)
+		}
+	}
+	return s.restock(ctx, order)
+}
+
+// restock credits the quantities of a cancelled order back to inventory.
+// Quantities are merged per product because inventory deduplicates
+// restocks on (order ID, product ID).
+func (s *orderService) restock(ctx context.Context, order *entity.Order) error {
+	quantities := make(map[uuid.UUID]int)
+	var productIDs []uuid.UUID
+	for _, item := range order.Items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	for _, pid := range productIDs {
+		_, err := s.inventoryClient.UpdateStock(ctx, &pbInventory.UpdateStockRequest{
+			ProductId: pid.String(),
+			Quantity:  int32(quantities[pid]),
+			OrderId:   order.ID.String(),
+		})
+		// AlreadyExists means an earlier attempt restocked this product
+		if err != nil && status.Code(err) != codes.AlreadyExists {
+			return fmt.Errorf("failed to restock product %s: %v", pid, err)
+		}
+	}
+	return nil
 }
 
 func (s *orderService) ListOrders(userID string, page, pageSize int) ([]*entity.Order, int, error) {
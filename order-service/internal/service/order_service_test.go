@@ -0,0 +1,249 @@
+package service
+
+import (
+	pbInventory "E-Commerce/inventory-service/proto"
+	"E-Commerce/order-service/internal/entity"
+	"E-Commerce/order-service/internal/repository"
+	"E-Commerce/pkg/authz"
+	pbUser "E-Commerce/user-service/proto"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var productID = uuid.MustParse("1ba83505-10b6-49fb-bff6-da5a2ab37554")
+
+func newOrder(userID, orderStatus, reservationID string) *entity.Order {
+	return &entity.Order{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Status:        orderStatus,
+		ReservationID: reservationID,
+		Items: []*entity.OrderItem{
+			{ProductID: productID, Quantity: 2},
+			{ProductID: productID, Quantity: 1},
+		},
+	}
+}
+
+func TestCancelOrderReturnsStockOnce(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   *entity.Order
+		release error
+		restock bool
+	}{
+		{
+			// The reservation still holds the stock, or expired and gave it
+			// back already; either way releasing it is all there is to do
+			name:  "Uncommitted Reservation",
+			order: newOrder("user-1", entity.StatusPending, "res-1"),
+		},
+		{
+			name:    "Committed Reservation",
+			order:   newOrder("user-1", entity.StatusPending, "res-1"),
+			release: status.Error(codes.FailedPrecondition, "reservation is no longer active"),
+			restock: true,
+		},
+		{
+			name:    "Order Without Reservation",
+			order:   newOrder("user-1", entity.StatusPending, ""),
+			restock: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inventory := &mockInventoryClient{}
+			if tt.order.ReservationID != "" {
+				if tt.release != nil {
+					inventory.On("ReleaseReservation", "res-1").Return(nil, tt.release).Once()
+				} else {
+					inventory.On("ReleaseReservation", "res-1").Return(&pbInventory.ReleaseReservationResponse{Success: true}, nil).Once()
+				}
+			}
+			if tt.restock {
+				// Quantities are merged per product
+				inventory.On("UpdateStock", productID.String(), int32(3), tt.order.ID.String()).
+					Return(&pbInventory.UpdateStockResponse{Success: true}, nil).Once()
+			}
+			svc := NewOrderService(newFakeOrderRepository(tt.order), inventory, nil, nil)
+
+			order, err := svc.CancelOrder(context.Background(), tt.order.ID, "user-1", "user", "")
+			require.NoError(t, err)
+			assert.Equal(t, entity.StatusCancelled, order.Status)
+			inventory.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelOrderChecksStatusUnderLock(t *testing.T) {
+	order := newOrder("user-1", entity.StatusShipped, "res-1")
+	svc := NewOrderService(newFakeOrderRepository(order), &mockInventoryClient{}, nil, nil)
+
+	_, err := svc.CancelOrder(context.Background(), order.ID, "user-1", "user", "")
+	assert.ErrorIs(t, err, entity.ErrCancelNotAllowed)
+	assert.Equal(t, entity.StatusShipped, order.Status)
+}
+
+func TestGetOrderAsHidesOtherUsersOrders(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   string
+		role    string
+		visible bool
+	}{
+		{name: "Owner", actor: "user-1", role: authz.RoleUser, visible: true},
+		{name: "Finance Reads Any", actor: "user-2", role: authz.RoleFinance, visible: true},
+		{name: "Support Reads Any", actor: "user-2", role: authz.RoleSupport, visible: true},
+		{name: "Admin Reads Any", actor: "user-2", role: authz.RoleAdmin, visible: true},
+		{name: "Other User", actor: "user-2", role: authz.RoleUser},
+		{name: "Role Without Read Any", actor: "user-2", role: authz.RoleCatalogManager},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := newOrder("user-1", entity.StatusPending, "res-1")
+			svc := NewOrderService(newFakeOrderRepository(order), nil, nil, nil)
+
+			got, err := svc.GetOrderAs(order.ID, tt.actor, tt.role)
+			if !tt.visible {
+				// Indistinguishable from an order that does not exist
+				assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, order.ID, got.ID)
+		})
+	}
+}
+
+func TestCancelOrderChecksOwnership(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   string
+		role    string
+		allowed bool
+	}{
+		{name: "Owner", actor: "user-1", role: authz.RoleUser, allowed: true},
+		{name: "Support Cancels Any", actor: "user-2", role: authz.RoleSupport, allowed: true},
+		{name: "Admin Cancels Any", actor: "user-2", role: authz.RoleAdmin, allowed: true},
+		{name: "Other User", actor: "user-2", role: authz.RoleUser},
+		{name: "Read Any Is Not Cancel Any", actor: "user-2", role: authz.RoleFinance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := newOrder("user-1", entity.StatusPending, "res-1")
+			inventory := &mockInventoryClient{}
+			if tt.allowed {
+				inventory.On("ReleaseReservation", "res-1").Return(&pbInventory.ReleaseReservationResponse{Success: true}, nil).Once()
+			}
+			svc := NewOrderService(newFakeOrderRepository(order), inventory, nil, nil)
+
+			got, err := svc.CancelOrder(context.Background(), order.ID, tt.actor, tt.role, "")
+			if !tt.allowed {
+				assert.ErrorIs(t, err, entity.ErrNotOrderOwner)
+				assert.Equal(t, entity.StatusPending, order.Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, entity.StatusCancelled, got.Status)
+			inventory.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateOrderReportsRejections(t *testing.T) {
+	product := &pbInventory.GetProductResponse{Product: &pbInventory.Product{Id: productID.String(), Price: 10}}
+
+	tests := []struct {
+		name    string
+		product error
+		reserve error
+		want    error
+	}{
+		{
+			name:    "Unknown Product",
+			product: status.Error(codes.NotFound, "product not found"),
+			want:    ErrProductNotFound,
+		},
+		{
+			name:    "Insufficient Stock",
+			reserve: status.Error(codes.FailedPrecondition, "insufficient stock: product has 1, requested 3"),
+			want:    ErrInsufficientStock,
+		},
+		{
+			name:    "Invalid Quantity",
+			reserve: status.Error(codes.InvalidArgument, "reservation needs an order ID and positive quantities"),
+			want:    ErrInvalidItems,
+		},
+		{
+			name:    "Product Removed Before Reserving",
+			reserve: status.Error(codes.NotFound, "product not found"),
+			want:    ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &mockUserClient{}
+			users.On("GetUserProfile", "user-1").Return(&pbUser.UserProfile{Email: "user@example.com"}, nil)
+			inventory := &mockInventoryClient{}
+			if tt.product != nil {
+				inventory.On("GetProduct", productID.String()).Return(nil, tt.product)
+			} else {
+				inventory.On("GetProduct", productID.String()).Return(product, nil)
+				inventory.On("ReserveStock", mock.Anything).Return(nil, tt.reserve)
+			}
+			repo := newFakeOrderRepository()
+			svc := NewOrderService(repo, inventory, users, nil)
+
+			_, err := svc.CreateOrder(context.Background(), "user-1", newOrder("user-1", entity.StatusPending, "").Items)
+			assert.ErrorIs(t, err, tt.want)
+			assert.Empty(t, repo.orders, "a rejected order must not be stored")
+			inventory.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateOrderStatusCancelNeedsCancelAny(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		allowed bool
+	}{
+		{name: "Fulfillment", role: authz.RoleFulfillment},
+		{name: "Support", role: authz.RoleSupport, allowed: true},
+		{name: "Admin", role: authz.RoleAdmin, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := newOrder("user-1", entity.StatusPaid, "res-1")
+			inventory := &mockInventoryClient{}
+			if tt.allowed {
+				inventory.On("ReleaseReservation", "res-1").Return(&pbInventory.ReleaseReservationResponse{Success: true}, nil).Once()
+			}
+			svc := NewOrderService(newFakeOrderRepository(order), inventory, nil, nil)
+
+			err := svc.UpdateOrderStatus(context.Background(), order.ID, entity.StatusCancelled, "staff-1", tt.role, "")
+			if !tt.allowed {
+				// Nothing is cancelled and no stock is given back
+				assert.ErrorIs(t, err, ErrCancelNotPermitted)
+				assert.Equal(t, entity.StatusPaid, order.Status)
+				inventory.AssertNotCalled(t, "ReleaseReservation", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, entity.StatusCancelled, order.Status)
+			inventory.AssertExpectations(t)
+		})
+	}
+}
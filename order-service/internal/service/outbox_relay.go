@@ -0,0 +1,195 @@
+package service
+
+import (
+	pbInventory "E-Commerce/inventory-service/proto"
+	"E-Commerce/order-service/internal/entity"
+	"E-Commerce/order-service/internal/repository"
+	"E-Commerce/pkg/events"
+	pbProducer "E-Commerce/producer-service/proto"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errUndeliverable marks events that no retry can publish, such as ones
+// whose payload does not decode or whose type the relay does not know.
+var errUndeliverable = errors.New("undeliverable outbox event")
+
+type OutboxRelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a claimed event stays invisible to other relays
+	// while it is being published.
+	Lease time.Duration
+	// BaseBackoff and MaxBackoff bound the exponential delay between
+	// attempts for an event that failed to publish.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Retention is how long sent events are kept before being pruned.
+	Retention     time.Duration
+	PruneInterval time.Duration
+}
+
+func DefaultOutboxRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		PollInterval:  time.Second,
+		BatchSize:     50,
+		Lease:         30 * time.Second,
+		BaseBackoff:   time.Second,
+		MaxBackoff:    5 * time.Minute,
+		Retention:     7 * 24 * time.Hour,
+		PruneInterval: time.Hour,
+	}
+}
+
+// OutboxRelay publishes pending outbox events through producer-service and
+// commits stock reservations in inventory-service. An event is only marked
+// sent after it was acknowledged, so every event is delivered at least
+// once; consumers must tolerate duplicates.
+type OutboxRelay struct {
+	repo      repository.OutboxRepository
+	producer  pbProducer.ProducerServiceClient
+	inventory pbInventory.InventoryServiceClient
+	cfg       OutboxRelayConfig
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, producer pbProducer.ProducerServiceClient, inventory pbInventory.InventoryServiceClient, cfg OutboxRelayConfig) *OutboxRelay {
+	return &OutboxRelay{
+		repo:      repo,
+		producer:  producer,
+		inventory: inventory,
+		cfg:       cfg,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	poll := time.NewTicker(r.cfg.PollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(r.cfg.PruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			r.relayBatch(ctx)
+		case <-prune.C:
+			pruned, err := r.repo.PruneSent(time.Now().Add(-r.cfg.Retention))
+			if err != nil {
+				log.Printf("Failed to prune outbox: %v", err)
+			} else if pruned > 0 {
+				log.Printf("Pruned %d sent outbox events", pruned)
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) relayBatch(ctx context.Context) {
+	events, err := r.repo.ClaimPending(r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		log.Printf("Failed to claim outbox events: %v", err)
+		return
+	}
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return
+		}
+		err := r.publish(ctx, event)
+		if errors.Is(err, errUndeliverable) {
+			// Retrying would only hold up the events behind it
+			log.Printf("Giving up on %s event %s: %v", event.EventType, event.ID, err)
+			if err := r.repo.MarkDead(event.ID, err.Error()); err != nil {
+				log.Printf("Failed to record outbox failure for %s: %v", event.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			next := time.Now().Add(r.backoff(event.Attempts))
+			log.Printf("Failed to publish %s event %s (attempt %d), retrying at %s: %v",
+				event.EventType, event.ID, event.Attempts+1, next.Format(time.RFC3339), err)
+			if err := r.repo.MarkFailed(event.ID, next, err.Error()); err != nil {
+				log.Printf("Failed to record outbox failure for %s: %v", event.ID, err)
+			}
+			continue
+		}
+		if err := r.repo.MarkSent(event.ID); err != nil {
+			// The event will be published again once its lease expires
+			log.Printf("Failed to mark outbox event %s as sent: %v", event.ID, err)
+		}
+	}
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, event *entity.OutboxEvent) error {
+	switch event.EventType {
+	case entity.EventOrderCreated:
+		var payload entity.OrderCreatedPayload
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return fmt.Errorf("%w: invalid payload: %v", errUndeliverable, err)
+		}
+		req := &pbProducer.OrderCreatedRequest{
+			Version:       int32(payload.Version),
+			EventId:       payload.EventID,
+			OrderId:       payload.OrderID,
+			UserId:        payload.UserID,
+			Items:         make([]*pbProducer.OrderLineItem, len(payload.Items)),
+			Total:         payload.Total,
+			CreatedAt:     payload.CreatedAt.Format(time.RFC3339),
+			ReservationId: payload.ReservationID,
+		}
+		for i, item := range payload.Items {
+			req.Items[i] = &pbProducer.OrderLineItem{
+				ProductId: item.ProductID,
+				Quantity:  int32(item.Quantity),
+				UnitPrice: item.UnitPrice,
+			}
+		}
+		resp, err := r.producer.NotifyOrderCreated(events.ContextWithTraceParent(ctx, event.TraceParent), req)
+		if err != nil {
+			return err
+		}
+		if !resp.Success {
+			return fmt.Errorf("producer-service rejected the event")
+		}
+		return nil
+	case entity.EventReservationCommit:
+		var payload entity.ReservationCommitPayload
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return fmt.Errorf("%w: invalid payload: %v", errUndeliverable, err)
+		}
+		_, err := r.inventory.CommitReservation(events.ContextWithTraceParent(ctx, event.TraceParent), &pbInventory.CommitReservationRequest{
+			ReservationId: payload.ReservationID,
+		})
+		// Committing twice is a no-op, and an expired reservation takes its
+		// stock again. A reservation released by a cancellation, or whose
+		// stock ran out after it expired, can no longer be committed
+		switch status.Code(err) {
+		case codes.OK:
+			return nil
+		case codes.FailedPrecondition, codes.NotFound, codes.InvalidArgument:
+			return fmt.Errorf("%w: reservation %s of order %s cannot be committed: %v", errUndeliverable, payload.ReservationID, payload.OrderID, err)
+		}
+		return err
+	}
+	return fmt.Errorf("%w: unknown event type %q", errUndeliverable, event.EventType)
+}
+
+// backoff doubles the delay with every failed attempt, capped at MaxBackoff.
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	d := r.cfg.BaseBackoff
+	for i := 0; i < attempts && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.cfg.MaxBackoff {
+		d = r.cfg.MaxBackoff
+	}
+	return d
+}
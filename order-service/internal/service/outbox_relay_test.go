@@ -0,0 +1,149 @@
+package service
+
+import (
+	pbInventory "E-Commerce/inventory-service/proto"
+	"E-Commerce/order-service/internal/entity"
+	pbProducer "E-Commerce/producer-service/proto"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeOutboxRepository hands out its events once and records what the
+// relay did with each.
+type fakeOutboxRepository struct {
+	events []*entity.OutboxEvent
+	status map[uuid.UUID]string
+	errors map[uuid.UUID]string
+}
+
+func newFakeOutboxRepository(events ...*entity.OutboxEvent) *fakeOutboxRepository {
+	return &fakeOutboxRepository{
+		events: events,
+		status: make(map[uuid.UUID]string),
+		errors: make(map[uuid.UUID]string),
+	}
+}
+
+func (r *fakeOutboxRepository) ClaimPending(limit int, lease time.Duration) ([]*entity.OutboxEvent, error) {
+	claimed := r.events
+	r.events = nil
+	return claimed, nil
+}
+
+func (r *fakeOutboxRepository) MarkSent(id uuid.UUID) error {
+	r.status[id] = entity.OutboxStatusSent
+	return nil
+}
+
+func (r *fakeOutboxRepository) MarkFailed(id uuid.UUID, nextAttemptAt time.Time, lastError string) error {
+	r.status[id] = entity.OutboxStatusPending
+	r.errors[id] = lastError
+	return nil
+}
+
+func (r *fakeOutboxRepository) MarkDead(id uuid.UUID, lastError string) error {
+	r.status[id] = entity.OutboxStatusFailed
+	r.errors[id] = lastError
+	return nil
+}
+
+func (r *fakeOutboxRepository) PruneSent(olderThan time.Time) (int64, error) {
+	return 0, nil
+}
+
+func orderCreatedEvent(t *testing.T, orderID string) *entity.OutboxEvent {
+	t.Helper()
+	payload, err := json.Marshal(entity.OrderCreatedPayload{Version: 2, EventID: uuid.NewString(), OrderID: orderID})
+	require.NoError(t, err)
+	return &entity.OutboxEvent{ID: uuid.New(), EventType: entity.EventOrderCreated, Payload: string(payload)}
+}
+
+func forOrder(orderID string) interface{} {
+	return mock.MatchedBy(func(req *pbProducer.OrderCreatedRequest) bool { return req.OrderId == orderID })
+}
+
+func TestRelayMarksPublishedEventsSent(t *testing.T) {
+	event := orderCreatedEvent(t, "order-1")
+	repo := newFakeOutboxRepository(event)
+	producer := &mockProducerClient{}
+	producer.On("NotifyOrderCreated", forOrder("order-1")).Return(&pbProducer.OrderCreatedResponse{Success: true}, nil)
+
+	NewOutboxRelay(repo, producer, nil, DefaultOutboxRelayConfig()).relayBatch(context.Background())
+
+	assert.Equal(t, entity.OutboxStatusSent, repo.status[event.ID])
+	producer.AssertExpectations(t)
+}
+
+func TestRelayRetriesFailedPublishes(t *testing.T) {
+	event := orderCreatedEvent(t, "order-1")
+	repo := newFakeOutboxRepository(event)
+	producer := &mockProducerClient{}
+	producer.On("NotifyOrderCreated", forOrder("order-1")).Return(nil, errors.New("producer-service unavailable"))
+
+	NewOutboxRelay(repo, producer, nil, DefaultOutboxRelayConfig()).relayBatch(context.Background())
+
+	assert.Equal(t, entity.OutboxStatusPending, repo.status[event.ID])
+	assert.Contains(t, repo.errors[event.ID], "producer-service unavailable")
+}
+
+func TestRelayGivesUpOnUndeliverableEvents(t *testing.T) {
+	garbled := &entity.OutboxEvent{ID: uuid.New(), EventType: entity.EventOrderCreated, Payload: "{not json"}
+	unknown := &entity.OutboxEvent{ID: uuid.New(), EventType: "order.teleported", Payload: "{}"}
+	valid := orderCreatedEvent(t, "order-1")
+	repo := newFakeOutboxRepository(garbled, unknown, valid)
+	producer := &mockProducerClient{}
+	producer.On("NotifyOrderCreated", forOrder("order-1")).Return(&pbProducer.OrderCreatedResponse{Success: true}, nil).Once()
+
+	NewOutboxRelay(repo, producer, nil, DefaultOutboxRelayConfig()).relayBatch(context.Background())
+
+	assert.Equal(t, entity.OutboxStatusFailed, repo.status[garbled.ID])
+	assert.Contains(t, repo.errors[garbled.ID], "invalid payload")
+	assert.Equal(t, entity.OutboxStatusFailed, repo.status[unknown.ID])
+	assert.Contains(t, repo.errors[unknown.ID], "unknown event type")
+	assert.Equal(t, entity.OutboxStatusSent, repo.status[valid.ID], "undeliverable events must not block the batch")
+	producer.AssertExpectations(t)
+}
+
+func commitEvent(t *testing.T, reservationID string) *entity.OutboxEvent {
+	t.Helper()
+	payload, err := json.Marshal(entity.ReservationCommitPayload{OrderID: "order-1", ReservationID: reservationID})
+	require.NoError(t, err)
+	return &entity.OutboxEvent{ID: uuid.New(), EventType: entity.EventReservationCommit, Payload: string(payload)}
+}
+
+func TestRelayCommitsReservations(t *testing.T) {
+	committed := commitEvent(t, "res-1")
+	unavailable := commitEvent(t, "res-2")
+	expired := commitEvent(t, "res-3")
+	repo := newFakeOutboxRepository(committed, unavailable, expired)
+	inventory := &mockInventoryClient{}
+	inventory.On("CommitReservation", "res-1").Return(&pbInventory.CommitReservationResponse{Success: true}, nil)
+	inventory.On("CommitReservation", "res-2").Return(nil, status.Error(codes.Unavailable, "connection refused"))
+	inventory.On("CommitReservation", "res-3").Return(nil, status.Error(codes.FailedPrecondition, "reservation is no longer active"))
+
+	NewOutboxRelay(repo, nil, inventory, DefaultOutboxRelayConfig()).relayBatch(context.Background())
+
+	assert.Equal(t, entity.OutboxStatusSent, repo.status[committed.ID])
+	assert.Equal(t, entity.OutboxStatusPending, repo.status[unavailable.ID], "the commit is retried until inventory is back")
+	assert.Equal(t, entity.OutboxStatusFailed, repo.status[expired.ID])
+	assert.Contains(t, repo.errors[expired.ID], "cannot be committed")
+	inventory.AssertExpectations(t)
+}
+
+func TestRelayBackoff(t *testing.T) {
+	r := NewOutboxRelay(nil, nil, nil, OutboxRelayConfig{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	assert.Equal(t, time.Second, r.backoff(0))
+	assert.Equal(t, 4*time.Second, r.backoff(2))
+	assert.Equal(t, 10*time.Second, r.backoff(20))
+}
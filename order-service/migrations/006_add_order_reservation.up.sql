@@ -0,0 +1,2 @@
+-- Orders placed before stock reservations have none and keep an empty ID
+ALTER TABLE orders ADD COLUMN reservation_id VARCHAR(36) NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- Orders finished under the old lifecycle were "completed", which no longer
+-- exists and has no way out. Move them to its successor, delivered, and
+-- record the move in their timeline.
+INSERT INTO order_status_history (order_id, from_status, to_status, actor, reason)
+SELECT id, status, 'delivered', 'migration', 'completed was replaced by delivered'
+FROM orders
+WHERE status = 'completed';
+
+UPDATE orders SET status = 'delivered' WHERE status = 'completed';
@@ -81,6 +81,82 @@ func (x *OrderItem) GetPrice() float32 {
 	return 0
 }
 
+type OrderStatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromStatus    string                 `protobuf:"bytes,1,opt,name=from_status,json=fromStatus,proto3" json:"from_status,omitempty"`
+	ToStatus      string                 `protobuf:"bytes,2,opt,name=to_status,json=toStatus,proto3" json:"to_status,omitempty"`
+	Actor         string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	ChangedAt     string                 `protobuf:"bytes,5,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderStatusChange) Reset() {
+	*x = OrderStatusChange{}
+	mi := &file_order_service_proto_order_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderStatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderStatusChange) ProtoMessage() {}
+
+func (x *OrderStatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_order_service_proto_order_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderStatusChange.ProtoReflect.Descriptor instead.
+func (*OrderStatusChange) Descriptor() ([]byte, []int) {
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OrderStatusChange) GetFromStatus() string {
+	if x != nil {
+		return x.FromStatus
+	}
+	return ""
+}
+
+func (x *OrderStatusChange) GetToStatus() string {
+	if x != nil {
+		return x.ToStatus
+	}
+	return ""
+}
+
+func (x *OrderStatusChange) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *OrderStatusChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *OrderStatusChange) GetChangedAt() string {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return ""
+}
+
 type Order struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -88,13 +164,14 @@ type Order struct {
 	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
 	TotalAmount   float32                `protobuf:"fixed32,4,opt,name=total_amount,json=totalAmount,proto3" json:"total_amount,omitempty"`
 	Items         []*OrderItem           `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
+	Timeline      []*OrderStatusChange   `protobuf:"bytes,6,rep,name=timeline,proto3" json:"timeline,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Order) Reset() {
 	*x = Order{}
-	mi := &file_order_service_proto_order_proto_msgTypes[1]
+	mi := &file_order_service_proto_order_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -106,7 +183,7 @@ func (x *Order) String() string {
 func (*Order) ProtoMessage() {}
 
 func (x *Order) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[1]
+	mi := &file_order_service_proto_order_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -119,7 +196,7 @@ func (x *Order) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Order.ProtoReflect.Descriptor instead.
 func (*Order) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{1}
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Order) GetId() string {
@@ -157,9 +234,16 @@ func (x *Order) GetItems() []*OrderItem {
 	return nil
 }
 
+func (x *Order) GetTimeline() []*OrderStatusChange {
+	if x != nil {
+		return x.Timeline
+	}
+	return nil
+}
+
+// The order is placed for the caller of the forwarded access token.
 type CreateOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Items         []*OrderItem           `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -167,7 +251,7 @@ type CreateOrderRequest struct {
 
 func (x *CreateOrderRequest) Reset() {
 	*x = CreateOrderRequest{}
-	mi := &file_order_service_proto_order_proto_msgTypes[2]
+	mi := &file_order_service_proto_order_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -179,7 +263,7 @@ func (x *CreateOrderRequest) String() string {
 func (*CreateOrderRequest) ProtoMessage() {}
 
 func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[2]
+	mi := &file_order_service_proto_order_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -192,14 +276,7 @@ func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
 func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{2}
-}
-
-func (x *CreateOrderRequest) GetUserId() string {
-	if x != nil {
-		return x.UserId
-	}
-	return ""
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CreateOrderRequest) GetItems() []*OrderItem {
@@ -218,7 +295,7 @@ type CreateOrderResponse struct {
 
 func (x *CreateOrderResponse) Reset() {
 	*x = CreateOrderResponse{}
-	mi := &file_order_service_proto_order_proto_msgTypes[3]
+	mi := &file_order_service_proto_order_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -230,7 +307,7 @@ func (x *CreateOrderResponse) String() string {
 func (*CreateOrderResponse) ProtoMessage() {}
 
 func (x *CreateOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[3]
+	mi := &file_order_service_proto_order_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -243,7 +320,7 @@ func (x *CreateOrderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateOrderResponse.ProtoReflect.Descriptor instead.
 func (*CreateOrderResponse) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{3}
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CreateOrderResponse) GetOrder() *Order {
@@ -262,7 +339,7 @@ type GetOrderRequest struct {
 
 func (x *GetOrderRequest) Reset() {
 	*x = GetOrderRequest{}
-	mi := &file_order_service_proto_order_proto_msgTypes[4]
+	mi := &file_order_service_proto_order_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -274,7 +351,7 @@ func (x *GetOrderRequest) String() string {
 func (*GetOrderRequest) ProtoMessage() {}
 
 func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[4]
+	mi := &file_order_service_proto_order_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -287,7 +364,7 @@ func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
 func (*GetOrderRequest) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{4}
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetOrderRequest) GetId() string {
@@ -306,7 +383,7 @@ type GetOrderResponse struct {
 
 func (x *GetOrderResponse) Reset() {
 	*x = GetOrderResponse{}
-	mi := &file_order_service_proto_order_proto_msgTypes[5]
+	mi := &file_order_service_proto_order_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -318,7 +395,7 @@ func (x *GetOrderResponse) String() string {
 func (*GetOrderResponse) ProtoMessage() {}
 
 func (x *GetOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[5]
+	mi := &file_order_service_proto_order_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -331,7 +408,7 @@ func (x *GetOrderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetOrderResponse.ProtoReflect.Descriptor instead.
 func (*GetOrderResponse) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{5}
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetOrderResponse) GetOrder() *Order {
@@ -341,17 +418,19 @@ func (x *GetOrderResponse) GetOrder() *Order {
 	return nil
 }
 
+// The change is recorded under the caller of the forwarded access token.
 type UpdateOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateOrderRequest) Reset() {
 	*x = UpdateOrderRequest{}
-	mi := &file_order_service_proto_order_proto_msgTypes[6]
+	mi := &file_order_service_proto_order_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -363,7 +442,7 @@ func (x *UpdateOrderRequest) String() string {
 func (*UpdateOrderRequest) ProtoMessage() {}
 
 func (x *UpdateOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[6]
+	mi := &file_order_service_proto_order_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -376,7 +455,7 @@ func (x *UpdateOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateOrderRequest.ProtoReflect.Descriptor instead.
 func (*UpdateOrderRequest) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{6}
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *UpdateOrderRequest) GetId() string {
@@ -393,6 +472,13 @@ func (x *UpdateOrderRequest) GetStatus() string {
 	return ""
 }
 
+func (x *UpdateOrderRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
 type UpdateOrderResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Order         *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
@@ -402,7 +488,7 @@ type UpdateOrderResponse struct {
 
 func (x *UpdateOrderResponse) Reset() {
 	*x = UpdateOrderResponse{}
-	mi := &file_order_service_proto_order_proto_msgTypes[7]
+	mi := &file_order_service_proto_order_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -414,7 +500,7 @@ func (x *UpdateOrderResponse) String() string {
 func (*UpdateOrderResponse) ProtoMessage() {}
 
 func (x *UpdateOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[7]
+	mi := &file_order_service_proto_order_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -427,7 +513,7 @@ func (x *UpdateOrderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateOrderResponse.ProtoReflect.Descriptor instead.
 func (*UpdateOrderResponse) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{7}
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *UpdateOrderResponse) GetOrder() *Order {
@@ -437,6 +523,106 @@ func (x *UpdateOrderResponse) GetOrder() *Order {
 	return nil
 }
 
+// The order is cancelled on behalf of the caller of the forwarded access
+// token.
+type CancelOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
+	mi := &file_order_service_proto_order_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderRequest) ProtoMessage() {}
+
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_service_proto_order_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CancelOrderRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CancelOrderRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderResponse) Reset() {
+	*x = CancelOrderResponse{}
+	mi := &file_order_service_proto_order_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderResponse) ProtoMessage() {}
+
+func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_order_service_proto_order_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderResponse.ProtoReflect.Descriptor instead.
+func (*CancelOrderResponse) Descriptor() ([]byte, []int) {
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CancelOrderResponse) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+// user_id defaults to the caller; listing another user's orders needs
+// order:read_any.
 type ListOrdersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -448,7 +634,7 @@ type ListOrdersRequest struct {
 
 func (x *ListOrdersRequest) Reset() {
 	*x = ListOrdersRequest{}
-	mi := &file_order_service_proto_order_proto_msgTypes[8]
+	mi := &file_order_service_proto_order_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -460,7 +646,7 @@ func (x *ListOrdersRequest) String() string {
 func (*ListOrdersRequest) ProtoMessage() {}
 
 func (x *ListOrdersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[8]
+	mi := &file_order_service_proto_order_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -473,7 +659,7 @@ func (x *ListOrdersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListOrdersRequest.ProtoReflect.Descriptor instead.
 func (*ListOrdersRequest) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{8}
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ListOrdersRequest) GetUserId() string {
@@ -507,7 +693,7 @@ type ListOrdersResponse struct {
 
 func (x *ListOrdersResponse) Reset() {
 	*x = ListOrdersResponse{}
-	mi := &file_order_service_proto_order_proto_msgTypes[9]
+	mi := &file_order_service_proto_order_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -519,7 +705,7 @@ func (x *ListOrdersResponse) String() string {
 func (*ListOrdersResponse) ProtoMessage() {}
 
 func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_service_proto_order_proto_msgTypes[9]
+	mi := &file_order_service_proto_order_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,7 +718,7 @@ func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListOrdersResponse.ProtoReflect.Descriptor instead.
 func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
-	return file_order_service_proto_order_proto_rawDescGZIP(), []int{9}
+	return file_order_service_proto_order_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ListOrdersResponse) GetOrders() []*Order {
@@ -558,26 +744,41 @@ const file_order_service_proto_order_proto_rawDesc = "" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
 	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x02R\x05price\"\x93\x01\n" +
+	"\x05price\x18\x03 \x01(\x02R\x05price\"\x9e\x01\n" +
+	"\x11OrderStatusChange\x12\x1f\n" +
+	"\vfrom_status\x18\x01 \x01(\tR\n" +
+	"fromStatus\x12\x1b\n" +
+	"\tto_status\x18\x02 \x01(\tR\btoStatus\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"changed_at\x18\x05 \x01(\tR\tchangedAt\"\xc9\x01\n" +
 	"\x05Order\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
 	"\x06status\x18\x03 \x01(\tR\x06status\x12!\n" +
 	"\ftotal_amount\x18\x04 \x01(\x02R\vtotalAmount\x12&\n" +
-	"\x05items\x18\x05 \x03(\v2\x10.order.OrderItemR\x05items\"U\n" +
-	"\x12CreateOrderRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12&\n" +
-	"\x05items\x18\x02 \x03(\v2\x10.order.OrderItemR\x05items\"9\n" +
+	"\x05items\x18\x05 \x03(\v2\x10.order.OrderItemR\x05items\x124\n" +
+	"\btimeline\x18\x06 \x03(\v2\x18.order.OrderStatusChangeR\btimeline\"K\n" +
+	"\x12CreateOrderRequest\x12&\n" +
+	"\x05items\x18\x02 \x03(\v2\x10.order.OrderItemR\x05itemsJ\x04\b\x01\x10\x02R\auser_id\"9\n" +
 	"\x13CreateOrderResponse\x12\"\n" +
 	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"!\n" +
 	"\x0fGetOrderRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"6\n" +
 	"\x10GetOrderResponse\x12\"\n" +
-	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"<\n" +
+	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"a\n" +
 	"\x12UpdateOrderRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\"9\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reasonJ\x04\b\x03\x10\x04R\x05actor\"9\n" +
 	"\x13UpdateOrderResponse\x12\"\n" +
+	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"[\n" +
+	"\x12CancelOrderRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reasonJ\x04\b\x02\x10\x03J\x04\b\x03\x10\x04R\x05actorR\n" +
+	"actor_role\"9\n" +
+	"\x13CancelOrderResponse\x12\"\n" +
 	"\x05order\x18\x01 \x01(\v2\f.order.OrderR\x05order\"]\n" +
 	"\x11ListOrdersRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
@@ -585,13 +786,14 @@ const file_order_service_proto_order_proto_rawDesc = "" +
 	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"P\n" +
 	"\x12ListOrdersResponse\x12$\n" +
 	"\x06orders\x18\x01 \x03(\v2\f.order.OrderR\x06orders\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total2\x9a\x02\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total2\xe0\x02\n" +
 	"\fOrderService\x12D\n" +
 	"\vCreateOrder\x12\x19.order.CreateOrderRequest\x1a\x1a.order.CreateOrderResponse\x12;\n" +
 	"\bGetOrder\x12\x16.order.GetOrderRequest\x1a\x17.order.GetOrderResponse\x12D\n" +
 	"\vUpdateOrder\x12\x19.order.UpdateOrderRequest\x1a\x1a.order.UpdateOrderResponse\x12A\n" +
 	"\n" +
-	"ListOrders\x12\x18.order.ListOrdersRequest\x1a\x19.order.ListOrdersResponseB\tZ\a./protob\x06proto3"
+	"ListOrders\x12\x18.order.ListOrdersRequest\x1a\x19.order.ListOrdersResponse\x12D\n" +
+	"\vCancelOrder\x12\x19.order.CancelOrderRequest\x1a\x1a.order.CancelOrderResponseB\tZ\a./protob\x06proto3"
 
 var (
 	file_order_service_proto_order_proto_rawDescOnce sync.Once
@@ -605,39 +807,46 @@ func file_order_service_proto_order_proto_rawDescGZIP() []byte {
 	return file_order_service_proto_order_proto_rawDescData
 }
 
-var file_order_service_proto_order_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_order_service_proto_order_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_order_service_proto_order_proto_goTypes = []any{
 	(*OrderItem)(nil),           // 0: order.OrderItem
-	(*Order)(nil),               // 1: order.Order
-	(*CreateOrderRequest)(nil),  // 2: order.CreateOrderRequest
-	(*CreateOrderResponse)(nil), // 3: order.CreateOrderResponse
-	(*GetOrderRequest)(nil),     // 4: order.GetOrderRequest
-	(*GetOrderResponse)(nil),    // 5: order.GetOrderResponse
-	(*UpdateOrderRequest)(nil),  // 6: order.UpdateOrderRequest
-	(*UpdateOrderResponse)(nil), // 7: order.UpdateOrderResponse
-	(*ListOrdersRequest)(nil),   // 8: order.ListOrdersRequest
-	(*ListOrdersResponse)(nil),  // 9: order.ListOrdersResponse
+	(*OrderStatusChange)(nil),   // 1: order.OrderStatusChange
+	(*Order)(nil),               // 2: order.Order
+	(*CreateOrderRequest)(nil),  // 3: order.CreateOrderRequest
+	(*CreateOrderResponse)(nil), // 4: order.CreateOrderResponse
+	(*GetOrderRequest)(nil),     // 5: order.GetOrderRequest
+	(*GetOrderResponse)(nil),    // 6: order.GetOrderResponse
+	(*UpdateOrderRequest)(nil),  // 7: order.UpdateOrderRequest
+	(*UpdateOrderResponse)(nil), // 8: order.UpdateOrderResponse
+	(*CancelOrderRequest)(nil),  // 9: order.CancelOrderRequest
+	(*CancelOrderResponse)(nil), // 10: order.CancelOrderResponse
+	(*ListOrdersRequest)(nil),   // 11: order.ListOrdersRequest
+	(*ListOrdersResponse)(nil),  // 12: order.ListOrdersResponse
 }
 var file_order_service_proto_order_proto_depIdxs = []int32{
 	0,  // 0: order.Order.items:type_name -> order.OrderItem
-	0,  // 1: order.CreateOrderRequest.items:type_name -> order.OrderItem
-	1,  // 2: order.CreateOrderResponse.order:type_name -> order.Order
-	1,  // 3: order.GetOrderResponse.order:type_name -> order.Order
-	1,  // 4: order.UpdateOrderResponse.order:type_name -> order.Order
-	1,  // 5: order.ListOrdersResponse.orders:type_name -> order.Order
-	2,  // 6: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
-	4,  // 7: order.OrderService.GetOrder:input_type -> order.GetOrderRequest
-	6,  // 8: order.OrderService.UpdateOrder:input_type -> order.UpdateOrderRequest
-	8,  // 9: order.OrderService.ListOrders:input_type -> order.ListOrdersRequest
-	3,  // 10: order.OrderService.CreateOrder:output_type -> order.CreateOrderResponse
-	5,  // 11: order.OrderService.GetOrder:output_type -> order.GetOrderResponse
-	7,  // 12: order.OrderService.UpdateOrder:output_type -> order.UpdateOrderResponse
-	9,  // 13: order.OrderService.ListOrders:output_type -> order.ListOrdersResponse
-	10, // [10:14] is the sub-list for method output_type
-	6,  // [6:10] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	1,  // 1: order.Order.timeline:type_name -> order.OrderStatusChange
+	0,  // 2: order.CreateOrderRequest.items:type_name -> order.OrderItem
+	2,  // 3: order.CreateOrderResponse.order:type_name -> order.Order
+	2,  // 4: order.GetOrderResponse.order:type_name -> order.Order
+	2,  // 5: order.UpdateOrderResponse.order:type_name -> order.Order
+	2,  // 6: order.CancelOrderResponse.order:type_name -> order.Order
+	2,  // 7: order.ListOrdersResponse.orders:type_name -> order.Order
+	3,  // 8: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
+	5,  // 9: order.OrderService.GetOrder:input_type -> order.GetOrderRequest
+	7,  // 10: order.OrderService.UpdateOrder:input_type -> order.UpdateOrderRequest
+	11, // 11: order.OrderService.ListOrders:input_type -> order.ListOrdersRequest
+	9,  // 12: order.OrderService.CancelOrder:input_type -> order.CancelOrderRequest
+	4,  // 13: order.OrderService.CreateOrder:output_type -> order.CreateOrderResponse
+	6,  // 14: order.OrderService.GetOrder:output_type -> order.GetOrderResponse
+	8,  // 15: order.OrderService.UpdateOrder:output_type -> order.UpdateOrderResponse
+	12, // 16: order.OrderService.ListOrders:output_type -> order.ListOrdersResponse
+	10, // 17: order.OrderService.CancelOrder:output_type -> order.CancelOrderResponse
+	13, // [13:18] is the sub-list for method output_type
+	8,  // [8:13] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_order_service_proto_order_proto_init() }
@@ -651,7 +860,7 @@ func file_order_service_proto_order_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_order_service_proto_order_proto_rawDesc), len(file_order_service_proto_order_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,71 @@
+package authz
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKey is the gRPC metadata key the access token travels in.
+const MetadataKey = "authorization"
+
+// ForwardToken returns a copy of ctx whose outgoing gRPC calls carry
+// token, so the backends can check the caller themselves.
+func ForwardToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, "Bearer "+token)
+}
+
+// Authenticated is the permission of methods any caller with a valid
+// token may use.
+const Authenticated Permission = ""
+
+// Rules maps full gRPC method names, e.g.
+// "/inventory.InventoryService/CreateProduct", to the permission they
+// require. Methods not listed need no token: other services call them
+// on their own behalf.
+type Rules map[string]Permission
+
+// ServerOption enforces rules on every unary RPC of a server.
+func ServerOption(v *Verifier, rules Rules) grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(UnaryServerInterceptor(v, rules))
+}
+
+// UnaryServerInterceptor verifies the forwarded access token of the
+// methods in rules and checks the permission they require. The caller is
+// available to the handler through FromContext.
+func UnaryServerInterceptor(v *Verifier, rules Rules) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		required, ok := rules[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		token, ok := bearerToken(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing access token")
+		}
+		claims, err := v.Verify(ctx, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid access token")
+		}
+		id := claims.Identity()
+		if required != Authenticated && !id.Can(required) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s requires permission %s", info.FullMethod, required)
+		}
+		return handler(NewContext(ctx, id), req)
+	}
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(MetadataKey)
+	if len(values) == 0 {
+		return "", false
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	return token, ok && token != ""
+}
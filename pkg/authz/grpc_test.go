@@ -0,0 +1,68 @@
+package authz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	public, private := newEd25519Key(t, "k1")
+	v := NewVerifier(NewKeySet((&issuer{keys: []PublicKey{public}}).fetch, time.Hour))
+	intercept := UnaryServerInterceptor(v, Rules{
+		"/inventory.InventoryService/CreateProduct": ProductWrite,
+		"/order.OrderService/GetOrder":              Authenticated,
+	})
+
+	var caller Identity
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		caller, _ = FromContext(ctx)
+		return "ok", nil
+	}
+	call := func(method, token string) codes.Code {
+		ctx := context.Background()
+		if token != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, "Bearer "+token))
+		}
+		_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return status.Code(err)
+	}
+	manager := sign(t, jwt.SigningMethodEdDSA, "k1", "jti-1", RoleCatalogManager, private)
+	customer := sign(t, jwt.SigningMethodEdDSA, "k1", "jti-2", RoleUser, private)
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		want   codes.Code
+	}{
+		{"open method without token", "/inventory.InventoryService/UpdateStock", "", codes.OK},
+		{"missing token", "/inventory.InventoryService/CreateProduct", "", codes.Unauthenticated},
+		{"invalid token", "/inventory.InventoryService/CreateProduct", "not-a-jwt", codes.Unauthenticated},
+		{"missing permission", "/inventory.InventoryService/CreateProduct", customer, codes.PermissionDenied},
+		{"granted permission", "/inventory.InventoryService/CreateProduct", manager, codes.OK},
+		{"authenticated only", "/order.OrderService/GetOrder", customer, codes.OK},
+	}
+	for _, tt := range tests {
+		if got := call(tt.method, tt.token); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if caller != (Identity{UserID: "user-1", Role: RoleUser}) {
+		t.Errorf("handler saw caller %+v, want the customer", caller)
+	}
+}
+
+func TestForwardToken(t *testing.T) {
+	ctx := ForwardToken(context.Background(), "abc")
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if got := md.Get(MetadataKey); len(got) != 1 || got[0] != "Bearer abc" {
+		t.Errorf("forwarded %q, want Bearer abc", got)
+	}
+}
@@ -0,0 +1,28 @@
+package authz
+
+import "context"
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	UserID string
+	Role   string
+}
+
+// Can reports whether the caller's role grants p.
+func (id Identity) Can(p Permission) bool {
+	return Has(id.Role, p)
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the caller stored in ctx by the gRPC interceptor or
+// NewContext.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
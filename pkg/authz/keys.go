@@ -0,0 +1,106 @@
+package authz
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// PublicKey is a key tokens are verified with.
+type PublicKey struct {
+	ID        string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+// FetchFunc returns the current public keys of the token issuer, the
+// user-service.
+type FetchFunc func(ctx context.Context) ([]PublicKey, error)
+
+// ParsePublicKey parses a PKIX, DER-encoded public key as published by
+// the user-service's GetSigningKeys RPC.
+func ParsePublicKey(kid, alg string, der []byte) (PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("failed to parse signing key %s: %v", kid, err)
+	}
+	return PublicKey{ID: kid, Algorithm: alg, Key: key}, nil
+}
+
+// KeySet caches the issuer's public keys. They are fetched again once
+// they are older than the refresh interval, or when a token names a key
+// the cache does not know, which is how a newly rotated key is picked
+// up. If fetching fails the cached keys keep being used.
+type KeySet struct {
+	fetch   FetchFunc
+	refresh time.Duration
+	// minInterval limits how often unknown key IDs can trigger a fetch
+	minInterval time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]PublicKey
+	fetchedAt time.Time
+
+	fetchMu   sync.Mutex
+	lastFetch time.Time
+}
+
+// NewKeySet creates a key set that fetches keys with fetch and refreshes
+// them every refresh.
+func NewKeySet(fetch FetchFunc, refresh time.Duration) *KeySet {
+	return &KeySet{fetch: fetch, refresh: refresh, minInterval: 5 * time.Second}
+}
+
+// Key returns the key with the given ID.
+func (s *KeySet) Key(ctx context.Context, kid string) (PublicKey, error) {
+	if key, ok, fresh := s.lookup(kid); ok && fresh {
+		return key, nil
+	}
+
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+	// Another request may have fetched while this one waited
+	key, ok, fresh := s.lookup(kid)
+	if ok && fresh {
+		return key, nil
+	}
+	if time.Since(s.lastFetch) >= s.minInterval {
+		s.lastFetch = time.Now()
+		if err := s.update(ctx); err != nil {
+			log.Printf("Failed to fetch signing keys: %v", err)
+		}
+		key, ok, _ = s.lookup(kid)
+	}
+	if !ok {
+		return PublicKey{}, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (s *KeySet) lookup(kid string) (key PublicKey, ok, fresh bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok = s.keys[kid]
+	return key, ok, time.Since(s.fetchedAt) < s.refresh
+}
+
+func (s *KeySet) update(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	fetched, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]PublicKey, len(fetched))
+	for _, k := range fetched {
+		keys[k.ID] = k
+	}
+	s.mu.Lock()
+	s.keys, s.fetchedAt = keys, time.Now()
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package authz decides what a caller may do. Each role grants a set of
+// permissions; the api-gateway checks them per route and the backends
+// check them again per gRPC method, from the access token the gateway
+// forwards.
+//
+// Access tokens only carry the role. Permissions are resolved from this
+// catalog, so changing what a role grants takes effect without issuing
+// new tokens.
+package authz
+
+import "sort"
+
+// Permission allows one kind of action, named resource:action.
+type Permission string
+
+const (
+	// ProductWrite allows creating, updating and deleting products.
+	ProductWrite Permission = "product:write"
+	// OrderUpdateStatus allows moving orders through fulfilment.
+	OrderUpdateStatus Permission = "order:update_status"
+	// OrderReadAny allows reading every user's orders, not just one's own.
+	OrderReadAny Permission = "order:read_any"
+	// OrderCancelAny allows cancelling any user's order at any stage
+	// before shipment.
+	OrderCancelAny Permission = "order:cancel_any"
+	// UserManageRoles allows changing users' roles.
+	UserManageRoles Permission = "user:manage_roles"
+)
+
+// Roles. Everyone who registers is a RoleUser, a customer, who needs no
+// permission to manage their own orders and profile.
+const (
+	RoleUser           = "user"
+	RoleAdmin          = "admin"
+	RoleCatalogManager = "catalog-manager"
+	RoleFulfillment    = "fulfillment"
+	RoleSupport        = "support"
+	RoleFinance        = "finance"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleUser: nil,
+	RoleAdmin: {
+		ProductWrite,
+		OrderUpdateStatus,
+		OrderReadAny,
+		OrderCancelAny,
+		UserManageRoles,
+	},
+	RoleCatalogManager: {ProductWrite},
+	RoleFulfillment:    {OrderUpdateStatus, OrderReadAny},
+	RoleSupport:        {OrderReadAny, OrderCancelAny},
+	RoleFinance:        {OrderReadAny},
+}
+
+// ValidRole reports whether role is a known role.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Roles returns every known role, sorted.
+func Roles() []string {
+	roles := make([]string, 0, len(rolePermissions))
+	for role := range rolePermissions {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Permissions returns the permissions role grants. Unknown roles grant
+// none.
+func Permissions(role string) []Permission {
+	return append([]Permission(nil), rolePermissions[role]...)
+}
+
+// Has reports whether role grants p.
+func Has(role string, p Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
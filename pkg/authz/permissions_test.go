@@ -0,0 +1,34 @@
+package authz
+
+import "testing"
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		role string
+		can  []Permission
+		not  []Permission
+	}{
+		{RoleAdmin, []Permission{ProductWrite, OrderUpdateStatus, OrderReadAny, OrderCancelAny, UserManageRoles}, nil},
+		{RoleUser, nil, []Permission{ProductWrite, OrderReadAny}},
+		{RoleCatalogManager, []Permission{ProductWrite}, []Permission{OrderReadAny, UserManageRoles}},
+		{RoleFulfillment, []Permission{OrderUpdateStatus, OrderReadAny}, []Permission{ProductWrite, OrderCancelAny}},
+		{RoleSupport, []Permission{OrderReadAny, OrderCancelAny}, []Permission{OrderUpdateStatus}},
+		{RoleFinance, []Permission{OrderReadAny}, []Permission{OrderUpdateStatus, ProductWrite}},
+		{"owner", nil, []Permission{ProductWrite}},
+	}
+	for _, tt := range tests {
+		for _, p := range tt.can {
+			if !Has(tt.role, p) {
+				t.Errorf("%s lacks %s", tt.role, p)
+			}
+		}
+		for _, p := range tt.not {
+			if Has(tt.role, p) {
+				t.Errorf("%s has %s", tt.role, p)
+			}
+		}
+	}
+	if ValidRole("owner") || !ValidRole(RoleFinance) {
+		t.Error("ValidRole does not follow the catalog")
+	}
+}
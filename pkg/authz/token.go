@@ -0,0 +1,62 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims are the claims of the access tokens the user-service issues.
+type Claims struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Identity returns the caller the token was issued to.
+func (c *Claims) Identity() Identity {
+	return Identity{UserID: c.UserID, Role: c.Role}
+}
+
+// Verifier checks access tokens against the issuer's public keys. Only
+// the user-service holds private keys, so no other service can issue
+// tokens.
+type Verifier struct {
+	keys *KeySet
+}
+
+func NewVerifier(keys *KeySet) *Verifier {
+	return &Verifier{keys: keys}
+}
+
+// Verify returns the claims of token if it was signed by a current key,
+// has not expired and has an ID (jti) it can be revoked by.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid")
+		}
+		key, err := v.keys.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if t.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("token signed with %s, but key %s is for %s", t.Method.Alg(), kid, key.Algorithm)
+		}
+		return key.Key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.ID == "" {
+		return nil, errors.New("token has no jti")
+	}
+	return claims, nil
+}
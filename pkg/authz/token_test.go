@@ -0,0 +1,163 @@
+package authz
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// issuer plays the user-service: it signs tokens and serves its public
+// keys, counting how often they are fetched.
+type issuer struct {
+	keys    []PublicKey
+	fetches int
+	err     error
+}
+
+func (i *issuer) fetch(ctx context.Context) ([]PublicKey, error) {
+	i.fetches++
+	if i.err != nil {
+		return nil, i.err
+	}
+	return i.keys, nil
+}
+
+func newEd25519Key(t *testing.T, kid string) (PublicKey, crypto.Signer) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return PublicKey{ID: kid, Algorithm: "EdDSA", Key: public}, private
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid, jti, role string, key interface{}) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, &Claims{
+		UserID: "user-1",
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifyCachesKeys(t *testing.T) {
+	public, private := newEd25519Key(t, "k1")
+	iss := &issuer{keys: []PublicKey{public}}
+	v := NewVerifier(NewKeySet(iss.fetch, time.Hour))
+
+	token := sign(t, jwt.SigningMethodEdDSA, "k1", "jti-1", RoleUser, private)
+	for i := 0; i < 3; i++ {
+		claims, err := v.Verify(context.Background(), token)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+		if claims.UserID != "user-1" {
+			t.Errorf("user_id = %q, want user-1", claims.UserID)
+		}
+	}
+	if iss.fetches != 1 {
+		t.Errorf("fetched keys %d times, want once", iss.fetches)
+	}
+}
+
+func TestVerifyDuringRotation(t *testing.T) {
+	oldPublic, oldPrivate := newEd25519Key(t, "old")
+	iss := &issuer{keys: []PublicKey{oldPublic}}
+	keys := NewKeySet(iss.fetch, time.Hour)
+	keys.minInterval = 0
+	v := NewVerifier(keys)
+	oldToken := sign(t, jwt.SigningMethodEdDSA, "old", "jti-1", RoleUser, oldPrivate)
+	if _, err := v.Verify(context.Background(), oldToken); err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+
+	// The user-service starts signing with a new key and keeps publishing
+	// the old one until its tokens expire
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss.keys = []PublicKey{{ID: "new", Algorithm: "RS256", Key: &rsaKey.PublicKey}, oldPublic}
+	newToken := sign(t, jwt.SigningMethodRS256, "new", "jti-2", RoleUser, rsaKey)
+
+	if _, err := v.Verify(context.Background(), newToken); err != nil {
+		t.Errorf("a token signed with a new key must trigger a fetch: %v", err)
+	}
+	if _, err := v.Verify(context.Background(), oldToken); err != nil {
+		t.Errorf("tokens of the previous key stay valid during the overlap: %v", err)
+	}
+	if iss.fetches != 2 {
+		t.Errorf("fetched keys %d times, want twice", iss.fetches)
+	}
+}
+
+func TestUnknownKeysDoNotFloodTheIssuer(t *testing.T) {
+	public, _ := newEd25519Key(t, "k1")
+	_, forged := newEd25519Key(t, "forged")
+	iss := &issuer{keys: []PublicKey{public}}
+	v := NewVerifier(NewKeySet(iss.fetch, time.Hour))
+
+	for i := 0; i < 10; i++ {
+		if _, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodEdDSA, "forged", "jti-1", RoleUser, forged)); err == nil {
+			t.Fatal("verified a token of an unknown key")
+		}
+	}
+	if iss.fetches != 1 {
+		t.Errorf("fetched keys %d times, want once", iss.fetches)
+	}
+}
+
+func TestVerifyKeepsStaleKeysWhenFetchFails(t *testing.T) {
+	public, private := newEd25519Key(t, "k1")
+	iss := &issuer{keys: []PublicKey{public}}
+	keys := NewKeySet(iss.fetch, time.Hour)
+	v := NewVerifier(keys)
+	token := sign(t, jwt.SigningMethodEdDSA, "k1", "jti-1", RoleUser, private)
+	if _, err := v.Verify(context.Background(), token); err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+
+	keys.refresh = 0
+	keys.minInterval = 0
+	iss.err = errors.New("user-service unavailable")
+	if _, err := v.Verify(context.Background(), token); err != nil {
+		t.Errorf("rejected a token while the issuer is down: %v", err)
+	}
+	if iss.fetches != 2 {
+		t.Errorf("fetched keys %d times, want twice", iss.fetches)
+	}
+}
+
+func TestVerifyRejectsUnverifiableTokens(t *testing.T) {
+	public, private := newEd25519Key(t, "k1")
+	v := NewVerifier(NewKeySet((&issuer{keys: []PublicKey{public}}).fetch, time.Hour))
+
+	for name, token := range map[string]string{
+		"no kid":        sign(t, jwt.SigningMethodEdDSA, "", "jti-1", RoleUser, private),
+		"no jti":        sign(t, jwt.SigningMethodEdDSA, "k1", "", RoleUser, private),
+		"shared secret": sign(t, jwt.SigningMethodHS256, "k1", "jti-1", RoleUser, []byte("GJKHKhhuaduh3459HhukhjAFHDUAFLJFANmlifa935h72h")),
+		"unsigned":      sign(t, jwt.SigningMethodNone, "k1", "jti-1", RoleUser, jwt.UnsafeAllowNoneSignatureType),
+	} {
+		if _, err := v.Verify(context.Background(), token); err == nil {
+			t.Errorf("%s: verified", name)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package broker defines the broker-neutral publish/subscribe API the
+// services use for domain events. Backends live in subpackages: rabbitmq
+// for production and memory for tests and local development.
+package broker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrConfirmTimeout means the broker did not confirm a message in time.
+	// The message may or may not have been delivered, so publishing it
+	// again is safe only for consumers that deduplicate.
+	ErrConfirmTimeout = errors.New("timed out waiting for the broker to confirm the message")
+	ErrRejected       = errors.New("broker rejected the message")
+	ErrUnroutable     = errors.New("message could not be routed to any queue")
+	ErrClosed         = errors.New("broker client is closed")
+	ErrNoAcknowledger = errors.New("delivery has no acknowledger")
+)
+
+// Headers that track a message through retries and dead-lettering.
+const (
+	AttemptHeader          = "x-attempt"
+	LastErrorHeader        = "x-last-error"
+	DeadLetterReasonHeader = "x-dead-letter-reason"
+	DeadLetteredAtHeader   = "x-dead-lettered-at"
+)
+
+// RetryHeaders are dropped when a dead-lettered message is replayed.
+var RetryHeaders = []string{AttemptHeader, LastErrorHeader, DeadLetterReasonHeader, DeadLetteredAtHeader}
+
+// Message is an event as published and as delivered.
+type Message struct {
+	// ID identifies the event across redeliveries and republishing.
+	ID string
+	// Topic is the routing key the message was published with. It is set
+	// on delivered messages and ignored on publish.
+	Topic string
+	// Key groups related messages, e.g. the events of one order. Backends
+	// that partition a topic keep messages with the same key together and
+	// in order; the others ignore it.
+	Key         string
+	Headers     map[string]interface{}
+	ContentType string
+	Timestamp   time.Time
+	Body        []byte
+}
+
+// Attempt returns how many times the message has been delivered for
+// processing, counting the current delivery. Messages without an attempt
+// header are on their first attempt.
+func (m Message) Attempt() int {
+	switch n := m.Headers[AttemptHeader].(type) {
+	case int:
+		return n
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case string:
+		if i, err := strconv.Atoi(n); err == nil {
+			return i
+		}
+	}
+	return 1
+}
+
+// Header returns a string header, or "" if it is missing.
+func (m Message) Header(key string) string {
+	s, _ := m.Headers[key].(string)
+	return s
+}
+
+// WithHeaders returns a copy of m with headers merged over its own.
+func (m Message) WithHeaders(headers map[string]interface{}) Message {
+	merged := make(map[string]interface{}, len(m.Headers)+len(headers))
+	for k, v := range m.Headers {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		merged[k] = v
+	}
+	m.Headers = merged
+	return m
+}
+
+// ResetRetries returns a copy of m without its retry and dead-letter
+// headers, giving it a fresh attempt budget.
+func (m Message) ResetRetries() Message {
+	m = m.WithHeaders(nil)
+	for _, h := range RetryHeaders {
+		delete(m.Headers, h)
+	}
+	return m
+}
+
+// Acknowledger settles a single delivery. Every delivery must be settled
+// exactly once.
+type Acknowledger interface {
+	// Ack marks the delivery as processed.
+	Ack() error
+	// Nack rejects the delivery. With requeue it is delivered again right
+	// away; otherwise it is dead-lettered.
+	Nack(requeue bool) error
+	// Retry schedules the message for redelivery after delay, with headers
+	// merged over its own, and acknowledges this delivery.
+	Retry(delay time.Duration, headers map[string]interface{}) error
+	// DeadLetter moves the message, with headers merged over its own, to
+	// the dead-letter queue and acknowledges this delivery.
+	DeadLetter(headers map[string]interface{}) error
+}
+
+// Delivery is a message handed to a subscriber, to be settled through its
+// Acknowledger.
+type Delivery struct {
+	Message
+	Redelivered  bool
+	Acknowledger Acknowledger
+}
+
+func (d Delivery) Ack() error {
+	if d.Acknowledger == nil {
+		return ErrNoAcknowledger
+	}
+	return d.Acknowledger.Ack()
+}
+
+func (d Delivery) Nack(requeue bool) error {
+	if d.Acknowledger == nil {
+		return ErrNoAcknowledger
+	}
+	return d.Acknowledger.Nack(requeue)
+}
+
+func (d Delivery) Retry(delay time.Duration, headers map[string]interface{}) error {
+	if d.Acknowledger == nil {
+		return ErrNoAcknowledger
+	}
+	return d.Acknowledger.Retry(delay, headers)
+}
+
+func (d Delivery) DeadLetter(headers map[string]interface{}) error {
+	if d.Acknowledger == nil {
+		return ErrNoAcknowledger
+	}
+	return d.Acknowledger.DeadLetter(headers)
+}
+
+type Publisher interface {
+	// Publish sends msg on topic and returns once the broker has accepted
+	// it, or fails when ctx is done first.
+	Publish(ctx context.Context, topic string, msg Message) error
+	Close() error
+}
+
+// Subscriber consumes one subscription, e.g. a RabbitMQ queue, together
+// with the dead-letter queue its rejected messages end up in.
+type Subscriber interface {
+	// Subscribe starts delivering messages, with at most prefetch of them
+	// unsettled at a time (0 means unlimited).
+	Subscribe(prefetch int) (<-chan Delivery, error)
+	// Unsubscribe stops new deliveries. Deliveries already received can
+	// still be settled; the deliveries channel is closed afterwards.
+	Unsubscribe() error
+	// PeekDeadLetters returns up to limit dead-lettered messages without
+	// removing them.
+	PeekDeadLetters(limit int) ([]Message, error)
+	// ReplayDeadLetters moves up to limit dead-lettered messages accepted by
+	// match back onto the subscription with their retry headers cleared,
+	// and returns how many were moved.
+	ReplayDeadLetters(match func(Message) bool, limit int) (int, error)
+	// Close stops the subscription; unsettled deliveries are redelivered.
+	Close() error
+}
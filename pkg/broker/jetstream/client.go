@@ -0,0 +1,245 @@
+// Package jetstream implements the broker API on NATS JetStream. Topics
+// map to subjects with the message key as the last token, so the events of
+// one order share a subject; durable consumers give consumer-group
+// semantics, and a message is only acknowledged once it was processed.
+//
+// The backend talks to JetStream through Client. Connect returns one backed
+// by a NATS server; jetstreamtest provides an in-process stand-in.
+package jetstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"E-Commerce/pkg/broker"
+
+	"github.com/nats-io/nats.go"
+	natsjs "github.com/nats-io/nats.go/jetstream"
+)
+
+var ErrMsgNotFound = errors.New("jetstream: message not found")
+
+type StreamConfig struct {
+	Name     string
+	Subjects []string
+	// MaxAge bounds how long messages are kept; 0 keeps them forever.
+	MaxAge time.Duration
+}
+
+type ConsumerConfig struct {
+	// Durable names the consumer. Every subscriber using the same durable
+	// name shares its messages, like a Kafka consumer group.
+	Durable       string
+	FilterSubject string
+	// AckWait is how long a delivered message may stay unacknowledged
+	// before it is delivered again.
+	AckWait time.Duration
+	// MaxAckPending bounds the unacknowledged messages across all
+	// subscribers of the consumer; 0 means the server default.
+	MaxAckPending int
+}
+
+// Client is the part of JetStream the backend uses.
+type Client interface {
+	// AddStream creates the stream, or updates it to cfg if it exists.
+	AddStream(ctx context.Context, cfg StreamConfig) error
+	// Publish stores a message and returns once a stream acknowledged it.
+	// Messages with the same non-empty id are stored once within the
+	// stream's duplicate window. It fails with broker.ErrUnroutable if no
+	// stream takes subject.
+	Publish(ctx context.Context, subject, id string, header nats.Header, data []byte) error
+	// Consume joins the durable consumer on stream, creating it if needed,
+	// and pulls its messages with at most batch of them buffered.
+	Consume(ctx context.Context, stream string, cfg ConsumerConfig, batch int) (Consumption, error)
+	// GetMsg returns the message stored at seq, or ErrMsgNotFound.
+	GetMsg(ctx context.Context, stream string, seq uint64) (*StoredMsg, error)
+	// Sequences returns the first and last sequence numbers of stream.
+	Sequences(ctx context.Context, stream string) (first, last uint64, err error)
+	DeleteMsg(ctx context.Context, stream string, seq uint64) error
+	Close() error
+}
+
+// Consumption is a running pull from a durable consumer.
+type Consumption interface {
+	// Messages is closed once the consumption is stopped.
+	Messages() <-chan Msg
+	// Stop stops pulling. Messages already delivered can still be settled;
+	// buffered ones are redelivered once their ack wait runs out.
+	Stop()
+}
+
+// Msg is a message delivered by a durable consumer.
+type Msg interface {
+	Subject() string
+	Header() nats.Header
+	Data() []byte
+	// NumDelivered counts the deliveries of the message, including this one.
+	NumDelivered() uint64
+	Ack() error
+	// Nak redelivers the message after delay.
+	Nak(delay time.Duration) error
+	// Term stops redelivery of the message without processing it.
+	Term() error
+}
+
+type StoredMsg struct {
+	Subject  string
+	Sequence uint64
+	Header   nats.Header
+	Data     []byte
+	Time     time.Time
+}
+
+type natsClient struct {
+	nc *nats.Conn
+	js natsjs.JetStream
+}
+
+// Connect connects to the NATS server at url. The connection reconnects
+// on its own for as long as the client is open.
+func Connect(url string) (Client, error) {
+	nc, err := nats.Connect(url, nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	js, err := natsjs.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return &natsClient{nc: nc, js: js}, nil
+}
+
+func (c *natsClient) AddStream(ctx context.Context, cfg StreamConfig) error {
+	_, err := c.js.CreateOrUpdateStream(ctx, natsjs.StreamConfig{
+		Name:       cfg.Name,
+		Subjects:   cfg.Subjects,
+		MaxAge:     cfg.MaxAge,
+		Retention:  natsjs.LimitsPolicy,
+		Storage:    natsjs.FileStorage,
+		Duplicates: 2 * time.Minute,
+	})
+	return err
+}
+
+func (c *natsClient) Publish(ctx context.Context, subject, id string, header nats.Header, data []byte) error {
+	var opts []natsjs.PublishOpt
+	if id != "" {
+		opts = append(opts, natsjs.WithMsgID(id))
+	}
+	_, err := c.js.PublishMsg(ctx, &nats.Msg{Subject: subject, Header: header, Data: data}, opts...)
+	switch {
+	case errors.Is(err, natsjs.ErrNoStreamResponse), errors.Is(err, nats.ErrNoResponders):
+		return fmt.Errorf("%w: no stream takes %s", broker.ErrUnroutable, subject)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, nats.ErrTimeout):
+		return broker.ErrConfirmTimeout
+	}
+	return err
+}
+
+func (c *natsClient) Consume(ctx context.Context, stream string, cfg ConsumerConfig, batch int) (Consumption, error) {
+	cons, err := c.js.CreateOrUpdateConsumer(ctx, stream, natsjs.ConsumerConfig{
+		Durable:       cfg.Durable,
+		FilterSubject: cfg.FilterSubject,
+		AckPolicy:     natsjs.AckExplicitPolicy,
+		AckWait:       cfg.AckWait,
+		MaxAckPending: cfg.MaxAckPending,
+		DeliverPolicy: natsjs.DeliverAllPolicy,
+		MaxDeliver:    -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	iter, err := cons.Messages(natsjs.PullMaxMessages(batch))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Msg)
+	go func() {
+		defer close(out)
+		for {
+			m, err := iter.Next()
+			if errors.Is(err, natsjs.ErrMsgIteratorClosed) {
+				return
+			}
+			if err != nil {
+				// Missed heartbeats and the like; the iterator recovers
+				continue
+			}
+			out <- natsMsg{m}
+		}
+	}()
+	return &natsConsumption{iter: iter, out: out}, nil
+}
+
+func (c *natsClient) GetMsg(ctx context.Context, stream string, seq uint64) (*StoredMsg, error) {
+	s, err := c.js.Stream(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := s.GetMsg(ctx, seq)
+	if errors.Is(err, natsjs.ErrMsgNotFound) {
+		return nil, ErrMsgNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &StoredMsg{Subject: raw.Subject, Sequence: raw.Sequence, Header: raw.Header, Data: raw.Data, Time: raw.Time}, nil
+}
+
+func (c *natsClient) Sequences(ctx context.Context, stream string) (uint64, uint64, error) {
+	s, err := c.js.Stream(ctx, stream)
+	if err != nil {
+		return 0, 0, err
+	}
+	info, err := s.Info(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return info.State.FirstSeq, info.State.LastSeq, nil
+}
+
+func (c *natsClient) DeleteMsg(ctx context.Context, stream string, seq uint64) error {
+	s, err := c.js.Stream(ctx, stream)
+	if err != nil {
+		return err
+	}
+	return s.DeleteMsg(ctx, seq)
+}
+
+func (c *natsClient) Close() error {
+	c.nc.Close()
+	return nil
+}
+
+type natsConsumption struct {
+	iter natsjs.MessagesContext
+	out  chan Msg
+}
+
+func (c *natsConsumption) Messages() <-chan Msg { return c.out }
+func (c *natsConsumption) Stop()                { c.iter.Stop() }
+
+type natsMsg struct {
+	natsjs.Msg
+}
+
+func (m natsMsg) Header() nats.Header { return m.Headers() }
+
+func (m natsMsg) NumDelivered() uint64 {
+	md, err := m.Metadata()
+	if err != nil {
+		return 1
+	}
+	return md.NumDelivered
+}
+
+func (m natsMsg) Nak(delay time.Duration) error {
+	if delay <= 0 {
+		return m.Msg.Nak()
+	}
+	return m.NakWithDelay(delay)
+}
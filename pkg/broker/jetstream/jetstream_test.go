@@ -0,0 +1,286 @@
+package jetstream_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/jetstream"
+	"E-Commerce/pkg/broker/jetstream/jetstreamtest"
+)
+
+const (
+	topic   = "order.created"
+	durable = "order.created.consumer"
+)
+
+func newPublisher(t *testing.T, srv *jetstreamtest.Server) broker.Publisher {
+	t.Helper()
+	pub, err := jetstream.NewPublisher(srv.Connect(), time.Second, jetstream.OrderEvents)
+	if err != nil {
+		t.Fatalf("failed to create publisher: %v", err)
+	}
+	return pub
+}
+
+func newSubscriber(t *testing.T, srv *jetstreamtest.Server, ackWait time.Duration) broker.Subscriber {
+	t.Helper()
+	sub, err := jetstream.NewSubscriber(srv.Connect(), jetstream.SubscriberConfig{
+		Stream:           jetstream.OrderEvents,
+		DeadLetterStream: jetstream.OrderEventsDeadLetters,
+		Topic:            topic,
+		Consumer:         jetstream.ConsumerConfig{Durable: durable, AckWait: ackWait},
+		Timeout:          time.Second,
+	})
+	if err != nil {
+		t.Fatalf("failed to create subscriber: %v", err)
+	}
+	return sub
+}
+
+func subscribe(t *testing.T, sub broker.Subscriber, prefetch int) <-chan broker.Delivery {
+	t.Helper()
+	msgs, err := sub.Subscribe(prefetch)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	return msgs
+}
+
+func publish(t *testing.T, pub broker.Publisher, key string, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		msg := broker.Message{ID: id, Key: key, ContentType: "application/json", Body: []byte(`{}`)}
+		if err := pub.Publish(context.Background(), topic, msg); err != nil {
+			t.Fatalf("failed to publish %s: %v", id, err)
+		}
+	}
+}
+
+func receive(t *testing.T, msgs <-chan broker.Delivery) broker.Delivery {
+	t.Helper()
+	select {
+	case d := <-msgs:
+		return d
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message was delivered")
+	}
+	return broker.Delivery{}
+}
+
+func expectNone(t *testing.T, msgs <-chan broker.Delivery) {
+	t.Helper()
+	select {
+	case d := <-msgs:
+		t.Fatalf("unexpected delivery of %s", d.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPublishUsesSubjectPerKey(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	pub := newPublisher(t, srv)
+	sub := newSubscriber(t, srv, 0)
+	msgs := subscribe(t, sub, 1)
+
+	publish(t, pub, "order-1", "m1")
+	d := receive(t, msgs)
+	if d.ID != "m1" || d.Topic != topic || d.Key != "order-1" {
+		t.Fatalf("got id %q topic %q key %q, want m1 %s order-1", d.ID, d.Topic, d.Key, topic)
+	}
+	if d.ContentType != "application/json" || d.Attempt() != 1 || d.Redelivered {
+		t.Fatalf("got content type %q attempt %d redelivered %v", d.ContentType, d.Attempt(), d.Redelivered)
+	}
+	if err := d.Ack(); err != nil {
+		t.Fatalf("failed to ack: %v", err)
+	}
+	if n := srv.Pending(jetstream.OrderEventsStream, durable); n != 0 {
+		t.Fatalf("pending = %d after ack, want 0", n)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	tests := []struct {
+		key, want string
+	}{
+		{"42", "order.created.42"},
+		{"", "order.created._"},
+		{"a.b c", "order.created.a_b_c"},
+	}
+	for _, tt := range tests {
+		if got := jetstream.Subject(topic, tt.key); got != tt.want {
+			t.Errorf("Subject(%q, %q) = %q, want %q", topic, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestPublishDeduplicatesByID(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	pub := newPublisher(t, srv)
+
+	publish(t, pub, "order-1", "m1", "m1", "m2")
+	if n := srv.StreamLen(jetstream.OrderEventsStream); n != 2 {
+		t.Fatalf("stream holds %d messages, want 2", n)
+	}
+}
+
+func TestPublishUnroutable(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	pub := newPublisher(t, srv)
+
+	err := pub.Publish(context.Background(), "payment.created", broker.Message{ID: "m1"})
+	if !errors.Is(err, broker.ErrUnroutable) {
+		t.Fatalf("got %v, want ErrUnroutable", err)
+	}
+}
+
+func TestSubscribersShareDurableConsumer(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	pub := newPublisher(t, srv)
+	a := subscribe(t, newSubscriber(t, srv, 0), 1)
+	b := subscribe(t, newSubscriber(t, srv, 0), 1)
+
+	publish(t, pub, "order-1", "m1", "m2", "m3", "m4")
+	// With a prefetch of 1 neither subscriber can take every message
+	// while its first one is unacknowledged.
+	first := []broker.Delivery{receive(t, a), receive(t, b)}
+	if first[0].ID == first[1].ID {
+		t.Fatalf("both subscribers got %s", first[0].ID)
+	}
+
+	seen := make(map[string]int)
+	for _, d := range first {
+		seen[d.ID]++
+		d.Ack()
+	}
+	for len(seen) < 4 {
+		var d broker.Delivery
+		select {
+		case d = <-a:
+		case d = <-b:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of 4 messages were delivered", len(seen))
+		}
+		seen[d.ID]++
+		if err := d.Ack(); err != nil {
+			t.Fatalf("failed to ack: %v", err)
+		}
+	}
+	expectNone(t, a)
+	expectNone(t, b)
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("%s delivered %d times, want once", id, n)
+		}
+	}
+}
+
+func TestUnacknowledgedMessageIsRedelivered(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	pub := newPublisher(t, srv)
+	sub := newSubscriber(t, srv, 50*time.Millisecond)
+	msgs := subscribe(t, sub, 0)
+
+	publish(t, pub, "order-1", "m1")
+	receive(t, msgs) // never settled, as if processing crashed
+
+	d := receive(t, msgs)
+	if d.ID != "m1" || d.Attempt() != 2 || !d.Redelivered {
+		t.Fatalf("got %s attempt %d redelivered %v, want m1 attempt 2 redelivered", d.ID, d.Attempt(), d.Redelivered)
+	}
+	if err := d.Ack(); err != nil {
+		t.Fatalf("failed to ack: %v", err)
+	}
+	expectNone(t, msgs)
+}
+
+func TestRetryRedeliversAfterDelay(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	pub := newPublisher(t, srv)
+	sub := newSubscriber(t, srv, 0)
+	msgs := subscribe(t, sub, 1)
+
+	publish(t, pub, "order-1", "m1")
+	start := time.Now()
+	if err := receive(t, msgs).Retry(50*time.Millisecond, nil); err != nil {
+		t.Fatalf("failed to retry: %v", err)
+	}
+	d := receive(t, msgs)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("redelivered after %v, want at least 50ms", elapsed)
+	}
+	if d.Attempt() != 2 {
+		t.Fatalf("attempt = %d, want 2", d.Attempt())
+	}
+	d.Ack()
+}
+
+func TestDeadLetterPeekAndReplay(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	pub := newPublisher(t, srv)
+	sub := newSubscriber(t, srv, 0)
+	msgs := subscribe(t, sub, 1)
+
+	publish(t, pub, "order-1", "m1")
+	publish(t, pub, "order-2", "m2")
+	for i := 0; i < 2; i++ {
+		d := receive(t, msgs)
+		if err := d.DeadLetter(map[string]interface{}{broker.DeadLetterReasonHeader: "invalid"}); err != nil {
+			t.Fatalf("failed to dead-letter %s: %v", d.ID, err)
+		}
+	}
+	expectNone(t, msgs)
+
+	dead, err := sub.PeekDeadLetters(10)
+	if err != nil {
+		t.Fatalf("failed to peek: %v", err)
+	}
+	if len(dead) != 2 || dead[0].ID != "m1" || dead[1].Key != "order-2" {
+		t.Fatalf("got dead letters %+v, want m1 and m2", dead)
+	}
+	if dead[0].Header(broker.DeadLetterReasonHeader) != "invalid" {
+		t.Errorf("reason = %q, want invalid", dead[0].Header(broker.DeadLetterReasonHeader))
+	}
+
+	n, err := sub.ReplayDeadLetters(func(m broker.Message) bool { return m.Key == "order-2" }, 10)
+	if err != nil || n != 1 {
+		t.Fatalf("replayed %d, %v; want 1", n, err)
+	}
+	d := receive(t, msgs)
+	if d.ID != "m2" || d.Key != "order-2" || d.Attempt() != 1 {
+		t.Fatalf("got %s key %s attempt %d, want m2 order-2 attempt 1", d.ID, d.Key, d.Attempt())
+	}
+	d.Ack()
+	if n := srv.StreamLen(jetstream.OrderEventsDeadLetterStream); n != 1 {
+		t.Fatalf("dead-letter stream holds %d messages, want 1", n)
+	}
+}
+
+func TestCloseRedeliversUnsettledToOtherSubscriber(t *testing.T) {
+	srv := jetstreamtest.NewServer()
+	defer srv.Close()
+	pub := newPublisher(t, srv)
+	first := newSubscriber(t, srv, 50*time.Millisecond)
+	msgs := subscribe(t, first, 1)
+
+	publish(t, pub, "order-1", "m1")
+	receive(t, msgs)
+	first.Close()
+
+	second := newSubscriber(t, srv, 50*time.Millisecond)
+	defer second.Close()
+	d := receive(t, subscribe(t, second, 1))
+	if d.ID != "m1" || !d.Redelivered {
+		t.Fatalf("got %s redelivered %v, want m1 redelivered", d.ID, d.Redelivered)
+	}
+	d.Ack()
+}
@@ -0,0 +1,457 @@
+// Package jetstreamtest provides an in-process stand-in for NATS JetStream.
+// It implements jetstream.Client with the stream, durable consumer,
+// acknowledgement and redelivery semantics the backend depends on, so the
+// JetStream backend can be tested without a NATS server.
+package jetstreamtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/jetstream"
+
+	"github.com/nats-io/nats.go"
+)
+
+// defaultAckWait matches the NATS server default.
+const defaultAckWait = 30 * time.Second
+
+type stored struct {
+	subject string
+	header  nats.Header
+	data    []byte
+	time    time.Time
+	deleted bool
+}
+
+type stream struct {
+	cfg  jetstream.StreamConfig
+	msgs []*stored // message n has sequence n+1
+	ids  map[string]bool
+}
+
+type pending struct {
+	delivered   uint64
+	redeliverAt time.Time
+}
+
+type consumer struct {
+	stream    *stream
+	cfg       jetstream.ConsumerConfig
+	next      uint64 // next sequence never delivered so far
+	pending   map[uint64]*pending
+	delivered map[uint64]uint64 // delivery count per sequence
+}
+
+// Server holds streams and durable consumers. The zero value is not usable;
+// call NewServer.
+type Server struct {
+	mu        sync.Mutex
+	streams   map[string]*stream
+	consumers map[string]*consumer // by stream and durable name
+	clients   map[*Client]bool
+	changed   chan struct{} // closed and replaced on every change
+	closed    bool
+}
+
+func NewServer() *Server {
+	return &Server{
+		streams:   make(map[string]*stream),
+		consumers: make(map[string]*consumer),
+		clients:   make(map[*Client]bool),
+		changed:   make(chan struct{}),
+	}
+}
+
+// Connect returns a new client of the server.
+func (s *Server) Connect() *Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := &Client{srv: s, consumptions: make(map[*consumption]bool)}
+	c.closed = s.closed
+	s.clients[c] = true
+	return c
+}
+
+// Close closes every client.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	clients := make([]*Client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+	for _, c := range clients {
+		c.Close()
+	}
+	return nil
+}
+
+// StreamLen returns how many messages stream holds.
+func (s *Server) StreamLen(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	if st, ok := s.streams[name]; ok {
+		for _, m := range st.msgs {
+			if !m.deleted {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Pending returns how many messages the durable consumer delivered that
+// were not acknowledged or terminated yet.
+func (s *Server) Pending(streamName, durable string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.consumers[streamName+"/"+durable]; ok {
+		return len(c.pending)
+	}
+	return 0
+}
+
+// broadcast wakes every consumption. s.mu must be held.
+func (s *Server) broadcast() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// next picks the message c delivers next: the earliest pending message due
+// for redelivery, else the next new one matching its filter. Without one it
+// returns how long to wait for a redelivery, or 0 to wait for a change.
+// s.mu must be held.
+func (s *Server) next(c *consumer) (*Msg, time.Duration) {
+	now := time.Now()
+	var due []uint64
+	var wait time.Duration
+	for seq, p := range c.pending {
+		if d := p.redeliverAt.Sub(now); d <= 0 {
+			due = append(due, seq)
+		} else if wait == 0 || d < wait {
+			wait = d
+		}
+	}
+	if len(due) > 0 {
+		sort.Slice(due, func(i, j int) bool { return due[i] < due[j] })
+		return s.deliver(c, due[0], now), 0
+	}
+	if c.cfg.MaxAckPending > 0 && len(c.pending) >= c.cfg.MaxAckPending {
+		return nil, wait
+	}
+	for ; c.next <= uint64(len(c.stream.msgs)); c.next++ {
+		m := c.stream.msgs[c.next-1]
+		if !m.deleted && subjectMatch(c.cfg.FilterSubject, m.subject) {
+			seq := c.next
+			c.next++
+			return s.deliver(c, seq, now), 0
+		}
+	}
+	return nil, wait
+}
+
+// deliver records a delivery of seq. s.mu must be held.
+func (s *Server) deliver(c *consumer, seq uint64, now time.Time) *Msg {
+	ackWait := c.cfg.AckWait
+	if ackWait <= 0 {
+		ackWait = defaultAckWait
+	}
+	c.delivered[seq]++
+	c.pending[seq] = &pending{delivered: c.delivered[seq], redeliverAt: now.Add(ackWait)}
+	m := c.stream.msgs[seq-1]
+	return &Msg{
+		srv:          s,
+		cons:         c,
+		seq:          seq,
+		subject:      m.subject,
+		header:       copyHeader(m.header),
+		data:         m.data,
+		numDelivered: c.delivered[seq],
+	}
+}
+
+// undeliver returns a message nobody received to the consumer as if it
+// had not been delivered. s.mu must be held.
+func (s *Server) undeliver(m *Msg) {
+	if p, ok := m.cons.pending[m.seq]; ok && p.delivered == m.numDelivered {
+		m.cons.delivered[m.seq]--
+		p.redeliverAt = time.Time{}
+		s.broadcast()
+	}
+}
+
+// Client is a connection to a Server. It implements jetstream.Client.
+type Client struct {
+	srv          *Server
+	consumptions map[*consumption]bool // guarded by srv.mu
+	closed       bool                  // guarded by srv.mu
+}
+
+var _ jetstream.Client = (*Client)(nil)
+
+func (c *Client) AddStream(ctx context.Context, cfg jetstream.StreamConfig) error {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.closed {
+		return broker.ErrClosed
+	}
+	if st, ok := s.streams[cfg.Name]; ok {
+		st.cfg = cfg
+		return nil
+	}
+	s.streams[cfg.Name] = &stream{cfg: cfg, ids: make(map[string]bool)}
+	return nil
+}
+
+func (c *Client) Publish(ctx context.Context, subject, id string, header nats.Header, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return broker.ErrConfirmTimeout
+	}
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.closed {
+		return broker.ErrClosed
+	}
+
+	var target *stream
+	for _, st := range s.streams {
+		for _, filter := range st.cfg.Subjects {
+			if subjectMatch(filter, subject) {
+				target = st
+			}
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("%w: no stream takes %s", broker.ErrUnroutable, subject)
+	}
+	if id != "" {
+		if target.ids[id] {
+			return nil
+		}
+		target.ids[id] = true
+	}
+	target.msgs = append(target.msgs, &stored{subject: subject, header: copyHeader(header), data: data, time: time.Now()})
+	s.broadcast()
+	return nil
+}
+
+func (c *Client) Consume(ctx context.Context, streamName string, cfg jetstream.ConsumerConfig, batch int) (jetstream.Consumption, error) {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.closed {
+		return nil, broker.ErrClosed
+	}
+	st, ok := s.streams[streamName]
+	if !ok {
+		return nil, fmt.Errorf("jetstreamtest: stream %s not found", streamName)
+	}
+	key := streamName + "/" + cfg.Durable
+	cons, ok := s.consumers[key]
+	if !ok {
+		cons = &consumer{stream: st, next: 1, pending: make(map[uint64]*pending), delivered: make(map[uint64]uint64)}
+		s.consumers[key] = cons
+	}
+	cons.cfg = cfg
+
+	cp := &consumption{client: c, cons: cons, out: make(chan jetstream.Msg), stop: make(chan struct{})}
+	c.consumptions[cp] = true
+	go cp.run()
+	return cp, nil
+}
+
+func (c *Client) GetMsg(ctx context.Context, streamName string, seq uint64) (*jetstream.StoredMsg, error) {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[streamName]
+	if !ok {
+		return nil, fmt.Errorf("jetstreamtest: stream %s not found", streamName)
+	}
+	if seq == 0 || seq > uint64(len(st.msgs)) || st.msgs[seq-1].deleted {
+		return nil, jetstream.ErrMsgNotFound
+	}
+	m := st.msgs[seq-1]
+	return &jetstream.StoredMsg{Subject: m.subject, Sequence: seq, Header: copyHeader(m.header), Data: m.data, Time: m.time}, nil
+}
+
+// Sequences follows JetStream: an empty stream has a first sequence one
+// past its last.
+func (c *Client) Sequences(ctx context.Context, streamName string) (uint64, uint64, error) {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[streamName]
+	if !ok {
+		return 0, 0, fmt.Errorf("jetstreamtest: stream %s not found", streamName)
+	}
+	last := uint64(len(st.msgs))
+	for seq := uint64(1); seq <= last; seq++ {
+		if !st.msgs[seq-1].deleted {
+			return seq, last, nil
+		}
+	}
+	return last + 1, last, nil
+}
+
+func (c *Client) DeleteMsg(ctx context.Context, streamName string, seq uint64) error {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[streamName]
+	if !ok || seq == 0 || seq > uint64(len(st.msgs)) || st.msgs[seq-1].deleted {
+		return jetstream.ErrMsgNotFound
+	}
+	st.msgs[seq-1].deleted = true
+	return nil
+}
+
+// Close stops the client's consumptions. Messages they delivered stay
+// pending and are redelivered once their ack wait runs out.
+func (c *Client) Close() error {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.closed = true
+	for cp := range c.consumptions {
+		cp.stopLocked()
+	}
+	delete(s.clients, c)
+	return nil
+}
+
+type consumption struct {
+	client *Client
+	cons   *consumer
+	out    chan jetstream.Msg
+	stop   chan struct{}
+}
+
+func (cp *consumption) Messages() <-chan jetstream.Msg { return cp.out }
+
+func (cp *consumption) Stop() {
+	s := cp.client.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp.stopLocked()
+}
+
+// stopLocked stops the consumption. srv.mu must be held.
+func (cp *consumption) stopLocked() {
+	if _, ok := cp.client.consumptions[cp]; ok {
+		delete(cp.client.consumptions, cp)
+		close(cp.stop)
+	}
+}
+
+func (cp *consumption) run() {
+	defer close(cp.out)
+	s := cp.client.srv
+	for {
+		s.mu.Lock()
+		m, wait := s.next(cp.cons)
+		changed := s.changed
+		s.mu.Unlock()
+
+		if m != nil {
+			select {
+			case cp.out <- m:
+				continue
+			case <-cp.stop:
+				s.mu.Lock()
+				s.undeliver(m)
+				s.mu.Unlock()
+				return
+			}
+		}
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+		select {
+		case <-changed:
+		case <-timer:
+		case <-cp.stop:
+			return
+		}
+	}
+}
+
+var errNotPending = errors.New("jetstreamtest: message was already acknowledged or terminated")
+
+// Msg is a message delivered by a Server. It implements jetstream.Msg.
+type Msg struct {
+	srv          *Server
+	cons         *consumer
+	seq          uint64
+	subject      string
+	header       nats.Header
+	data         []byte
+	numDelivered uint64
+}
+
+func (m *Msg) Subject() string      { return m.subject }
+func (m *Msg) Header() nats.Header  { return m.header }
+func (m *Msg) Data() []byte         { return m.data }
+func (m *Msg) NumDelivered() uint64 { return m.numDelivered }
+
+// settle applies fn to the pending entry of m.
+func (m *Msg) settle(fn func(p *pending)) error {
+	m.srv.mu.Lock()
+	defer m.srv.mu.Unlock()
+	p, ok := m.cons.pending[m.seq]
+	if !ok {
+		return errNotPending
+	}
+	fn(p)
+	m.srv.broadcast()
+	return nil
+}
+
+func (m *Msg) Ack() error {
+	return m.settle(func(*pending) { delete(m.cons.pending, m.seq) })
+}
+
+func (m *Msg) Nak(delay time.Duration) error {
+	return m.settle(func(p *pending) { p.redeliverAt = time.Now().Add(delay) })
+}
+
+func (m *Msg) Term() error {
+	return m.Ack()
+}
+
+func copyHeader(h nats.Header) nats.Header {
+	c := make(nats.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// subjectMatch reports whether subject matches filter, where "*" matches
+// one token and a trailing ">" one or more.
+func subjectMatch(filter, subject string) bool {
+	f := strings.Split(filter, ".")
+	t := strings.Split(subject, ".")
+	for i, tok := range f {
+		if tok == ">" {
+			return len(t) > i
+		}
+		if i >= len(t) || (tok != "*" && tok != t[i]) {
+			return false
+		}
+	}
+	return len(f) == len(t)
+}
@@ -0,0 +1,22 @@
+package jetstreamtest
+
+import "testing"
+
+func TestSubjectMatch(t *testing.T) {
+	tests := []struct {
+		filter, subject string
+		want            bool
+	}{
+		{"order.created.*", "order.created.42", true},
+		{"order.created.*", "order.created", false},
+		{"order.created.*", "order.created.42.x", false},
+		{"order.>", "order.created.42", true},
+		{"order.>", "order", false},
+		{"dlq.order.>", "order.created.42", false},
+	}
+	for _, tt := range tests {
+		if got := subjectMatch(tt.filter, tt.subject); got != tt.want {
+			t.Errorf("subjectMatch(%q, %q) = %v, want %v", tt.filter, tt.subject, got, tt.want)
+		}
+	}
+}
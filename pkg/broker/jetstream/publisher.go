@@ -0,0 +1,99 @@
+package jetstream
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"E-Commerce/pkg/broker"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Headers carrying the broker.Message fields that have no JetStream
+// counterpart.
+const (
+	messageIDHeader   = "x-message-id"
+	contentTypeHeader = "Content-Type"
+	timestampHeader   = "x-timestamp"
+)
+
+type publisher struct {
+	client  Client
+	timeout time.Duration
+}
+
+// NewPublisher creates or updates streams and publishes on client. A
+// publish fails with broker.ErrConfirmTimeout if the stream has not
+// acknowledged it within timeout.
+func NewPublisher(client Client, timeout time.Duration, streams ...StreamConfig) (broker.Publisher, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, s := range streams {
+		if err := client.AddStream(ctx, s); err != nil {
+			return nil, fmt.Errorf("failed to add stream %s: %v", s.Name, err)
+		}
+	}
+	return &publisher{client: client, timeout: timeout}, nil
+}
+
+// Publish stores msg on the subject for its topic and key. The message ID
+// doubles as the JetStream deduplication ID, so publishing an event again
+// shortly after a timeout does not store it twice.
+func (p *publisher) Publish(ctx context.Context, topic string, msg broker.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.client.Publish(ctx, Subject(topic, msg.Key), msg.ID, header(msg), msg.Body)
+}
+
+func (p *publisher) Close() error {
+	return p.client.Close()
+}
+
+// header converts the headers and metadata of msg to NATS headers. Values
+// that are not strings are formatted with fmt.
+func header(msg broker.Message) nats.Header {
+	h := make(nats.Header, len(msg.Headers)+3)
+	for k, v := range msg.Headers {
+		switch v := v.(type) {
+		case string:
+			h.Set(k, v)
+		default:
+			h.Set(k, fmt.Sprint(v))
+		}
+	}
+	if msg.ID != "" {
+		h.Set(messageIDHeader, msg.ID)
+	}
+	if msg.ContentType != "" {
+		h.Set(contentTypeHeader, msg.ContentType)
+	}
+	if !msg.Timestamp.IsZero() {
+		h.Set(timestampHeader, strconv.FormatInt(msg.Timestamp.UnixMilli(), 10))
+	}
+	return h
+}
+
+// message converts a stored or delivered message back into a broker
+// message.
+func message(subject string, h nats.Header, data []byte) broker.Message {
+	msg := broker.Message{
+		ID:          h.Get(messageIDHeader),
+		ContentType: h.Get(contentTypeHeader),
+		Headers:     make(map[string]interface{}, len(h)),
+		Body:        data,
+	}
+	msg.Topic, msg.Key = splitSubject(subject)
+	if ms, err := strconv.ParseInt(h.Get(timestampHeader), 10, 64); err == nil {
+		msg.Timestamp = time.UnixMilli(ms)
+	}
+	for k := range h {
+		switch k {
+		case messageIDHeader, contentTypeHeader, timestampHeader:
+		default:
+			msg.Headers[k] = h.Get(k)
+		}
+	}
+	return msg
+}
@@ -0,0 +1,258 @@
+package jetstream
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"E-Commerce/pkg/broker"
+)
+
+// defaultBatch is how many messages are pulled ahead when the prefetch is
+// unlimited.
+const defaultBatch = 64
+
+type SubscriberConfig struct {
+	Stream           StreamConfig
+	DeadLetterStream StreamConfig
+	// Topic is consumed for every key.
+	Topic    string
+	Consumer ConsumerConfig
+	// Timeout bounds each call to JetStream outside of consuming.
+	Timeout time.Duration
+}
+
+type subscriber struct {
+	client Client
+	cfg    SubscriberConfig
+
+	mu          sync.Mutex
+	consumption Consumption
+	stopped     chan struct{}
+}
+
+// NewSubscriber creates or updates cfg's streams. The client is closed if
+// that fails.
+func NewSubscriber(client Client, cfg SubscriberConfig) (broker.Subscriber, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	for _, s := range []StreamConfig{cfg.Stream, cfg.DeadLetterStream} {
+		if err := client.AddStream(ctx, s); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	cfg.Consumer.FilterSubject = TopicFilter(cfg.Topic)
+	return &subscriber{client: client, cfg: cfg}, nil
+}
+
+// Subscribe joins the durable consumer. Every subscriber of the same
+// consumer, in this process or another, gets a share of the messages.
+func (s *subscriber) Subscribe(prefetch int) (<-chan broker.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.consumption != nil {
+		return nil, errors.New("jetstream: already subscribed")
+	}
+
+	batch := prefetch
+	if batch <= 0 {
+		batch = defaultBatch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+	consumption, err := s.client.Consume(ctx, s.cfg.Stream.Name, s.cfg.Consumer, batch)
+	if err != nil {
+		return nil, err
+	}
+	s.consumption = consumption
+	s.stopped = make(chan struct{})
+
+	// The slots keep at most prefetch messages unsettled; JetStream itself
+	// only limits them per consumer, across all subscribers.
+	var slots chan struct{}
+	if prefetch > 0 {
+		slots = make(chan struct{}, prefetch)
+	}
+	out := make(chan broker.Delivery)
+	go s.deliver(consumption.Messages(), slots, s.stopped, out)
+	return out, nil
+}
+
+func (s *subscriber) deliver(in <-chan Msg, slots chan struct{}, stopped <-chan struct{}, out chan<- broker.Delivery) {
+	defer close(out)
+	for m := range in {
+		if !acquire(slots, stopped) {
+			// Hand buffered messages back to the other subscribers
+			m.Nak(0)
+			continue
+		}
+
+		msg := message(m.Subject(), m.Header(), m.Data())
+		msg.Headers[broker.AttemptHeader] = int(m.NumDelivered())
+		out <- broker.Delivery{
+			Message:      msg,
+			Redelivered:  m.NumDelivered() > 1,
+			Acknowledger: &acker{s: s, m: m, msg: msg, slots: slots},
+		}
+	}
+}
+
+// acquire takes a prefetch slot, if there is a limit, unless the
+// subscription stopped first.
+func acquire(slots chan struct{}, stopped <-chan struct{}) bool {
+	select {
+	case <-stopped:
+		return false
+	default:
+	}
+	if slots == nil {
+		return true
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-stopped:
+		return false
+	}
+}
+
+func (s *subscriber) Unsubscribe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.consumption != nil {
+		select {
+		case <-s.stopped:
+		default:
+			close(s.stopped)
+			s.consumption.Stop()
+		}
+	}
+	return nil
+}
+
+func (s *subscriber) PeekDeadLetters(limit int) ([]broker.Message, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	var msgs []broker.Message
+	err := s.scanDeadLetters(func(seq uint64, msg broker.Message) (bool, error) {
+		msgs = append(msgs, msg)
+		return len(msgs) < limit, nil
+	})
+	return msgs, err
+}
+
+func (s *subscriber) ReplayDeadLetters(match func(broker.Message) bool, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	replayed := 0
+	err := s.scanDeadLetters(func(seq uint64, msg broker.Message) (bool, error) {
+		if !match(msg) {
+			return true, nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+		defer cancel()
+		// A new deduplication ID, as the original one may still be in the
+		// stream's duplicate window
+		msg = msg.ResetRetries()
+		if err := s.client.Publish(ctx, Subject(msg.Topic, msg.Key), "", header(msg), msg.Body); err != nil {
+			return false, err
+		}
+		if err := s.client.DeleteMsg(ctx, s.cfg.DeadLetterStream.Name, seq); err != nil {
+			return false, err
+		}
+		replayed++
+		return replayed < limit, nil
+	})
+	return replayed, err
+}
+
+// scanDeadLetters calls fn for each dead-lettered message in order until
+// it returns false or an error.
+func (s *subscriber) scanDeadLetters(fn func(seq uint64, msg broker.Message) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+	first, last, err := s.client.Sequences(ctx, s.cfg.DeadLetterStream.Name)
+	if err != nil {
+		return err
+	}
+	if first == 0 {
+		first = 1
+	}
+
+	for seq := first; seq <= last; seq++ {
+		stored, err := s.client.GetMsg(ctx, s.cfg.DeadLetterStream.Name, seq)
+		if errors.Is(err, ErrMsgNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		subject := strings.TrimPrefix(stored.Subject, DeadLetterPrefix+".")
+		more, err := fn(seq, message(subject, stored.Header, stored.Data))
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the subscription and closes the client. Unsettled messages
+// are redelivered once their ack wait runs out.
+func (s *subscriber) Close() error {
+	s.Unsubscribe()
+	return s.client.Close()
+}
+
+// acker settles one JetStream message.
+type acker struct {
+	s     *subscriber
+	m     Msg
+	msg   broker.Message
+	slots chan struct{}
+	once  sync.Once
+}
+
+// release frees the prefetch slot of the message.
+func (a *acker) release() {
+	if a.slots != nil {
+		a.once.Do(func() { <-a.slots })
+	}
+}
+
+func (a *acker) Ack() error {
+	defer a.release()
+	return a.m.Ack()
+}
+
+func (a *acker) Nack(requeue bool) error {
+	if !requeue {
+		return a.DeadLetter(nil)
+	}
+	defer a.release()
+	return a.m.Nak(0)
+}
+
+// Retry redelivers the message after delay. JetStream redelivers the
+// stored message, so headers are not kept; the attempt count comes from
+// the delivery count instead.
+func (a *acker) Retry(delay time.Duration, headers map[string]interface{}) error {
+	defer a.release()
+	return a.m.Nak(delay)
+}
+
+func (a *acker) DeadLetter(headers map[string]interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.s.cfg.Timeout)
+	defer cancel()
+	msg := a.msg.WithHeaders(headers)
+	err := a.s.client.Publish(ctx, DeadLetterPrefix+"."+a.m.Subject(), "", header(msg), msg.Body)
+	if err != nil {
+		return err
+	}
+	defer a.release()
+	return a.m.Term()
+}
@@ -0,0 +1,64 @@
+package jetstream
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	OrderEventsStream           = "ORDER_EVENTS"
+	OrderEventsDeadLetterStream = "ORDER_EVENTS_DLQ"
+
+	// DeadLetterPrefix is prepended to the subject of a dead-lettered
+	// message, which keeps its original subject after the prefix.
+	DeadLetterPrefix = "dlq"
+)
+
+// OrderEvents holds every order event, e.g. order.created.<order ID>, for
+// a week, so a consumer that was down for a while can catch up.
+var OrderEvents = StreamConfig{
+	Name:     OrderEventsStream,
+	Subjects: []string{"order.>"},
+	MaxAge:   7 * 24 * time.Hour,
+}
+
+// OrderEventsDeadLetters holds order events that could not be processed
+// until they are replayed.
+var OrderEventsDeadLetters = StreamConfig{
+	Name:     OrderEventsDeadLetterStream,
+	Subjects: []string{DeadLetterPrefix + ".order.>"},
+}
+
+// Subject is the subject a message with key is published on for topic.
+// Characters that are not allowed in a subject token are replaced by "_",
+// and an empty key becomes "_".
+func Subject(topic, key string) string {
+	return topic + "." + subjectToken(key)
+}
+
+// TopicFilter matches the subjects of every key of topic.
+func TopicFilter(topic string) string {
+	return topic + ".*"
+}
+
+// splitSubject reverses Subject as far as possible.
+func splitSubject(subject string) (topic, key string) {
+	i := strings.LastIndexByte(subject, '.')
+	if i < 0 {
+		return subject, ""
+	}
+	return subject[:i], subject[i+1:]
+}
+
+func subjectToken(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, key)
+}
@@ -0,0 +1,182 @@
+// Package memory is an in-process implementation of the broker API for
+// tests and local development. Queues are bound to topics, messages
+// published on a topic are copied to every bound queue, and deliveries
+// follow the same ack, nack, retry and dead-letter rules as the RabbitMQ
+// backend. Nothing is persisted.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"E-Commerce/pkg/broker"
+)
+
+var errAlreadySettled = errors.New("memory: delivery was already settled")
+
+type entry struct {
+	msg         broker.Message
+	redelivered bool
+}
+
+type queue struct {
+	name  string
+	ready []entry
+	dead  []broker.Message
+}
+
+// Broker holds queues and the topics bound to them. The zero value is not
+// usable; call NewBroker.
+type Broker struct {
+	mu       sync.Mutex
+	cond     *sync.Cond // broadcast whenever a queue or subscriber changes
+	queues   map[string]*queue
+	bindings map[string][]*queue
+	subs     map[*subscriber]bool
+	closed   bool
+}
+
+func NewBroker() *Broker {
+	b := &Broker{
+		queues:   make(map[string]*queue),
+		bindings: make(map[string][]*queue),
+		subs:     make(map[*subscriber]bool),
+	}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// queue returns the named queue, creating it if needed. b.mu must be held.
+func (b *Broker) queue(name string) *queue {
+	q, ok := b.queues[name]
+	if !ok {
+		q = &queue{name: name}
+		b.queues[name] = q
+	}
+	return q
+}
+
+// Bind creates queue if needed and routes messages published on each of
+// topics to it. Topics match exactly; there are no wildcards.
+func (b *Broker) Bind(queue string, topics ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q := b.queue(queue)
+	for _, topic := range topics {
+		bound := false
+		for _, other := range b.bindings[topic] {
+			bound = bound || other == q
+		}
+		if !bound {
+			b.bindings[topic] = append(b.bindings[topic], q)
+		}
+	}
+}
+
+// Publisher returns a publisher for the broker. Closing it does not affect
+// the broker or other clients.
+func (b *Broker) Publisher() broker.Publisher {
+	return &publisher{b: b}
+}
+
+// Subscriber returns a subscriber for queue, creating the queue if needed.
+func (b *Broker) Subscriber(queue string) broker.Subscriber {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := &subscriber{b: b, q: b.queue(queue), unsettled: make(map[*acker]bool)}
+	b.subs[s] = true
+	return s
+}
+
+// Len returns how many messages are ready for delivery on queue.
+func (b *Broker) Len(queue string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if q, ok := b.queues[queue]; ok {
+		return len(q.ready)
+	}
+	return 0
+}
+
+// Unsettled returns how many messages have been delivered from queue but
+// not yet settled.
+func (b *Broker) Unsettled(queue string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for s := range b.subs {
+		if s.q.name == queue {
+			n += len(s.unsettled)
+		}
+	}
+	return n
+}
+
+// DeadLetters returns how many messages were dead-lettered from queue.
+func (b *Broker) DeadLetters(queue string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if q, ok := b.queues[queue]; ok {
+		return len(q.dead)
+	}
+	return 0
+}
+
+// Close closes every subscriber and makes further publishing fail.
+// Pending retries are dropped.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	subs := make([]*subscriber, 0, len(b.subs))
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+	b.mu.Unlock()
+	for _, s := range subs {
+		s.Close()
+	}
+	return nil
+}
+
+// publish copies msg to every queue bound to topic. b.mu must be held.
+func (b *Broker) publish(topic string, msg broker.Message) error {
+	if b.closed {
+		return broker.ErrClosed
+	}
+	queues := b.bindings[topic]
+	if len(queues) == 0 {
+		return fmt.Errorf("%w: no queue is bound to %q", broker.ErrUnroutable, topic)
+	}
+	msg.Topic = topic
+	for _, q := range queues {
+		q.ready = append(q.ready, entry{msg: msg.WithHeaders(nil)})
+	}
+	b.cond.Broadcast()
+	return nil
+}
+
+type publisher struct {
+	b      *Broker
+	closed bool // guarded by b.mu
+}
+
+func (p *publisher) Publish(ctx context.Context, topic string, msg broker.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.b.mu.Lock()
+	defer p.b.mu.Unlock()
+	if p.closed {
+		return broker.ErrClosed
+	}
+	return p.b.publish(topic, msg)
+}
+
+func (p *publisher) Close() error {
+	p.b.mu.Lock()
+	defer p.b.mu.Unlock()
+	p.closed = true
+	return nil
+}
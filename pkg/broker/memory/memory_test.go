@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"E-Commerce/pkg/broker"
+)
+
+func publish(t *testing.T, b *Broker, topic string, ids ...string) {
+	t.Helper()
+	pub := b.Publisher()
+	for _, id := range ids {
+		if err := pub.Publish(context.Background(), topic, broker.Message{ID: id}); err != nil {
+			t.Fatalf("failed to publish %s: %v", id, err)
+		}
+	}
+}
+
+func receive(t *testing.T, msgs <-chan broker.Delivery) broker.Delivery {
+	t.Helper()
+	select {
+	case d := <-msgs:
+		return d
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message was delivered")
+	}
+	return broker.Delivery{}
+}
+
+func expectNone(t *testing.T, msgs <-chan broker.Delivery) {
+	t.Helper()
+	select {
+	case d := <-msgs:
+		t.Fatalf("unexpected delivery of %s", d.ID)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func subscribe(t *testing.T, b *Broker, queue string, prefetch int) (broker.Subscriber, <-chan broker.Delivery) {
+	t.Helper()
+	sub := b.Subscriber(queue)
+	msgs, err := sub.Subscribe(prefetch)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	return sub, msgs
+}
+
+func TestPublishRoutesToEveryBoundQueue(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	b.Bind("a", "order.created")
+	b.Bind("b", "order.created", "order.cancelled")
+
+	publish(t, b, "order.created", "m1")
+	publish(t, b, "order.cancelled", "m2")
+	if n := b.Len("a"); n != 1 {
+		t.Errorf("queue a has %d messages, want 1", n)
+	}
+	if n := b.Len("b"); n != 2 {
+		t.Errorf("queue b has %d messages, want 2", n)
+	}
+
+	err := b.Publisher().Publish(context.Background(), "order.shipped", broker.Message{})
+	if !errors.Is(err, broker.ErrUnroutable) {
+		t.Errorf("got %v, want %v", err, broker.ErrUnroutable)
+	}
+}
+
+func TestPrefetchLimitsUnsettledDeliveries(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	b.Bind("q", "t")
+	publish(t, b, "t", "m1", "m2", "m3")
+
+	_, msgs := subscribe(t, b, "q", 2)
+	first := receive(t, msgs)
+	receive(t, msgs)
+	expectNone(t, msgs)
+
+	if err := first.Ack(); err != nil {
+		t.Fatalf("ack failed: %v", err)
+	}
+	if d := receive(t, msgs); d.ID != "m3" {
+		t.Errorf("got %s, want m3", d.ID)
+	}
+	if err := first.Ack(); err == nil {
+		t.Error("a delivery was acknowledged twice")
+	}
+}
+
+func TestNackRequeuesOrDeadLetters(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	b.Bind("q", "t")
+	publish(t, b, "t", "m1")
+
+	sub, msgs := subscribe(t, b, "q", 0)
+	receive(t, msgs).Nack(true)
+	d := receive(t, msgs)
+	if !d.Redelivered {
+		t.Error("requeued message is not marked as redelivered")
+	}
+	d.Nack(false)
+
+	letters, _ := sub.PeekDeadLetters(10)
+	if len(letters) != 1 || letters[0].ID != "m1" {
+		t.Errorf("got dead letters %+v, want m1", letters)
+	}
+}
+
+func TestRetryRedeliversAfterDelay(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	b.Bind("q", "t")
+	publish(t, b, "t", "m1")
+
+	_, msgs := subscribe(t, b, "q", 0)
+	start := time.Now()
+	err := receive(t, msgs).Retry(30*time.Millisecond, map[string]interface{}{broker.AttemptHeader: int32(2)})
+	if err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	d := receive(t, msgs)
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("message came back after %s, before its delay", elapsed)
+	}
+	if d.Attempt() != 2 || d.Topic != "t" {
+		t.Errorf("got attempt %d on %q, want 2 on %q", d.Attempt(), d.Topic, "t")
+	}
+}
+
+func TestReplayDeadLettersResetsRetries(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	b.Bind("q", "t")
+	publish(t, b, "t", "m1", "m2")
+
+	sub, msgs := subscribe(t, b, "q", 0)
+	for i := 0; i < 2; i++ {
+		receive(t, msgs).DeadLetter(map[string]interface{}{
+			broker.AttemptHeader:          int32(5),
+			broker.DeadLetterReasonHeader: "max_attempts",
+		})
+	}
+
+	n, err := sub.ReplayDeadLetters(func(m broker.Message) bool { return m.ID == "m2" }, 10)
+	if err != nil || n != 1 {
+		t.Fatalf("ReplayDeadLetters = %d, %v; want 1, nil", n, err)
+	}
+	d := receive(t, msgs)
+	if d.ID != "m2" || d.Attempt() != 1 || d.Header(broker.DeadLetterReasonHeader) != "" {
+		t.Errorf("replayed %s on attempt %d with reason %q", d.ID, d.Attempt(), d.Header(broker.DeadLetterReasonHeader))
+	}
+	if n := b.DeadLetters("q"); n != 1 {
+		t.Errorf("got %d dead letters after replay, want 1", n)
+	}
+}
+
+func TestCloseRequeuesUnsettledDeliveries(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	b.Bind("q", "t")
+	publish(t, b, "t", "m1")
+
+	sub, msgs := subscribe(t, b, "q", 0)
+	d := receive(t, msgs)
+	sub.Close()
+	if _, ok := <-msgs; ok {
+		t.Error("deliveries channel is still open after Close")
+	}
+	if err := d.Ack(); !errors.Is(err, broker.ErrClosed) {
+		t.Errorf("got %v acknowledging after Close, want %v", err, broker.ErrClosed)
+	}
+
+	_, msgs = subscribe(t, b, "q", 0)
+	if d := receive(t, msgs); d.ID != "m1" || !d.Redelivered {
+		t.Errorf("got %s (redelivered %t), want m1 redelivered", d.ID, d.Redelivered)
+	}
+}
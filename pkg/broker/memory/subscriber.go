@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"E-Commerce/pkg/broker"
+)
+
+type subscriber struct {
+	b *Broker
+	q *queue
+
+	// Guarded by b.mu
+	prefetch   int
+	subscribed bool
+	stopped    bool
+	closed     bool
+	stop       chan struct{}
+	unsettled  map[*acker]bool
+}
+
+func (s *subscriber) Subscribe(prefetch int) (<-chan broker.Delivery, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if s.closed || s.b.closed {
+		return nil, broker.ErrClosed
+	}
+	if s.subscribed {
+		return nil, errors.New("memory: already subscribed")
+	}
+	s.subscribed = true
+	s.prefetch = prefetch
+	s.stop = make(chan struct{})
+	out := make(chan broker.Delivery)
+	go s.deliver(out)
+	return out, nil
+}
+
+// deliver hands out ready messages until the subscription stops, keeping
+// at most prefetch of them unsettled.
+func (s *subscriber) deliver(out chan<- broker.Delivery) {
+	defer close(out)
+	b := s.b
+	for {
+		b.mu.Lock()
+		for !s.stopped && (len(s.q.ready) == 0 || s.full()) {
+			b.cond.Wait()
+		}
+		if s.stopped {
+			b.mu.Unlock()
+			return
+		}
+		e := s.q.ready[0]
+		s.q.ready = s.q.ready[1:]
+		a := &acker{s: s, msg: e.msg}
+		s.unsettled[a] = true
+		stop := s.stop
+		b.mu.Unlock()
+
+		select {
+		case out <- broker.Delivery{Message: e.msg, Redelivered: e.redelivered, Acknowledger: a}:
+		case <-stop:
+			// Nobody took the message; put it back where it was
+			b.mu.Lock()
+			delete(s.unsettled, a)
+			s.q.ready = append([]entry{e}, s.q.ready...)
+			b.cond.Broadcast()
+			b.mu.Unlock()
+			return
+		}
+	}
+}
+
+// full reports whether the prefetch limit is reached. b.mu must be held.
+func (s *subscriber) full() bool {
+	return s.prefetch > 0 && len(s.unsettled) >= s.prefetch
+}
+
+func (s *subscriber) Unsubscribe() error {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.unsubscribe()
+	return nil
+}
+
+// unsubscribe stops the delivery goroutine. b.mu must be held.
+func (s *subscriber) unsubscribe() {
+	if s.subscribed && !s.stopped {
+		s.stopped = true
+		close(s.stop)
+		s.b.cond.Broadcast()
+	}
+}
+
+func (s *subscriber) PeekDeadLetters(limit int) ([]broker.Message, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if limit > len(s.q.dead) {
+		limit = len(s.q.dead)
+	}
+	msgs := make([]broker.Message, limit)
+	for i := range msgs {
+		msgs[i] = s.q.dead[i].WithHeaders(nil)
+	}
+	return msgs, nil
+}
+
+func (s *subscriber) ReplayDeadLetters(match func(broker.Message) bool, limit int) (int, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if s.b.closed {
+		return 0, broker.ErrClosed
+	}
+
+	replayed := 0
+	kept := s.q.dead[:0]
+	for _, msg := range s.q.dead {
+		if replayed < limit && match(msg) {
+			s.q.ready = append(s.q.ready, entry{msg: msg.ResetRetries()})
+			replayed++
+			continue
+		}
+		kept = append(kept, msg)
+	}
+	s.q.dead = kept
+	s.b.cond.Broadcast()
+	return replayed, nil
+}
+
+// Close stops the subscription and puts unsettled messages back at the
+// front of the queue, marked as redelivered.
+func (s *subscriber) Close() error {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.unsubscribe()
+	var requeued []entry
+	for a := range s.unsettled {
+		requeued = append(requeued, entry{msg: a.msg, redelivered: true})
+	}
+	s.q.ready = append(requeued, s.q.ready...)
+	s.unsettled = make(map[*acker]bool)
+	delete(s.b.subs, s)
+	s.b.cond.Broadcast()
+	return nil
+}
+
+// acker settles one delivery.
+type acker struct {
+	s   *subscriber
+	msg broker.Message
+}
+
+// settle marks the delivery as settled and runs then with b.mu held.
+func (a *acker) settle(then func(q *queue)) error {
+	b := a.s.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !a.s.unsettled[a] {
+		if a.s.closed {
+			// The message went back to the queue when the subscriber closed
+			return broker.ErrClosed
+		}
+		return errAlreadySettled
+	}
+	delete(a.s.unsettled, a)
+	then(a.s.q)
+	b.cond.Broadcast()
+	return nil
+}
+
+func (a *acker) Ack() error {
+	return a.settle(func(*queue) {})
+}
+
+func (a *acker) Nack(requeue bool) error {
+	return a.settle(func(q *queue) {
+		if requeue {
+			q.ready = append([]entry{{msg: a.msg, redelivered: true}}, q.ready...)
+		} else {
+			q.dead = append(q.dead, a.msg)
+		}
+	})
+}
+
+func (a *acker) Retry(delay time.Duration, headers map[string]interface{}) error {
+	msg := a.msg.WithHeaders(headers)
+	b := a.s.b
+	return a.settle(func(q *queue) {
+		time.AfterFunc(delay, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if !b.closed {
+				q.ready = append(q.ready, entry{msg: msg})
+				b.cond.Broadcast()
+			}
+		})
+	})
+}
+
+func (a *acker) DeadLetter(headers map[string]interface{}) error {
+	msg := a.msg.WithHeaders(headers)
+	return a.settle(func(q *queue) {
+		q.dead = append(q.dead, msg)
+	})
+}
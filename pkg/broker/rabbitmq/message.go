@@ -0,0 +1,77 @@
+package rabbitmq
+
+import (
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+// topicHeader keeps the original routing key of a message that was moved
+// to a retry or dead-letter queue, where it is delivered under another key.
+const topicHeader = "x-topic"
+
+// publishing converts msg into a persistent AMQP publishing.
+func publishing(msg broker.Message) amqp.Publishing {
+	p := messaging.NewPublishing(msg.Body)
+	if msg.ContentType != "" {
+		p.ContentType = msg.ContentType
+	}
+	p.MessageId = msg.ID
+	p.Timestamp = msg.Timestamp
+	p.Headers = amqp.Table(msg.Headers)
+	return p
+}
+
+// message converts a delivery back into a broker message.
+func message(d amqp.Delivery) broker.Message {
+	topic := d.RoutingKey
+	if t, ok := d.Headers[topicHeader].(string); ok {
+		topic = t
+	}
+	return broker.Message{
+		ID:          d.MessageId,
+		Topic:       topic,
+		Headers:     map[string]interface{}(d.Headers),
+		ContentType: d.ContentType,
+		Timestamp:   d.Timestamp,
+		Body:        d.Body,
+	}
+}
+
+// republish copies d into a new publishing with extra headers merged over
+// the original ones, for moving a message to a retry or dead-letter queue.
+func republish(d amqp.Delivery, headers map[string]interface{}) amqp.Publishing {
+	merged := make(amqp.Table, len(d.Headers)+len(headers)+1)
+	for k, v := range d.Headers {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		merged[k] = v
+	}
+	if _, ok := merged[topicHeader]; !ok {
+		merged[topicHeader] = d.RoutingKey
+	}
+	return amqp.Publishing{
+		Headers:         merged,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		CorrelationId:   d.CorrelationId,
+		MessageId:       d.MessageId,
+		Timestamp:       d.Timestamp,
+		Type:            d.Type,
+		AppId:           d.AppId,
+		Body:            d.Body,
+	}
+}
+
+// resetRetries copies a dead-lettered message into a new publishing without
+// its retry and dead-letter headers, giving it a fresh attempt budget.
+func resetRetries(d amqp.Delivery) amqp.Publishing {
+	msg := republish(d, nil)
+	for _, h := range broker.RetryHeaders {
+		delete(msg.Headers, h)
+	}
+	return msg
+}
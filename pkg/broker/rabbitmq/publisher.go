@@ -0,0 +1,333 @@
+// Package rabbitmq implements the broker API on RabbitMQ, on top of the
+// topology and connection wrappers in pkg/messaging.
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+var errChannelClosed = errors.New("channel closed before the message was confirmed")
+
+type PublisherConfig struct {
+	// PoolSize bounds how many confirm-mode channels publish concurrently.
+	PoolSize int
+	// ConfirmTimeout is how long a publish may take end to end, including
+	// waiting for a reconnect, before it is reported as failed.
+	ConfirmTimeout time.Duration
+	// MinReconnectDelay and MaxReconnectDelay bound the exponential delay
+	// between reconnect attempts.
+	MinReconnectDelay time.Duration
+	MaxReconnectDelay time.Duration
+}
+
+func DefaultPublisherConfig() PublisherConfig {
+	return PublisherConfig{
+		PoolSize:          8,
+		ConfirmTimeout:    5 * time.Second,
+		MinReconnectDelay: 500 * time.Millisecond,
+		MaxReconnectDelay: 30 * time.Second,
+	}
+}
+
+// Dialer opens a new broker connection. It is called again whenever the
+// current connection drops.
+type Dialer func() (messaging.Connection, error)
+
+// publisher publishes mandatory messages on a pool of confirm-mode channels
+// and only reports success once the broker confirmed the message. It
+// redials with backoff when the connection drops and redeclares its
+// topology on every new connection.
+type publisher struct {
+	dial     Dialer
+	topology messaging.Topology
+	exchange string
+	cfg      PublisherConfig
+
+	mu    sync.Mutex
+	conn  messaging.Connection // nil while reconnecting
+	gen   uint64               // bumped on every new connection
+	ready chan struct{}        // closed once conn is usable
+
+	idle  chan *confirmChannel
+	slots chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewPublisher connects through dial and declares topology up front, so
+// messages published before any consumer starts are still queued. Topics
+// are published as routing keys on exchange. Lost connections are redialed
+// in the background.
+func NewPublisher(dial Dialer, topology messaging.Topology, exchange string, cfg PublisherConfig) (broker.Publisher, error) {
+	p := &publisher{
+		dial:     dial,
+		topology: topology,
+		exchange: exchange,
+		cfg:      cfg,
+		ready:    make(chan struct{}),
+		idle:     make(chan *confirmChannel, cfg.PoolSize),
+		slots:    make(chan struct{}, cfg.PoolSize),
+		done:     make(chan struct{}),
+	}
+	closed, err := p.connect()
+	if err != nil {
+		return nil, err
+	}
+	go p.watch(closed)
+	return p, nil
+}
+
+// connect dials the broker, declares the topology and makes the new
+// connection current. It returns a channel that is notified when the
+// connection closes.
+func (p *publisher) connect() (chan *amqp.Error, error) {
+	conn, err := p.dial()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %v", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %v", err)
+	}
+	err = p.topology.Declare(ch)
+	ch.Close()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare topology: %v", err)
+	}
+
+	closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	p.mu.Lock()
+	p.conn = conn
+	p.gen++
+	close(p.ready)
+	p.mu.Unlock()
+	return closed, nil
+}
+
+// watch waits for the current connection to close and reconnects, until
+// the publisher is closed.
+func (p *publisher) watch(closed chan *amqp.Error) {
+	for {
+		select {
+		case <-p.done:
+			return
+		case err := <-closed:
+			select {
+			case <-p.done:
+				return
+			default:
+			}
+			log.Printf("RabbitMQ connection lost, reconnecting: %v", err)
+		}
+
+		p.mu.Lock()
+		p.conn = nil
+		p.ready = make(chan struct{})
+		p.mu.Unlock()
+
+		delay := p.cfg.MinReconnectDelay
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-time.After(delay):
+			}
+			var err error
+			if closed, err = p.connect(); err == nil {
+				log.Printf("Reconnected to RabbitMQ")
+				break
+			}
+			log.Printf("Failed to reconnect to RabbitMQ, retrying in %s: %v", delay, err)
+			if delay *= 2; delay > p.cfg.MaxReconnectDelay {
+				delay = p.cfg.MaxReconnectDelay
+			}
+		}
+	}
+}
+
+// Publish sends msg and waits for the broker's confirmation. Channel or
+// connection failures are retried until ConfirmTimeout runs out or ctx is
+// done, so a message may reach the broker more than once.
+func (p *publisher) Publish(ctx context.Context, topic string, msg broker.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.ConfirmTimeout)
+	defer cancel()
+
+	for {
+		cc, err := p.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		err = cc.publish(ctx, p.exchange, topic, publishing(msg))
+		p.release(cc, err == nil || errors.Is(err, broker.ErrRejected) || errors.Is(err, broker.ErrUnroutable))
+		if errors.Is(err, errChannelClosed) {
+			log.Printf("Publish interrupted, retrying: %v", err)
+			continue
+		}
+		return err
+	}
+}
+
+// acquire takes a pool slot and returns an open channel on the current
+// connection, waiting for a reconnect if necessary.
+func (p *publisher) acquire(ctx context.Context) (*confirmChannel, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, broker.ErrConfirmTimeout
+	case <-p.done:
+		return nil, broker.ErrClosed
+	}
+
+	for {
+		p.mu.Lock()
+		conn, gen, ready := p.conn, p.gen, p.ready
+		p.mu.Unlock()
+
+		select {
+		case cc := <-p.idle:
+			if cc.gen == gen {
+				return cc, nil
+			}
+			cc.ch.Close()
+			continue
+		default:
+		}
+
+		if conn != nil {
+			cc, err := newConfirmChannel(conn, gen)
+			if err == nil {
+				return cc, nil
+			}
+			// The connection is going away; wait for the watcher to notice
+			log.Printf("Failed to open confirm channel: %v", err)
+			ready = nil
+		}
+
+		var retry <-chan time.Time
+		if ready == nil {
+			retry = time.After(p.cfg.MinReconnectDelay)
+		}
+		select {
+		case <-ready:
+		case <-retry:
+		case <-ctx.Done():
+			<-p.slots
+			return nil, broker.ErrConfirmTimeout
+		case <-p.done:
+			<-p.slots
+			return nil, broker.ErrClosed
+		}
+	}
+}
+
+// release returns cc to the pool if it can be reused and frees its slot.
+func (p *publisher) release(cc *confirmChannel, reusable bool) {
+	if reusable {
+		select {
+		case p.idle <- cc:
+		default:
+			cc.ch.Close()
+		}
+	} else {
+		cc.ch.Close()
+	}
+	<-p.slots
+}
+
+func (p *publisher) Close() error {
+	var err error
+	p.once.Do(func() {
+		close(p.done)
+		for len(p.idle) > 0 {
+			(<-p.idle).ch.Close()
+		}
+		p.mu.Lock()
+		if p.conn != nil {
+			err = p.conn.Close()
+		}
+		p.mu.Unlock()
+	})
+	return err
+}
+
+// confirmChannel is a channel in confirm mode with its notification
+// channels. It is used for one publish at a time, so each confirmation
+// belongs to the message published just before.
+type confirmChannel struct {
+	ch       messaging.Channel
+	gen      uint64
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+}
+
+func newConfirmChannel(conn messaging.Connection, gen uint64) (*confirmChannel, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, err
+	}
+	return &confirmChannel{
+		ch:       ch,
+		gen:      gen,
+		confirms: ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+		returns:  ch.NotifyReturn(make(chan amqp.Return, 1)),
+	}, nil
+}
+
+func (cc *confirmChannel) publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	if err := cc.ch.Publish(exchange, key, true, false, msg); err != nil {
+		return fmt.Errorf("%w: %v", errChannelClosed, err)
+	}
+
+	// The broker sends basic.return before the confirmation of an
+	// unroutable message
+	var returned *amqp.Return
+	returns := cc.returns
+	for {
+		select {
+		case r, ok := <-returns:
+			if ok {
+				returned = &r
+			} else {
+				returns = nil
+			}
+		case c, ok := <-cc.confirms:
+			if !ok {
+				return errChannelClosed
+			}
+			if !c.Ack {
+				return broker.ErrRejected
+			}
+			if returned == nil && returns != nil {
+				select {
+				case r, ok := <-returns:
+					if ok {
+						returned = &r
+					}
+				default:
+				}
+			}
+			if returned != nil {
+				return fmt.Errorf("%w: %s", broker.ErrUnroutable, returned.ReplyText)
+			}
+			return nil
+		case <-ctx.Done():
+			return broker.ErrConfirmTimeout
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/messaging"
+	"E-Commerce/pkg/messaging/amqptest"
+
+	"github.com/streadway/amqp"
+)
+
+var testPublisherConfig = PublisherConfig{
+	PoolSize:          2,
+	ConfirmTimeout:    200 * time.Millisecond,
+	MinReconnectDelay: 5 * time.Millisecond,
+	MaxReconnectDelay: 20 * time.Millisecond,
+}
+
+func newTestPublisher(t *testing.T) (*amqptest.Broker, broker.Publisher, *int) {
+	t.Helper()
+	amqpBroker := amqptest.NewBroker()
+	dials := 0
+	dial := func() (messaging.Connection, error) {
+		dials++
+		return amqpBroker.Dial()
+	}
+	p, err := NewPublisher(dial, messaging.OrderEvents, messaging.OrderEventsExchange, testPublisherConfig)
+	if err != nil {
+		t.Fatalf("failed to create publisher: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return amqpBroker, p, &dials
+}
+
+// publishOrderCreated publishes a stand-in order.created event.
+func publishOrderCreated(p broker.Publisher, orderID string) error {
+	msg := broker.Message{ID: "event-" + orderID, Body: []byte(`{"order_id":"` + orderID + `"}`)}
+	return p.Publish(context.Background(), messaging.OrderCreatedRoutingKey, msg)
+}
+
+func TestPublishWaitsForConfirm(t *testing.T) {
+	amqpBroker, p, _ := newTestPublisher(t)
+
+	if err := publishOrderCreated(p, "order-1"); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if n := amqpBroker.QueueLen(messaging.OrderCreatedQueue); n != 1 {
+		t.Errorf("got %d queued events, want 1", n)
+	}
+
+	amqpBroker.NackConfirms(true)
+	if err := publishOrderCreated(p, "order-2"); !errors.Is(err, broker.ErrRejected) {
+		t.Errorf("got %v, want %v", err, broker.ErrRejected)
+	}
+	amqpBroker.NackConfirms(false)
+
+	amqpBroker.HoldConfirms(true)
+	start := time.Now()
+	err := publishOrderCreated(p, "order-3")
+	if !errors.Is(err, broker.ErrConfirmTimeout) {
+		t.Errorf("got %v, want %v", err, broker.ErrConfirmTimeout)
+	}
+	if elapsed := time.Since(start); elapsed < testPublisherConfig.ConfirmTimeout {
+		t.Errorf("publish failed after %s, before the confirm timeout", elapsed)
+	}
+}
+
+func TestPublishRecoversFromConnectionLoss(t *testing.T) {
+	amqpBroker, p, dials := newTestPublisher(t)
+
+	if err := publishOrderCreated(p, "order-1"); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+
+	amqpBroker.DropConnection()
+	if err := publishOrderCreated(p, "order-2"); err != nil {
+		t.Fatalf("publish after connection loss failed: %v", err)
+	}
+	if *dials < 2 {
+		t.Errorf("publisher did not redial")
+	}
+	if n := amqpBroker.QueueLen(messaging.OrderCreatedQueue); n != 2 {
+		t.Errorf("got %d queued events, want 2", n)
+	}
+}
+
+func TestPublishUnroutableMessageFails(t *testing.T) {
+	amqpBroker := amqptest.NewBroker()
+	topology := messaging.Topology{
+		Exchanges: []messaging.Exchange{{Name: "unbound", Kind: amqp.ExchangeTopic, Durable: true}},
+	}
+	p, err := NewPublisher(amqpBroker.Dial, topology, "unbound", testPublisherConfig)
+	if err != nil {
+		t.Fatalf("failed to create publisher: %v", err)
+	}
+	defer p.Close()
+
+	err = p.Publish(context.Background(), "order.created", broker.Message{Body: []byte("{}")})
+	if !errors.Is(err, broker.ErrUnroutable) {
+		t.Errorf("got %v, want %v", err, broker.ErrUnroutable)
+	}
+}
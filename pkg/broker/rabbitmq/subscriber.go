@@ -0,0 +1,220 @@
+package rabbitmq
+
+import (
+	"fmt"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+type SubscriberConfig struct {
+	// Topology is declared before anything else, e.g. messaging.OrderEvents.
+	// It must contain Queue and the dead-letter exchange and queue.
+	Topology messaging.Topology
+	Queue    string
+	// Dead-lettered messages are published to DeadLetterExchange with
+	// DeadLetterRoutingKey, which must route them to DeadLetterQueue.
+	DeadLetterExchange   string
+	DeadLetterRoutingKey string
+	DeadLetterQueue      string
+	// RetryDelays are the delays Retry accepts; one delay queue is declared
+	// for each.
+	RetryDelays []time.Duration
+	ConsumerTag string
+}
+
+type subscriber struct {
+	conn    messaging.Connection
+	channel messaging.Channel
+	cfg     SubscriberConfig
+}
+
+// NewSubscriber declares cfg's topology and one delay queue per retry delay
+// on conn. The connection is closed if the declaration fails.
+func NewSubscriber(conn messaging.Connection, cfg SubscriberConfig) (broker.Subscriber, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := cfg.Topology.Declare(ch); err == nil {
+		err = messaging.RetryTopology(cfg.Queue, cfg.RetryDelays).Declare(ch)
+	}
+	if err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	return &subscriber{conn: conn, channel: ch, cfg: cfg}, nil
+}
+
+func (s *subscriber) Subscribe(prefetch int) (<-chan broker.Delivery, error) {
+	if err := s.channel.Qos(prefetch, 0, false); err != nil {
+		return nil, err
+	}
+	in, err := s.channel.Consume(
+		s.cfg.Queue,       // queue
+		s.cfg.ConsumerTag, // consumer
+		false,             // auto-ack
+		false,             // exclusive
+		false,             // no-local
+		false,             // no-wait
+		nil,               // args
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan broker.Delivery)
+	go func() {
+		defer close(out)
+		for d := range in {
+			out <- broker.Delivery{
+				Message:      message(d),
+				Redelivered:  d.Redelivered,
+				Acknowledger: &acker{s: s, d: d},
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (s *subscriber) Unsubscribe() error {
+	return s.channel.Cancel(s.cfg.ConsumerTag, false)
+}
+
+func (s *subscriber) PeekDeadLetters(limit int) ([]broker.Message, error) {
+	// Fetched messages stay unacknowledged, so closing the channel puts
+	// them back on the queue.
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	var msgs []broker.Message
+	for len(msgs) < limit {
+		d, ok, err := ch.Get(s.cfg.DeadLetterQueue, false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		msgs = append(msgs, message(d))
+	}
+	return msgs, nil
+}
+
+func (s *subscriber) ReplayDeadLetters(match func(broker.Message) bool, limit int) (int, error) {
+	// Messages that do not match are left unacknowledged until the channel
+	// is closed, so every message is looked at once.
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	replayed := 0
+	for replayed < limit {
+		d, ok, err := ch.Get(s.cfg.DeadLetterQueue, false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+		if !match(message(d)) {
+			continue
+		}
+
+		err = ch.Publish(
+			"",          // default exchange
+			s.cfg.Queue, // routing key
+			false,       // mandatory
+			false,       // immediate
+			resetRetries(d),
+		)
+		if err != nil {
+			return replayed, err
+		}
+		if err := d.Ack(false); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (s *subscriber) Close() error {
+	if err := s.channel.Close(); err != nil {
+		return err
+	}
+	return s.conn.Close()
+}
+
+// acker settles one AMQP delivery.
+type acker struct {
+	s *subscriber
+	d amqp.Delivery
+}
+
+func (a *acker) Ack() error {
+	return a.d.Ack(false)
+}
+
+// Nack without requeue dead-letters the message explicitly, as the queue
+// itself has no dead-letter exchange.
+func (a *acker) Nack(requeue bool) error {
+	if !requeue {
+		return a.DeadLetter(nil)
+	}
+	return a.d.Nack(false, true)
+}
+
+func (a *acker) Retry(delay time.Duration, headers map[string]interface{}) error {
+	if !a.s.hasRetryDelay(delay) {
+		// Publishing to a delay queue that does not exist would drop the
+		// message silently
+		return fmt.Errorf("no retry queue for a delay of %s", delay)
+	}
+	err := a.s.channel.Publish(
+		"", // default exchange
+		messaging.RetryQueueName(a.s.cfg.Queue, delay), // routing key
+		false, // mandatory
+		false, // immediate
+		republish(a.d, headers),
+	)
+	if err != nil {
+		return err
+	}
+	return a.d.Ack(false)
+}
+
+func (a *acker) DeadLetter(headers map[string]interface{}) error {
+	err := a.s.channel.Publish(
+		a.s.cfg.DeadLetterExchange,   // exchange
+		a.s.cfg.DeadLetterRoutingKey, // routing key
+		false,                        // mandatory
+		false,                        // immediate
+		republish(a.d, headers),
+	)
+	if err != nil {
+		return err
+	}
+	return a.d.Ack(false)
+}
+
+func (s *subscriber) hasRetryDelay(delay time.Duration) bool {
+	for _, d := range s.cfg.RetryDelays {
+		if d == delay {
+			return true
+		}
+	}
+	return false
+}
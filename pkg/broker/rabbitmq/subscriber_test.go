@@ -0,0 +1,104 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/messaging"
+	"E-Commerce/pkg/messaging/amqptest"
+)
+
+const testRetryDelay = 20 * time.Millisecond
+
+func newTestSubscriber(t *testing.T) (*amqptest.Broker, broker.Subscriber, <-chan broker.Delivery) {
+	t.Helper()
+	amqpBroker := amqptest.NewBroker()
+	t.Cleanup(func() { amqpBroker.Close() })
+
+	sub, err := NewSubscriber(amqpBroker, SubscriberConfig{
+		Topology:             messaging.OrderEvents,
+		Queue:                messaging.OrderCreatedQueue,
+		DeadLetterExchange:   messaging.DeadLetterExchange,
+		DeadLetterRoutingKey: messaging.OrderCreatedRoutingKey,
+		DeadLetterQueue:      messaging.OrderCreatedDeadLetterQueue,
+		RetryDelays:          []time.Duration{testRetryDelay},
+		ConsumerTag:          "test",
+	})
+	if err != nil {
+		t.Fatalf("failed to create subscriber: %v", err)
+	}
+	msgs, err := sub.Subscribe(0)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	p, err := NewPublisher(amqpBroker.Dial, messaging.OrderEvents, messaging.OrderEventsExchange, testPublisherConfig)
+	if err != nil {
+		t.Fatalf("failed to create publisher: %v", err)
+	}
+	defer p.Close()
+	msg := broker.Message{ID: "event-1", Body: []byte(`{"order_id":"order-1"}`)}
+	if err := p.Publish(context.Background(), messaging.OrderCreatedRoutingKey, msg); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+	return amqpBroker, sub, msgs
+}
+
+func receive(t *testing.T, msgs <-chan broker.Delivery) broker.Delivery {
+	t.Helper()
+	select {
+	case d := <-msgs:
+		return d
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message was delivered")
+	}
+	return broker.Delivery{}
+}
+
+func TestRetryGoesThroughDelayQueue(t *testing.T) {
+	_, _, msgs := newTestSubscriber(t)
+
+	d := receive(t, msgs)
+	if d.ID != "event-1" || d.Topic != messaging.OrderCreatedRoutingKey {
+		t.Fatalf("got %s on %q", d.ID, d.Topic)
+	}
+	if err := d.Retry(time.Minute, nil); err == nil {
+		t.Fatal("retrying with a delay that has no delay queue succeeded")
+	}
+	if err := d.Retry(testRetryDelay, map[string]interface{}{broker.AttemptHeader: int32(2)}); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+
+	d = receive(t, msgs)
+	if d.ID != "event-1" || d.Attempt() != 2 {
+		t.Errorf("got %s on attempt %d, want event-1 on attempt 2", d.ID, d.Attempt())
+	}
+	if d.Topic != messaging.OrderCreatedRoutingKey {
+		t.Errorf("retried message lost its topic, got %q", d.Topic)
+	}
+	d.Ack()
+}
+
+func TestDeadLetterPeekAndReplay(t *testing.T) {
+	amqpBroker, sub, msgs := newTestSubscriber(t)
+
+	receive(t, msgs).Nack(false)
+
+	letters, err := sub.PeekDeadLetters(10)
+	if err != nil || len(letters) != 1 || letters[0].ID != "event-1" {
+		t.Fatalf("PeekDeadLetters = %+v, %v; want event-1", letters, err)
+	}
+	if n := amqpBroker.QueueLen(messaging.OrderCreatedDeadLetterQueue); n != 1 {
+		t.Fatalf("got %d dead-lettered messages after peeking, want 1", n)
+	}
+
+	n, err := sub.ReplayDeadLetters(func(broker.Message) bool { return true }, 10)
+	if err != nil || n != 1 {
+		t.Fatalf("ReplayDeadLetters = %d, %v; want 1, nil", n, err)
+	}
+	if d := receive(t, msgs); d.ID != "event-1" || d.Attempt() != 1 {
+		t.Errorf("got %s on attempt %d, want event-1 on attempt 1", d.ID, d.Attempt())
+	}
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"E-Commerce/pkg/schema"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	// SpecVersion is the CloudEvents version of Envelope.
+	SpecVersion = "1.0"
+	// ContentType marks a message body holding an Envelope, per the
+	// CloudEvents structured JSON mode.
+	ContentType = "application/cloudevents+json"
+
+	OrderCreatedType = "com.ecommerce.order.created.v1"
+)
+
+// Envelope is a CloudEvents 1.0 event with its data as JSON. TraceParent is
+// the CloudEvents distributed tracing extension and carries the W3C trace
+// context of the request that caused the event.
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	TraceParent     string          `json:"traceparent,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewEnvelope wraps data as an event of eventType. The trace context of
+// ctx, if any, is carried along.
+func NewEnvelope(ctx context.Context, eventType, source, id, subject string, t time.Time, data interface{}) (*Envelope, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		SpecVersion:     SpecVersion,
+		ID:              id,
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            t.UTC(),
+		DataContentType: "application/json",
+		TraceParent:     TraceParentFromContext(ctx),
+		Data:            body,
+	}, nil
+}
+
+// Encode checks that e is a complete event whose data matches the schema
+// of its type and returns it as JSON.
+func Encode(e *Envelope) ([]byte, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(e)
+}
+
+// Decode parses an encoded Envelope and validates its data against the
+// schema of its type. A type without a schema, e.g. an unknown version,
+// fails with ErrUnsupportedVersion and anything else wrong with the event
+// with ErrInvalidEvent, while a body that is not an envelope at all fails
+// with neither.
+func Decode(body []byte) (*Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, fmt.Errorf("failed to decode event envelope: %v", err)
+	}
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (e *Envelope) validate() error {
+	switch {
+	case e.SpecVersion != SpecVersion:
+		return fmt.Errorf("%w: CloudEvents spec version %q", ErrUnsupportedVersion, e.SpecVersion)
+	case e.ID == "" || e.Source == "" || e.Type == "":
+		return fmt.Errorf("%w: event is missing its id, source or type", ErrInvalidEvent)
+	case e.DataContentType != "application/json":
+		return fmt.Errorf("%w: %s data has content type %q", ErrInvalidEvent, e.Type, e.DataContentType)
+	}
+	err := schema.Default.Validate(e.Type, e.Data)
+	switch {
+	case errors.Is(err, schema.ErrUnknownType):
+		return fmt.Errorf("%w: %v", ErrUnsupportedVersion, err)
+	case err != nil:
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return nil
+}
+
+// ContextWithTraceParent returns ctx with the remote span context of a
+// W3C traceparent value, e.g. one kept with an event that is published
+// later. Malformed values are ignored.
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
+
+// TraceParentFromContext formats the span context of ctx as a W3C
+// traceparent value, or returns "" if ctx has none.
+func TraceParentFromContext(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func testOrderCreated() OrderCreated {
+	return OrderCreated{
+		Version:   OrderCreatedVersion,
+		EventID:   "event-1",
+		OrderID:   "order-1",
+		UserID:    "user-1",
+		Items:     []LineItem{{ProductID: "product-1", Quantity: 2, UnitPrice: 10}},
+		Total:     20,
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	event := testOrderCreated()
+	ctx := ContextWithTraceParent(context.Background(), testTraceParent)
+	e, err := NewEnvelope(ctx, OrderCreatedType, "/producer-service", event.EventID, event.OrderID, event.CreatedAt, event)
+	if err != nil {
+		t.Fatalf("failed to wrap event: %v", err)
+	}
+	body, err := Encode(e)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	var attrs map[string]interface{}
+	json.Unmarshal(body, &attrs)
+	for _, name := range []string{"specversion", "id", "source", "type", "time", "datacontenttype", "traceparent", "data"} {
+		if _, ok := attrs[name]; !ok {
+			t.Errorf("encoded event has no %s attribute", name)
+		}
+	}
+
+	got, err := Decode(body)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if got.Type != OrderCreatedType || got.ID != "event-1" || got.TraceParent != testTraceParent {
+		t.Errorf("got envelope %+v", got)
+	}
+	var data OrderCreated
+	if err := json.Unmarshal(got.Data, &data); err != nil || data.OrderID != "order-1" {
+		t.Errorf("got data %+v, %v", data, err)
+	}
+}
+
+func TestDecodeRejectsUnknownVersion(t *testing.T) {
+	e, _ := NewEnvelope(context.Background(), "com.ecommerce.order.created.v2", "/producer-service", "event-1", "", time.Now(), testOrderCreated())
+	body, _ := json.Marshal(e)
+	if _, err := Decode(body); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("got %v, want %v", err, ErrUnsupportedVersion)
+	}
+	if _, err := Encode(e); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("got %v, want %v", err, ErrUnsupportedVersion)
+	}
+}
+
+func TestDecodeRejectsInvalidData(t *testing.T) {
+	event := testOrderCreated()
+	event.Items[0].Quantity = 0
+	e, _ := NewEnvelope(context.Background(), OrderCreatedType, "/producer-service", "event-1", "", time.Now(), event)
+	body, _ := json.Marshal(e)
+	if _, err := Decode(body); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidEvent)
+	}
+
+	e.ID = ""
+	if _, err := Encode(e); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("got %v, want %v for an event without id", err, ErrInvalidEvent)
+	}
+}
+
+func TestContextWithTraceParentDropsMalformedValues(t *testing.T) {
+	ctx := ContextWithTraceParent(context.Background(), "not-a-traceparent")
+	if tp := TraceParentFromContext(ctx); tp != "" {
+		t.Errorf("got %q, want the malformed value dropped", tp)
+	}
+}
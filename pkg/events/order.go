@@ -0,0 +1,63 @@
+// Package events defines the payloads of the domain events exchanged
+// between services. Payloads carry an explicit version so consumers can
+// reject messages they do not understand instead of misreading them.
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OrderCreatedVersion is the current version of the OrderCreated payload.
+const OrderCreatedVersion = 1
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported event version")
+	ErrInvalidEvent       = errors.New("invalid event")
+)
+
+type LineItem struct {
+	ProductID string  `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+}
+
+// OrderCreated is published once an order has been stored. EventID stays
+// the same when the event is published again, so consumers can use it to
+// spot duplicates. ReservationID is set when the order's stock was
+// reserved in inventory-service, and so already taken off; it is empty for
+// orders placed before reservations.
+type OrderCreated struct {
+	Version       int        `json:"version"`
+	EventID       string     `json:"event_id,omitempty"`
+	OrderID       string     `json:"order_id"`
+	UserID        string     `json:"user_id"`
+	Items         []LineItem `json:"items"`
+	Total         float64    `json:"total"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReservationID string     `json:"reservation_id,omitempty"`
+}
+
+// Validate checks that e is a payload of a supported version with at least
+// one line item and positive quantities.
+func (e *OrderCreated) Validate() error {
+	if e.Version != OrderCreatedVersion {
+		return fmt.Errorf("%w: order.created v%d", ErrUnsupportedVersion, e.Version)
+	}
+	if e.OrderID == "" {
+		return fmt.Errorf("%w: missing order ID", ErrInvalidEvent)
+	}
+	if len(e.Items) == 0 {
+		return fmt.Errorf("%w: order %s has no items", ErrInvalidEvent, e.OrderID)
+	}
+	for _, item := range e.Items {
+		if item.ProductID == "" {
+			return fmt.Errorf("%w: order %s has an item without product ID", ErrInvalidEvent, e.OrderID)
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: order %s has quantity %d for product %s", ErrInvalidEvent, e.OrderID, item.Quantity, item.ProductID)
+		}
+	}
+	return nil
+}
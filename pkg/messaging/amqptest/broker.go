@@ -0,0 +1,375 @@
+// Package amqptest provides an in-process stand-in for a RabbitMQ broker.
+// It implements messaging.Connection and messaging.Channel with the
+// exchange, queue and acknowledgement semantics the services depend on, so
+// event flows can be tested without a running broker.
+package amqptest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"E-Commerce/pkg/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+var ErrClosed = errors.New("amqptest: channel or connection is closed")
+
+type binding struct {
+	queue string
+	key   string
+}
+
+type exchange struct {
+	kind     string
+	durable  bool
+	bindings []binding
+}
+
+type message struct {
+	delivery amqp.Delivery
+	expires  time.Time // zero if the message never expires
+}
+
+type queue struct {
+	name    string
+	durable bool
+	args    amqp.Table
+	ready   []message
+	cond    *sync.Cond
+}
+
+// Broker is an in-memory broker. The zero value is not usable; call
+// NewBroker.
+//
+// The broker itself implements messaging.Connection through a default
+// connection, which is enough for most tests. Tests of reconnect logic use
+// Dial for separate connections and DropConnection to sever them.
+type Broker struct {
+	mu        sync.Mutex
+	exchanges map[string]*exchange
+	queues    map[string]*queue
+	conns     map[*Conn]bool
+	def       *Conn
+	closed    bool
+
+	holdConfirms bool
+	nackConfirms bool
+}
+
+func NewBroker() *Broker {
+	b := &Broker{
+		exchanges: map[string]*exchange{"": {kind: amqp.ExchangeDirect, durable: true}},
+		queues:    make(map[string]*queue),
+		conns:     make(map[*Conn]bool),
+	}
+	b.def = b.newConn()
+	return b
+}
+
+// Dial opens a new connection to the broker.
+func (b *Broker) Dial() (messaging.Connection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	return b.newConn(), nil
+}
+
+// newConn registers a new connection. b.mu must be held.
+func (b *Broker) newConn() *Conn {
+	c := &Conn{broker: b, channels: make(map[*Channel]bool)}
+	b.conns[c] = true
+	return c
+}
+
+// Channel opens a new channel on the default connection.
+func (b *Broker) Channel() (messaging.Channel, error) {
+	b.mu.Lock()
+	c := b.def
+	b.mu.Unlock()
+	return c.Channel()
+}
+
+// NotifyClose registers a listener for the default connection being
+// dropped by DropConnection.
+func (b *Broker) NotifyClose(ch chan *amqp.Error) chan *amqp.Error {
+	b.mu.Lock()
+	c := b.def
+	b.mu.Unlock()
+	return c.NotifyClose(ch)
+}
+
+// Close closes every connection, returning unacknowledged messages to their
+// queues as a real broker would when a connection drops.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	for _, c := range b.connections() {
+		c.shutdown(nil)
+	}
+	return nil
+}
+
+// DropConnection simulates a network failure: every open connection is
+// closed with an error sent to its close listeners. Exchanges, queues and
+// ready messages survive, and the default connection is replaced so the
+// broker keeps working as a messaging.Connection.
+func (b *Broker) DropConnection() {
+	b.mu.Lock()
+	b.def = b.newConn()
+	b.mu.Unlock()
+	for _, c := range b.connections() {
+		if c != b.def {
+			c.shutdown(&amqp.Error{Code: amqp.ConnectionForced, Reason: "CONNECTION_FORCED - amqptest", Server: true})
+		}
+	}
+}
+
+func (b *Broker) connections() []*Conn {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	conns := make([]*Conn, 0, len(b.conns))
+	for c := range b.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+// HoldConfirms makes the broker stop (or resume) sending publisher
+// confirmations, as if they were lost on the way.
+func (b *Broker) HoldConfirms(hold bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.holdConfirms = hold
+}
+
+// NackConfirms makes the broker negatively confirm (or resume acking)
+// published messages.
+func (b *Broker) NackConfirms(nack bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nackConfirms = nack
+}
+
+// QueueLen reports how many messages are ready for delivery on a queue.
+func (b *Broker) QueueLen(name string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if q, ok := b.queues[name]; ok {
+		return len(q.ready)
+	}
+	return 0
+}
+
+// Unacked reports how many messages have been delivered but not yet
+// acknowledged across all channels.
+func (b *Broker) Unacked() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for c := range b.conns {
+		for ch := range c.channels {
+			n += len(ch.unacked)
+		}
+	}
+	return n
+}
+
+// route delivers msg to every queue bound to exchangeName under key and
+// reports whether any queue received it. b.mu must be held.
+func (b *Broker) route(exchangeName, key string, msg amqp.Publishing) (bool, error) {
+	ex, ok := b.exchanges[exchangeName]
+	if !ok {
+		return false, &amqp.Error{Code: amqp.NotFound, Reason: fmt.Sprintf("NOT_FOUND - no exchange '%s'", exchangeName)}
+	}
+
+	var targets []string
+	if exchangeName == "" {
+		if _, ok := b.queues[key]; ok {
+			targets = append(targets, key)
+		}
+	} else {
+		seen := make(map[string]bool)
+		for _, bd := range ex.bindings {
+			if seen[bd.queue] || !matches(ex.kind, bd.key, key) {
+				continue
+			}
+			seen[bd.queue] = true
+			targets = append(targets, bd.queue)
+		}
+	}
+
+	for _, name := range targets {
+		b.enqueue(b.queues[name], exchangeName, key, msg)
+	}
+	return len(targets) > 0, nil
+}
+
+// enqueue appends a message to q, starting its expiry clock if the queue or
+// the message has a TTL. b.mu must be held.
+func (b *Broker) enqueue(q *queue, exchangeName, key string, msg amqp.Publishing) {
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+	m := message{delivery: amqp.Delivery{
+		Headers:         msg.Headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    msg.DeliveryMode,
+		Priority:        msg.Priority,
+		CorrelationId:   msg.CorrelationId,
+		ReplyTo:         msg.ReplyTo,
+		Expiration:      msg.Expiration,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		UserId:          msg.UserId,
+		AppId:           msg.AppId,
+		Exchange:        exchangeName,
+		RoutingKey:      key,
+		Body:            msg.Body,
+	}}
+	if ttl, ok := messageTTL(q, msg); ok {
+		m.expires = time.Now().Add(ttl)
+		time.AfterFunc(ttl, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.expire(q)
+		})
+	}
+	q.ready = append(q.ready, m)
+	q.cond.Broadcast()
+}
+
+// expire dead-letters the expired messages at the head of q. Like RabbitMQ,
+// it only looks at the head, so a message never overtakes an earlier one.
+// b.mu must be held.
+func (b *Broker) expire(q *queue) {
+	now := time.Now()
+	for len(q.ready) > 0 {
+		m := q.ready[0]
+		if m.expires.IsZero() || now.Before(m.expires) {
+			return
+		}
+		q.ready = q.ready[1:]
+		b.deadLetter(q, m.delivery)
+	}
+}
+
+// deadLetter republishes d to the dead-letter exchange configured on q
+// through the x-dead-letter-exchange argument. Messages of a queue without
+// one are dropped. b.mu must be held.
+func (b *Broker) deadLetter(q *queue, d amqp.Delivery) {
+	dlx, ok := q.args["x-dead-letter-exchange"].(string)
+	if !ok {
+		return
+	}
+	key := d.RoutingKey
+	if k, ok := q.args["x-dead-letter-routing-key"].(string); ok {
+		key = k
+	}
+	msg := amqp.Publishing{
+		Headers:         d.Headers,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    d.DeliveryMode,
+		Priority:        d.Priority,
+		CorrelationId:   d.CorrelationId,
+		ReplyTo:         d.ReplyTo,
+		MessageId:       d.MessageId,
+		Timestamp:       d.Timestamp,
+		Type:            d.Type,
+		UserId:          d.UserId,
+		AppId:           d.AppId,
+		Body:            d.Body,
+	}
+	b.route(dlx, key, msg)
+}
+
+// requeue puts a delivery back at the head of its queue. b.mu must be held.
+func (b *Broker) requeue(queueName string, d amqp.Delivery) {
+	q, ok := b.queues[queueName]
+	if !ok {
+		return
+	}
+	d.Redelivered = true
+	d.Acknowledger = nil
+	q.ready = append([]message{{delivery: d}}, q.ready...)
+	q.cond.Broadcast()
+}
+
+// reject discards a delivery, dead-lettering it if its queue is configured
+// to. b.mu must be held.
+func (b *Broker) reject(queueName string, d amqp.Delivery) {
+	if q, ok := b.queues[queueName]; ok {
+		d.Acknowledger = nil
+		b.deadLetter(q, d)
+	}
+}
+
+// messageTTL returns the shorter of the per-queue x-message-ttl argument and
+// the per-message expiration, both in milliseconds.
+func messageTTL(q *queue, msg amqp.Publishing) (time.Duration, bool) {
+	ttl, ok := millis(q.args["x-message-ttl"])
+	if msg.Expiration != "" {
+		if ms, err := strconv.ParseInt(msg.Expiration, 10, 64); err == nil {
+			if d := time.Duration(ms) * time.Millisecond; !ok || d < ttl {
+				ttl, ok = d, true
+			}
+		}
+	}
+	return ttl, ok
+}
+
+func millis(v interface{}) (time.Duration, bool) {
+	switch n := v.(type) {
+	case int:
+		return time.Duration(n) * time.Millisecond, true
+	case int32:
+		return time.Duration(n) * time.Millisecond, true
+	case int64:
+		return time.Duration(n) * time.Millisecond, true
+	}
+	return 0, false
+}
+
+// matches reports whether a routing key satisfies a binding key for the
+// given exchange kind.
+func matches(kind, bindingKey, routingKey string) bool {
+	switch kind {
+	case amqp.ExchangeFanout:
+		return true
+	case amqp.ExchangeTopic:
+		return topicMatch(strings.Split(bindingKey, "."), strings.Split(routingKey, "."))
+	default:
+		return bindingKey == routingKey
+	}
+}
+
+// topicMatch implements AMQP topic matching where "*" stands for exactly
+// one word and "#" for zero or more words.
+func topicMatch(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if topicMatch(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && topicMatch(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && topicMatch(pattern[1:], words[1:])
+	}
+}
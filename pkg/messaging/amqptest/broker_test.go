@@ -0,0 +1,24 @@
+package amqptest
+
+import "testing"
+
+func TestTopicMatch(t *testing.T) {
+	tests := []struct {
+		binding, key string
+		want         bool
+	}{
+		{"order.created", "order.created", true},
+		{"order.created", "order.cancelled", false},
+		{"order.*", "order.created", true},
+		{"order.*", "order.created.v1", false},
+		{"order.#", "order.created.v1", true},
+		{"order.#", "order", true},
+		{"#", "anything.at.all", true},
+		{"*.created", "order.created", true},
+	}
+	for _, tt := range tests {
+		if got := matches("topic", tt.binding, tt.key); got != tt.want {
+			t.Errorf("matches(%q, %q) = %v, want %v", tt.binding, tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package amqptest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+type unacked struct {
+	queue    string
+	delivery amqp.Delivery
+}
+
+type consumer struct {
+	tag  string
+	stop chan struct{}
+	once sync.Once
+}
+
+func (c *consumer) cancel() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+// Channel is an in-process implementation of messaging.Channel. All of its
+// state is guarded by the owning broker's mutex.
+type Channel struct {
+	broker    *Broker
+	conn      *Conn
+	closed    bool
+	nextTag   uint64
+	unacked   map[uint64]*unacked
+	consumers []*consumer
+	prefetch  int
+
+	confirming   bool
+	publishSeq   uint64
+	confirms     []chan amqp.Confirmation
+	returns      []chan amqp.Return
+	closeNotifys []chan *amqp.Error
+}
+
+func (ch *Channel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		return ErrClosed
+	}
+
+	if ex, ok := b.exchanges[name]; ok {
+		if ex.kind != kind || ex.durable != durable {
+			return preconditionFailed("exchange", name)
+		}
+		return nil
+	}
+	b.exchanges[name] = &exchange{kind: kind, durable: durable}
+	return nil
+}
+
+func (ch *Channel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		return amqp.Queue{}, ErrClosed
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("amq.gen-%d", len(b.queues)+1)
+	}
+	if q, ok := b.queues[name]; ok {
+		if q.durable != durable || !sameArgs(q.args, args) {
+			return amqp.Queue{}, preconditionFailed("queue", name)
+		}
+		return amqp.Queue{Name: name, Messages: len(q.ready)}, nil
+	}
+	b.queues[name] = &queue{
+		name:    name,
+		durable: durable,
+		args:    args,
+		cond:    sync.NewCond(&b.mu),
+	}
+	return amqp.Queue{Name: name}, nil
+}
+
+func (ch *Channel) QueueBind(name, key, exchangeName string, noWait bool, args amqp.Table) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		return ErrClosed
+	}
+
+	ex, ok := b.exchanges[exchangeName]
+	if !ok {
+		return notFound("exchange", exchangeName)
+	}
+	if _, ok := b.queues[name]; !ok {
+		return notFound("queue", name)
+	}
+	for _, bd := range ex.bindings {
+		if bd.queue == name && bd.key == key {
+			return nil
+		}
+	}
+	ex.bindings = append(ex.bindings, binding{queue: name, key: key})
+	return nil
+}
+
+// Publish routes msg. As with the amqp library, returns and confirmations
+// are sent to the listeners before Publish returns, so listener channels
+// must be buffered or drained by another goroutine.
+func (ch *Channel) Publish(exchangeName, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	b := ch.broker
+	b.mu.Lock()
+	if ch.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+
+	routed, err := b.route(exchangeName, key, msg)
+	if err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	var returns []chan amqp.Return
+	if mandatory && !routed {
+		returns = ch.returns
+	}
+	var confirms []chan amqp.Confirmation
+	var confirmation amqp.Confirmation
+	if ch.confirming {
+		ch.publishSeq++
+		confirmation = amqp.Confirmation{DeliveryTag: ch.publishSeq, Ack: !b.nackConfirms}
+		if !b.holdConfirms {
+			confirms = ch.confirms
+		}
+	}
+	b.mu.Unlock()
+
+	for _, c := range returns {
+		c <- amqp.Return{
+			ReplyCode:   amqp.NoRoute,
+			ReplyText:   "NO_ROUTE",
+			Exchange:    exchangeName,
+			RoutingKey:  key,
+			ContentType: msg.ContentType,
+			MessageId:   msg.MessageId,
+			Headers:     msg.Headers,
+			Body:        msg.Body,
+		}
+	}
+	for _, c := range confirms {
+		c <- confirmation
+	}
+	return nil
+}
+
+// Confirm puts the channel into confirm mode.
+func (ch *Channel) Confirm(noWait bool) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		return ErrClosed
+	}
+	ch.confirming = true
+	return nil
+}
+
+func (ch *Channel) NotifyPublish(c chan amqp.Confirmation) chan amqp.Confirmation {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		close(c)
+	} else {
+		ch.confirms = append(ch.confirms, c)
+	}
+	return c
+}
+
+func (ch *Channel) NotifyReturn(c chan amqp.Return) chan amqp.Return {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		close(c)
+	} else {
+		ch.returns = append(ch.returns, c)
+	}
+	return c
+}
+
+func (ch *Channel) NotifyClose(c chan *amqp.Error) chan *amqp.Error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		close(c)
+	} else {
+		ch.closeNotifys = append(ch.closeNotifys, c)
+	}
+	return c
+}
+
+func (ch *Channel) Consume(queueName, consumerTag string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		return nil, ErrClosed
+	}
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil, notFound("queue", queueName)
+	}
+	if consumerTag == "" {
+		consumerTag = fmt.Sprintf("ctag-%d", len(ch.consumers)+1)
+	}
+	c := &consumer{tag: consumerTag, stop: make(chan struct{})}
+	ch.consumers = append(ch.consumers, c)
+
+	out := make(chan amqp.Delivery)
+	go ch.deliver(q, c, autoAck, out)
+	return out, nil
+}
+
+// deliver hands messages from q to out until the consumer is cancelled.
+func (ch *Channel) deliver(q *queue, c *consumer, autoAck bool, out chan<- amqp.Delivery) {
+	b := ch.broker
+	defer close(out)
+
+	// Wake the waiting loop below when the consumer is cancelled.
+	go func() {
+		<-c.stop
+		b.mu.Lock()
+		q.cond.Broadcast()
+		b.mu.Unlock()
+	}()
+
+	for {
+		b.mu.Lock()
+		for (len(q.ready) == 0 || ch.full(autoAck)) && !stopped(c) {
+			q.cond.Wait()
+		}
+		if stopped(c) {
+			b.mu.Unlock()
+			return
+		}
+		d := ch.take(q, autoAck)
+		d.ConsumerTag = c.tag
+		b.mu.Unlock()
+
+		select {
+		case out <- d:
+		case <-c.stop:
+			b.mu.Lock()
+			if _, ok := ch.unacked[d.DeliveryTag]; ok {
+				delete(ch.unacked, d.DeliveryTag)
+				b.requeue(q.name, d)
+			}
+			b.mu.Unlock()
+			return
+		}
+	}
+}
+
+// take pops the head of q and, unless autoAck is set, tracks it until it is
+// settled. b.mu must be held and q must not be empty.
+func (ch *Channel) take(q *queue, autoAck bool) amqp.Delivery {
+	d := q.ready[0].delivery
+	q.ready = q.ready[1:]
+	ch.nextTag++
+	d.DeliveryTag = ch.nextTag
+	if !autoAck {
+		d.Acknowledger = ch
+		ch.unacked[d.DeliveryTag] = &unacked{queue: q.name, delivery: d}
+	}
+	return d
+}
+
+// Get fetches a single message from a queue, reporting false if it is
+// empty.
+func (ch *Channel) Get(queueName string, autoAck bool) (amqp.Delivery, bool, error) {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		return amqp.Delivery{}, false, ErrClosed
+	}
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return amqp.Delivery{}, false, notFound("queue", queueName)
+	}
+	if len(q.ready) == 0 {
+		return amqp.Delivery{}, false, nil
+	}
+	d := ch.take(q, autoAck)
+	d.MessageCount = uint32(len(q.ready))
+	return d, true, nil
+}
+
+// Qos limits how many unacknowledged deliveries the channel's consumers
+// hold at once. Unlike RabbitMQ, the limit always applies to the channel as
+// a whole; global is ignored.
+func (ch *Channel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch.closed {
+		return ErrClosed
+	}
+	ch.prefetch = prefetchCount
+	for _, q := range b.queues {
+		q.cond.Broadcast()
+	}
+	return nil
+}
+
+// Cancel stops the consumer with the given tag. Its deliveries channel is
+// closed once any delivery in flight has been handed over or requeued.
+func (ch *Channel) Cancel(consumerTag string, noWait bool) error {
+	b := ch.broker
+	b.mu.Lock()
+	if ch.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	var found *consumer
+	for i, c := range ch.consumers {
+		if c.tag == consumerTag {
+			found = c
+			ch.consumers = append(ch.consumers[:i], ch.consumers[i+1:]...)
+			break
+		}
+	}
+	b.mu.Unlock()
+
+	if found == nil {
+		return &amqp.Error{Code: amqp.NotFound, Reason: fmt.Sprintf("NOT_FOUND - no consumer '%s'", consumerTag)}
+	}
+	found.cancel()
+	return nil
+}
+
+// full reports whether the prefetch limit stops further deliveries. b.mu
+// must be held.
+func (ch *Channel) full(autoAck bool) bool {
+	return !autoAck && ch.prefetch > 0 && len(ch.unacked) >= ch.prefetch
+}
+
+// wake lets consumers waiting on the prefetch limit re-check it. b.mu must
+// be held.
+func (ch *Channel) wake(queueName string) {
+	if q, ok := ch.broker.queues[queueName]; ok {
+		q.cond.Broadcast()
+	}
+}
+
+func stopped(c *consumer) bool {
+	select {
+	case <-c.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (ch *Channel) Ack(tag uint64, multiple bool) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tags, err := ch.settle(tag, multiple)
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		ch.wake(ch.unacked[t].queue)
+		delete(ch.unacked, t)
+	}
+	return nil
+}
+
+func (ch *Channel) Nack(tag uint64, multiple bool, requeue bool) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tags, err := ch.settle(tag, multiple)
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		u := ch.unacked[t]
+		delete(ch.unacked, t)
+		ch.wake(u.queue)
+		if requeue {
+			b.requeue(u.queue, u.delivery)
+		} else {
+			b.reject(u.queue, u.delivery)
+		}
+	}
+	return nil
+}
+
+func (ch *Channel) Reject(tag uint64, requeue bool) error {
+	return ch.Nack(tag, false, requeue)
+}
+
+// settle resolves the delivery tags covered by an ack or nack. b.mu must be
+// held.
+func (ch *Channel) settle(tag uint64, multiple bool) ([]uint64, error) {
+	if ch.closed {
+		return nil, ErrClosed
+	}
+	if !multiple {
+		if _, ok := ch.unacked[tag]; !ok {
+			return nil, &amqp.Error{Code: amqp.PreconditionFailed, Reason: fmt.Sprintf("PRECONDITION_FAILED - unknown delivery tag %d", tag)}
+		}
+		return []uint64{tag}, nil
+	}
+	var tags []uint64
+	for t := range ch.unacked {
+		if t <= tag {
+			tags = append(tags, t)
+		}
+	}
+	return tags, nil
+}
+
+// Close cancels the channel's consumers and returns its unacknowledged
+// messages to their queues.
+func (ch *Channel) Close() error {
+	ch.shutdown(nil)
+	return nil
+}
+
+// shutdown closes the channel, sending cause to its close listeners unless
+// it is nil, as for a channel closed by its owner.
+func (ch *Channel) shutdown(cause *amqp.Error) {
+	b := ch.broker
+	b.mu.Lock()
+	if ch.closed {
+		b.mu.Unlock()
+		return
+	}
+	ch.closed = true
+	consumers := ch.consumers
+	for tag, u := range ch.unacked {
+		delete(ch.unacked, tag)
+		b.requeue(u.queue, u.delivery)
+	}
+	delete(ch.conn.channels, ch)
+	confirms, returns, closeNotifys := ch.confirms, ch.returns, ch.closeNotifys
+	ch.confirms, ch.returns, ch.closeNotifys = nil, nil, nil
+	b.mu.Unlock()
+
+	for _, c := range consumers {
+		c.cancel()
+	}
+	for _, c := range closeNotifys {
+		if cause != nil {
+			c <- cause
+		}
+		close(c)
+	}
+	for _, c := range confirms {
+		close(c)
+	}
+	for _, c := range returns {
+		close(c)
+	}
+}
+
+func sameArgs(a, b amqp.Table) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func preconditionFailed(kind, name string) error {
+	return &amqp.Error{
+		Code:   amqp.PreconditionFailed,
+		Reason: fmt.Sprintf("PRECONDITION_FAILED - inequivalent arguments for %s '%s'", kind, name),
+	}
+}
+
+func notFound(kind, name string) error {
+	return &amqp.Error{Code: amqp.NotFound, Reason: fmt.Sprintf("NOT_FOUND - no %s '%s'", kind, name)}
+}
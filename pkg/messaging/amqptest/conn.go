@@ -0,0 +1,76 @@
+package amqptest
+
+import (
+	"E-Commerce/pkg/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+// Conn is a connection to a Broker. All of its state is guarded by the
+// broker's mutex.
+type Conn struct {
+	broker       *Broker
+	closed       bool
+	channels     map[*Channel]bool
+	closeNotifys []chan *amqp.Error
+}
+
+// Channel opens a new channel on the connection.
+func (c *Conn) Channel() (messaging.Channel, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	ch := &Channel{broker: b, conn: c, unacked: make(map[uint64]*unacked)}
+	c.channels[ch] = true
+	return ch, nil
+}
+
+func (c *Conn) NotifyClose(ch chan *amqp.Error) chan *amqp.Error {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c.closed {
+		close(ch)
+	} else {
+		c.closeNotifys = append(c.closeNotifys, ch)
+	}
+	return ch
+}
+
+func (c *Conn) Close() error {
+	c.shutdown(nil)
+	return nil
+}
+
+// shutdown closes the connection and its channels, sending cause to every
+// close listener unless it is nil, as for a connection closed by its owner.
+func (c *Conn) shutdown(cause *amqp.Error) {
+	b := c.broker
+	b.mu.Lock()
+	if c.closed {
+		b.mu.Unlock()
+		return
+	}
+	c.closed = true
+	delete(b.conns, c)
+	channels := make([]*Channel, 0, len(c.channels))
+	for ch := range c.channels {
+		channels = append(channels, ch)
+	}
+	notifys := c.closeNotifys
+	c.closeNotifys = nil
+	b.mu.Unlock()
+
+	for _, ch := range channels {
+		ch.shutdown(cause)
+	}
+	for _, n := range notifys {
+		if cause != nil {
+			n <- cause
+		}
+		close(n)
+	}
+}
@@ -0,0 +1,62 @@
+package messaging
+
+import "github.com/streadway/amqp"
+
+// Channel is the subset of *amqp.Channel the services rely on. It lets
+// tests swap the broker for the in-process stand-in in package amqptest.
+type Channel interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	NotifyReturn(c chan amqp.Return) chan amqp.Return
+	NotifyClose(c chan *amqp.Error) chan *amqp.Error
+	Close() error
+}
+
+// Connection is the subset of *amqp.Connection the services rely on.
+type Connection interface {
+	Channel() (Channel, error)
+	NotifyClose(c chan *amqp.Error) chan *amqp.Error
+	Close() error
+}
+
+type amqpConnection struct {
+	conn *amqp.Connection
+}
+
+// Dial connects to a RabbitMQ broker.
+func Dial(url string) (Connection, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return WrapConnection(conn), nil
+}
+
+// WrapConnection adapts an established *amqp.Connection to Connection.
+func WrapConnection(conn *amqp.Connection) Connection {
+	return &amqpConnection{conn: conn}
+}
+
+func (c *amqpConnection) Channel() (Channel, error) {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (c *amqpConnection) NotifyClose(ch chan *amqp.Error) chan *amqp.Error {
+	return c.conn.NotifyClose(ch)
+}
+
+func (c *amqpConnection) Close() error {
+	return c.conn.Close()
+}
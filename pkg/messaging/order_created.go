@@ -0,0 +1,41 @@
+package messaging
+
+import (
+	"context"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/events"
+	"E-Commerce/pkg/tracing"
+)
+
+// PublishOrderCreated wraps event in a CloudEvents envelope from source,
+// checks it against its schema and publishes it on OrderCreatedRoutingKey,
+// returning once the broker accepted it. The trace context of ctx goes into
+// both the envelope and the message headers. Messages are keyed by order
+// ID, so backends that partition keep an order's events in sequence.
+//
+// It is the one way order.created events are published, shared so tests of
+// the consumer see exactly what the producer sends.
+func PublishOrderCreated(ctx context.Context, p broker.Publisher, source string, event events.OrderCreated) (*events.Envelope, []byte, error) {
+	envelope, err := events.NewEnvelope(ctx, events.OrderCreatedType, source, event.EventID, event.OrderID, event.CreatedAt, event)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := events.Encode(envelope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := broker.Message{
+		ID:          event.EventID,
+		Key:         event.OrderID,
+		ContentType: events.ContentType,
+		Timestamp:   envelope.Time,
+		Body:        body,
+	}
+	tracing.Inject(ctx, &msg)
+	if err := p.Publish(ctx, OrderCreatedRoutingKey, msg); err != nil {
+		return nil, nil, err
+	}
+	return envelope, body, nil
+}
@@ -0,0 +1,35 @@
+package messaging
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// RetryQueueName names the delay queue that holds messages for queue for
+// delay before returning them to it. The delay is part of the name because
+// RabbitMQ refuses to redeclare a queue with a different TTL.
+func RetryQueueName(queue string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%dms", queue, delay.Milliseconds())
+}
+
+// RetryTopology declares one delay queue per delay for queue. A message
+// published to a delay queue (through the default exchange, with the delay
+// queue's name as routing key) sits there until its TTL runs out and is
+// then dead-lettered straight back to queue.
+func RetryTopology(queue string, delays []time.Duration) Topology {
+	var t Topology
+	for _, delay := range delays {
+		t.Queues = append(t.Queues, Queue{
+			Name:    RetryQueueName(queue, delay),
+			Durable: true,
+			Args: amqp.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queue,
+			},
+		})
+	}
+	return t
+}
@@ -0,0 +1,95 @@
+// Package messaging holds the RabbitMQ topology shared by the services that
+// publish and consume domain events, so both sides always agree on
+// exchange names, routing keys, queues and durability. It also builds and
+// publishes order.created events, so every publisher sends them the same way.
+package messaging
+
+import (
+	"fmt"
+
+	"github.com/streadway/amqp"
+)
+
+const (
+	OrderEventsExchange    = "order_events"
+	OrderCreatedRoutingKey = "order.created"
+	OrderCreatedQueue      = "order_created_consumer"
+
+	// DeadLetterExchange receives messages that could not be processed;
+	// they are routed by their original routing key.
+	DeadLetterExchange          = "order_events.dlx"
+	OrderCreatedDeadLetterQueue = "order_created_consumer.dlq"
+)
+
+type Exchange struct {
+	Name    string
+	Kind    string
+	Durable bool
+	Args    amqp.Table
+}
+
+type Queue struct {
+	Name    string
+	Durable bool
+	Args    amqp.Table
+}
+
+type Binding struct {
+	Queue      string
+	Exchange   string
+	RoutingKey string
+}
+
+type Topology struct {
+	Exchanges []Exchange
+	Queues    []Queue
+	Bindings  []Binding
+}
+
+// OrderEvents is the topology for order domain events. Publishers and
+// consumers both declare it; declarations are idempotent as long as the
+// definitions match, which is why they must come from this one place.
+var OrderEvents = Topology{
+	Exchanges: []Exchange{
+		{Name: OrderEventsExchange, Kind: amqp.ExchangeTopic, Durable: true},
+		{Name: DeadLetterExchange, Kind: amqp.ExchangeDirect, Durable: true},
+	},
+	Queues: []Queue{
+		{Name: OrderCreatedQueue, Durable: true},
+		{Name: OrderCreatedDeadLetterQueue, Durable: true},
+	},
+	Bindings: []Binding{
+		{Queue: OrderCreatedQueue, Exchange: OrderEventsExchange, RoutingKey: OrderCreatedRoutingKey},
+		{Queue: OrderCreatedDeadLetterQueue, Exchange: DeadLetterExchange, RoutingKey: OrderCreatedRoutingKey},
+	},
+}
+
+// Declare creates every exchange, queue and binding of t on ch.
+func (t Topology) Declare(ch Channel) error {
+	for _, ex := range t.Exchanges {
+		if err := ch.ExchangeDeclare(ex.Name, ex.Kind, ex.Durable, false, false, false, ex.Args); err != nil {
+			return fmt.Errorf("declare exchange %s: %w", ex.Name, err)
+		}
+	}
+	for _, q := range t.Queues {
+		if _, err := ch.QueueDeclare(q.Name, q.Durable, false, false, false, q.Args); err != nil {
+			return fmt.Errorf("declare queue %s: %w", q.Name, err)
+		}
+	}
+	for _, b := range t.Bindings {
+		if err := ch.QueueBind(b.Queue, b.RoutingKey, b.Exchange, false, nil); err != nil {
+			return fmt.Errorf("bind queue %s to %s (%s): %w", b.Queue, b.Exchange, b.RoutingKey, err)
+		}
+	}
+	return nil
+}
+
+// NewPublishing wraps a JSON body in a persistent message so it survives
+// a broker restart once it reaches a durable queue.
+func NewPublishing(body []byte) amqp.Publishing {
+	return amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	}
+}
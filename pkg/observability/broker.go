@@ -0,0 +1,118 @@
+package observability
+
+import (
+	"context"
+	"time"
+
+	"E-Commerce/pkg/broker"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	MessagesPublished = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "broker_messages_published_total",
+			Help: "Total number of messages published, by topic and result",
+		},
+		[]string{"topic", "result"},
+	)
+
+	MessagesConsumed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "broker_messages_consumed_total",
+			Help: "Total number of messages consumed, by topic and how they were settled",
+		},
+		[]string{"topic", "outcome"},
+	)
+)
+
+// Settlement outcomes counted by MessagesConsumed.
+const (
+	OutcomeAck        = "ack"
+	OutcomeRequeue    = "requeue"
+	OutcomeReject     = "reject"
+	OutcomeRetry      = "retry"
+	OutcomeDeadLetter = "dead_letter"
+)
+
+// InstrumentPublisher counts every message p publishes in
+// MessagesPublished.
+func InstrumentPublisher(p broker.Publisher) broker.Publisher {
+	return &instrumentedPublisher{Publisher: p}
+}
+
+type instrumentedPublisher struct {
+	broker.Publisher
+}
+
+func (p *instrumentedPublisher) Publish(ctx context.Context, topic string, msg broker.Message) error {
+	err := p.Publisher.Publish(ctx, topic, msg)
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	MessagesPublished.WithLabelValues(topic, result).Inc()
+	return err
+}
+
+// InstrumentSubscriber counts every delivery of s in MessagesConsumed once
+// it is settled successfully.
+func InstrumentSubscriber(s broker.Subscriber) broker.Subscriber {
+	return &instrumentedSubscriber{Subscriber: s}
+}
+
+type instrumentedSubscriber struct {
+	broker.Subscriber
+}
+
+func (s *instrumentedSubscriber) Subscribe(prefetch int) (<-chan broker.Delivery, error) {
+	deliveries, err := s.Subscriber.Subscribe(prefetch)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan broker.Delivery)
+	go func() {
+		defer close(out)
+		for d := range deliveries {
+			if d.Acknowledger != nil {
+				d.Acknowledger = &countingAcknowledger{Acknowledger: d.Acknowledger, topic: d.Topic}
+			}
+			out <- d
+		}
+	}()
+	return out, nil
+}
+
+type countingAcknowledger struct {
+	broker.Acknowledger
+	topic string
+}
+
+func (a *countingAcknowledger) count(outcome string, err error) error {
+	if err == nil {
+		MessagesConsumed.WithLabelValues(a.topic, outcome).Inc()
+	}
+	return err
+}
+
+func (a *countingAcknowledger) Ack() error {
+	return a.count(OutcomeAck, a.Acknowledger.Ack())
+}
+
+func (a *countingAcknowledger) Nack(requeue bool) error {
+	outcome := OutcomeReject
+	if requeue {
+		outcome = OutcomeRequeue
+	}
+	return a.count(outcome, a.Acknowledger.Nack(requeue))
+}
+
+func (a *countingAcknowledger) Retry(delay time.Duration, headers map[string]interface{}) error {
+	return a.count(OutcomeRetry, a.Acknowledger.Retry(delay, headers))
+}
+
+func (a *countingAcknowledger) DeadLetter(headers map[string]interface{}) error {
+	return a.count(OutcomeDeadLetter, a.Acknowledger.DeadLetter(headers))
+}
@@ -0,0 +1,81 @@
+package observability
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"E-Commerce/pkg/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	GRPCRequestCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_requests_total",
+			Help: "Total number of gRPC requests",
+		},
+		[]string{"service", "method", "status"},
+	)
+
+	GRPCRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_request_duration_seconds",
+			Help:    "Duration of gRPC requests in seconds",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"service", "method"},
+	)
+)
+
+// ServerOptions traces every RPC a server handles and records its latency
+// and status code.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor()),
+	}
+}
+
+// UnaryServerInterceptor records GRPCRequestCounter and
+// GRPCRequestDuration for every unary RPC.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeRPC(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor records GRPCRequestCounter and
+// GRPCRequestDuration for every streaming RPC, timed until the stream ends.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeRPC(ss.Context(), info.FullMethod, start, err)
+		return err
+	}
+}
+
+func observeRPC(ctx context.Context, fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	exemplar := Exemplar(ctx)
+	Observe(GRPCRequestDuration.WithLabelValues(service, method), time.Since(start).Seconds(), exemplar)
+	Inc(GRPCRequestCounter.WithLabelValues(service, method, status.Code(err).String()), exemplar)
+}
+
+// splitMethod splits "/package.Service/Method" into its service and method.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,51 @@
+// Package observability gives every service the same tracing and
+// Prometheus metrics: OTLP traces named after the service, a /metrics
+// listener, per-method gRPC server metrics and broker publish/consume
+// counters.
+package observability
+
+import (
+	"log"
+	"net/http"
+
+	"E-Commerce/pkg/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Init starts exporting the traces of service and serves its metrics on
+// metricsAddr. An empty address serves no metrics, for services that
+// expose them on their own server. The returned function flushes pending
+// traces.
+func Init(service, metricsAddr string) func() {
+	shutdown := tracing.Init(service)
+
+	if metricsAddr != "" {
+		ServeMetrics(metricsAddr)
+	}
+	return shutdown
+}
+
+// MetricsHandler serves the default Prometheus registry. It offers the
+// OpenMetrics format, the only one that carries exemplars.
+func MetricsHandler() http.Handler {
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}),
+	)
+}
+
+// ServeMetrics serves the default Prometheus registry on addr at /metrics
+// in the background.
+func ServeMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", MetricsHandler())
+	go func() {
+		log.Printf("Serving metrics on %s/metrics", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("Metrics server stopped: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,111 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/memory"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptorRecordsMethodAndStatus(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/inventory.InventoryService/GetProduct"}
+	notFound := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "product", nil
+	}
+
+	interceptor(context.Background(), nil, info, ok)
+	interceptor(context.Background(), nil, info, notFound)
+	interceptor(context.Background(), nil, info, notFound)
+
+	counter := GRPCRequestCounter.WithLabelValues("inventory.InventoryService", "GetProduct", "NotFound")
+	if n := testutil.ToFloat64(counter); n != 2 {
+		t.Errorf("NotFound count = %v, want 2", n)
+	}
+	counter = GRPCRequestCounter.WithLabelValues("inventory.InventoryService", "GetProduct", "OK")
+	if n := testutil.ToFloat64(counter); n != 1 {
+		t.Errorf("OK count = %v, want 1", n)
+	}
+	if n := testutil.CollectAndCount(GRPCRequestDuration); n != 1 {
+		t.Errorf("got %d latency series, want 1", n)
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	tests := []struct {
+		fullMethod, service, method string
+	}{
+		{"/order.OrderService/CreateOrder", "order.OrderService", "CreateOrder"},
+		{"CreateOrder", "unknown", "CreateOrder"},
+	}
+	for _, tt := range tests {
+		service, method := splitMethod(tt.fullMethod)
+		if service != tt.service || method != tt.method {
+			t.Errorf("splitMethod(%q) = %q, %q, want %q, %q", tt.fullMethod, service, method, tt.service, tt.method)
+		}
+	}
+}
+
+func TestInstrumentedBrokerCountsPublishAndConsume(t *testing.T) {
+	b := memory.NewBroker()
+	defer b.Close()
+	b.Bind("orders", "order.placed")
+	pub := InstrumentPublisher(b.Publisher())
+	sub := InstrumentSubscriber(b.Subscriber("orders"))
+
+	msgs, err := sub.Subscribe(0)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for _, id := range []string{"m1", "m2"} {
+		if err := pub.Publish(context.Background(), "order.placed", broker.Message{ID: id}); err != nil {
+			t.Fatalf("failed to publish %s: %v", id, err)
+		}
+	}
+	if err := pub.Publish(context.Background(), "payment.placed", broker.Message{ID: "m3"}); !errors.Is(err, broker.ErrUnroutable) {
+		t.Fatalf("got %v, want ErrUnroutable", err)
+	}
+
+	for _, settle := range []func(broker.Delivery) error{
+		func(d broker.Delivery) error { return d.Ack() },
+		func(d broker.Delivery) error { return d.DeadLetter(nil) },
+	} {
+		select {
+		case d := <-msgs:
+			if err := settle(d); err != nil {
+				t.Fatalf("failed to settle %s: %v", d.ID, err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("no message was delivered")
+		}
+	}
+
+	published := []struct {
+		topic, result string
+		want          float64
+	}{
+		{"order.placed", "ok", 2},
+		{"payment.placed", "error", 1},
+	}
+	for _, p := range published {
+		if n := testutil.ToFloat64(MessagesPublished.WithLabelValues(p.topic, p.result)); n != p.want {
+			t.Errorf("published %s %s = %v, want %v", p.topic, p.result, n, p.want)
+		}
+	}
+	for _, outcome := range []string{OutcomeAck, OutcomeDeadLetter} {
+		if n := testutil.ToFloat64(MessagesConsumed.WithLabelValues("order.placed", outcome)); n != 1 {
+			t.Errorf("consumed %s = %v, want 1", outcome, n)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package schema is a registry of the JSON Schemas of domain events, keyed
+// by CloudEvents type, e.g. com.ecommerce.order.created.v1. The version is
+// part of the type, so a payload of a version nobody registered is rejected
+// rather than misread.
+//
+// The schemas of every domain event live in the schemas directory, one
+// <type>.json file each, and are loaded into Default.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	ErrUnknownType = errors.New("no schema registered for event type")
+	ErrInvalid     = errors.New("payload does not match its schema")
+)
+
+//go:embed schemas/*.json
+var files embed.FS
+
+// Default holds the schemas of the schemas directory.
+var Default = mustLoad()
+
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]*jsonschema.Schema
+}
+
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string]*jsonschema.Schema)}
+}
+
+// Register compiles a JSON Schema document for eventType, replacing any
+// schema registered for it before. Formats such as date-time are asserted,
+// not just annotated.
+func (r *Registry) Register(eventType string, doc []byte) error {
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+	c.AssertFormat = true
+	if err := c.AddResource(eventType, bytes.NewReader(doc)); err != nil {
+		return fmt.Errorf("failed to load schema for %s: %v", eventType, err)
+	}
+	s, err := c.Compile(eventType)
+	if err != nil {
+		return fmt.Errorf("failed to compile schema for %s: %v", eventType, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[eventType] = s
+	return nil
+}
+
+// Validate checks a JSON payload against the schema of eventType. It fails
+// with ErrUnknownType if there is none, or with ErrInvalid.
+func (r *Registry) Validate(eventType string, data []byte) error {
+	r.mu.RLock()
+	s, ok := r.schemas[eventType]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownType, eventType)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalid, eventType, err)
+	}
+	if err := s.Validate(v); err != nil {
+		var ve *jsonschema.ValidationError
+		if errors.As(err, &ve) {
+			return fmt.Errorf("%w: %s: %s", ErrInvalid, eventType, describe(ve))
+		}
+		return fmt.Errorf("%w: %s: %v", ErrInvalid, eventType, err)
+	}
+	return nil
+}
+
+// Types lists the registered event types in order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.schemas))
+	for t := range r.schemas {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// describe flattens a validation error to its innermost causes, which name
+// the offending fields, e.g. "/items/0/quantity: must be >= 1".
+func describe(ve *jsonschema.ValidationError) string {
+	if len(ve.Causes) == 0 {
+		loc := ve.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		return loc + ": " + ve.Message
+	}
+	msgs := make([]string, len(ve.Causes))
+	for i, c := range ve.Causes {
+		msgs[i] = describe(c)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func mustLoad() *Registry {
+	r := NewRegistry()
+	entries, err := files.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		doc, err := files.ReadFile(path.Join("schemas", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		if err := r.Register(strings.TrimSuffix(e.Name(), ".json"), doc); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const validOrderCreated = `{
+	"version": 1,
+	"order_id": "order-1",
+	"user_id": "user-1",
+	"items": [{"product_id": "product-1", "quantity": 2, "unit_price": 10}],
+	"total": 20,
+	"created_at": "2024-05-01T12:00:00Z"
+}`
+
+func TestDefaultValidatesOrderCreated(t *testing.T) {
+	if err := Default.Validate("com.ecommerce.order.created.v1", []byte(validOrderCreated)); err != nil {
+		t.Fatalf("valid payload rejected: %v", err)
+	}
+
+	tests := []struct {
+		name, from, to, field string
+	}{
+		{"zero quantity", `"quantity": 2`, `"quantity": 0`, "/items/0/quantity"},
+		{"fractional quantity", `"quantity": 2`, `"quantity": 2.5`, "/items/0/quantity"},
+		{"bad timestamp", `2024-05-01T12:00:00Z`, `yesterday`, "/created_at"},
+		{"wrong version", `"version": 1`, `"version": 2`, "/version"},
+		{"no items", `[{"product_id": "product-1", "quantity": 2, "unit_price": 10}]`, `[]`, "/items"},
+	}
+	for _, tt := range tests {
+		payload := strings.Replace(validOrderCreated, tt.from, tt.to, 1)
+		err := Default.Validate("com.ecommerce.order.created.v1", []byte(payload))
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, ErrInvalid)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.field) {
+			t.Errorf("%s: error %q does not name %s", tt.name, err, tt.field)
+		}
+	}
+}
+
+func TestValidateUnknownType(t *testing.T) {
+	err := Default.Validate("com.ecommerce.order.created.v2", []byte(validOrderCreated))
+	if !errors.Is(err, ErrUnknownType) {
+		t.Fatalf("got %v, want %v", err, ErrUnknownType)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("com.example.thing.v1", []byte(`{"type": "object", "required": ["id"]}`)); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	if err := r.Register("com.example.broken.v1", []byte(`{"type": 42}`)); err == nil {
+		t.Error("an invalid schema was registered")
+	}
+	if got := r.Types(); len(got) != 1 || got[0] != "com.example.thing.v1" {
+		t.Errorf("Types() = %v", got)
+	}
+	if err := r.Validate("com.example.thing.v1", []byte(`{}`)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("got %v, want %v", err, ErrInvalid)
+	}
+}
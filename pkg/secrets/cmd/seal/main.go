@@ -0,0 +1,48 @@
+// Command seal encrypts a JSON object of secrets for the encrypted-file
+// provider, with the key in SECRETS_KEY.
+//
+//	seal -keygen                     print a new key
+//	seal < secrets.json > secrets.enc
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"E-Commerce/pkg/secrets"
+)
+
+func main() {
+	keygen := flag.Bool("keygen", false, "print a new base64-encoded key and exit")
+	flag.Parse()
+
+	if *keygen {
+		key := make([]byte, secrets.KeySize)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			log.Fatalf("Failed to generate key: %v", err)
+		}
+		fmt.Println(base64.StdEncoding.EncodeToString(key))
+		return
+	}
+
+	key, err := base64.StdEncoding.DecodeString(os.Getenv(secrets.KeyEnv))
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", secrets.KeyEnv, err)
+	}
+	plaintext, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatalf("Failed to read secrets: %v", err)
+	}
+	sealed, err := secrets.Encrypt(key, plaintext)
+	if err != nil {
+		log.Fatalf("Failed to encrypt secrets: %v", err)
+	}
+	if _, err := os.Stdout.Write(sealed); err != nil {
+		log.Fatalf("Failed to write secrets: %v", err)
+	}
+}
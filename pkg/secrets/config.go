@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"time"
+)
+
+// KeyEnv holds the base64-encoded key of an encrypted secrets file. It is
+// only ever read from the environment, never from a settings file.
+const KeyEnv = "SECRETS_KEY"
+
+// Config holds the settings that choose where a service reads its secrets
+// from. Services embed it in their own settings; see pkg/settings.
+type Config struct {
+	Environment           string        `setting:"environment" default:"development" required:"true" usage:"development or production; production refuses missing and default secrets"`
+	SecretsProvider       string        `setting:"secrets_provider" default:"env" required:"true" usage:"where secrets are read from: env, file or encrypted-file"`
+	SecretsDir            string        `setting:"secrets_dir" default:"/run/secrets" usage:"directory of secret files, for the file provider"`
+	SecretsFile           string        `setting:"secrets_file" usage:"encrypted secrets file, for the encrypted-file provider"`
+	SecretsReloadInterval time.Duration `setting:"secrets_reload_interval" default:"1m" usage:"how often secrets are reloaded; 0 reloads only on SIGHUP"`
+}
+
+// Production reports whether the service runs in production mode.
+func (c Config) Production() bool {
+	return c.Environment == "production"
+}
+
+// Provider returns the provider selected by c.SecretsProvider.
+func (c Config) Provider() (Provider, error) {
+	switch c.SecretsProvider {
+	case "env":
+		return EnvProvider{}, nil
+	case "file":
+		return FileProvider{Dir: c.SecretsDir}, nil
+	case "encrypted-file":
+		if c.SecretsFile == "" {
+			return nil, fmt.Errorf("the encrypted-file provider needs secrets_file")
+		}
+		key, err := base64.StdEncoding.DecodeString(os.Getenv(KeyEnv))
+		if err != nil || len(key) != KeySize {
+			return nil, fmt.Errorf("%s must hold a base64-encoded %d-byte key", KeyEnv, KeySize)
+		}
+		return EncryptedFileProvider{Path: c.SecretsFile, Key: key}, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets_provider %q, want env, file or encrypted-file", c.SecretsProvider)
+	}
+}
+
+// Open reads specs from the provider selected by c.
+func Open(c Config, specs ...Spec) (*Store, error) {
+	if c.Environment != "development" && c.Environment != "production" {
+		return nil, fmt.Errorf("unknown environment %q, want development or production", c.Environment)
+	}
+	provider, err := c.Provider()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(provider, c.Production(), specs...)
+}
@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// KeySize is the size of the AES-256 key of an encrypted secrets file.
+const KeySize = 32
+
+// EncryptedFileProvider reads secrets from a JSON object of names to
+// values, encrypted with Encrypt. The file is decrypted on every read, so
+// replacing it takes effect on the next reload.
+type EncryptedFileProvider struct {
+	Path string
+	Key  []byte
+}
+
+func (p EncryptedFileProvider) Get(name string) (string, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secrets file: %v", err)
+	}
+	plaintext, err := Decrypt(p.Key, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %v", p.Path, err)
+	}
+	var values map[string]string
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %v", p.Path, err)
+	}
+	v, ok := values[name]
+	if !ok || v == "" {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+// Encrypt seals plaintext with AES-256-GCM under key. The random nonce is
+// prepended to the result.
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt opens data sealed by Encrypt, failing if it was not sealed under
+// key or has been tampered with.
+func Decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
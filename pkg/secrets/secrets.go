@@ -0,0 +1,203 @@
+// Package secrets reads credentials such as signing keys and passwords
+// from the environment, from files mounted by Docker or Kubernetes, or from
+// one encrypted file, and reloads them while the service runs.
+//
+// Services describe the secrets they need as Specs and read them through a
+// Store. In development a missing secret falls back to its default; in
+// production a missing secret, or one still set to its default, stops the
+// service from starting.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrNotFound means a provider has no value for a secret.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider looks up secrets by name, e.g. "jwt_signing_key". Every call
+// reads the current value, so a Store can reload it.
+type Provider interface {
+	Get(name string) (string, error)
+}
+
+// EnvProvider reads a secret from the environment variable named after it
+// in upper case, e.g. JWT_SIGNING_KEY.
+type EnvProvider struct{}
+
+func (EnvProvider) Get(name string) (string, error) {
+	v, ok := os.LookupEnv(strings.ToUpper(name))
+	if !ok || v == "" {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+// FileProvider reads a secret from the file named after it in Dir, the way
+// Docker and Kubernetes mount secrets, e.g. /run/secrets/jwt_signing_key.
+// A trailing newline is ignored.
+type FileProvider struct {
+	Dir string
+}
+
+func (p FileProvider) Get(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(p.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %v", name, err)
+	}
+	v := strings.TrimRight(string(data), "\r\n")
+	if v == "" {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+// Spec describes a secret a service needs.
+type Spec struct {
+	Name string
+	// Default is used in development when the secret is not set. It must
+	// never be a real credential: production refuses it.
+	Default string
+	// Validate, if set, rejects values the service cannot use, so a bad
+	// value stops startup or is not reloaded.
+	Validate func(value string) error
+}
+
+// Store holds the current values of a service's secrets.
+type Store struct {
+	provider   Provider
+	specs      []Spec
+	production bool
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewStore reads every secret in specs from provider. In production it
+// fails, naming each secret that is missing or still set to its default.
+func NewStore(provider Provider, production bool, specs ...Spec) (*Store, error) {
+	s := &Store{provider: provider, specs: specs, production: production}
+	values, defaulted, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range defaulted {
+		log.Printf("Secret %s is not set, using its development default", name)
+	}
+	s.values = values
+	return s, nil
+}
+
+// Get returns the current value of the secret name.
+func (s *Store) Get(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[name]
+}
+
+// Bytes returns a function reading the current value of the secret name,
+// for code that needs a key each time it uses it.
+func (s *Store) Bytes(name string) func() []byte {
+	return func() []byte {
+		return []byte(s.Get(name))
+	}
+}
+
+// Reload reads every secret again. If any of them cannot be read or is
+// unacceptable, the previous values are kept and an error returned.
+func (s *Store) Reload() error {
+	values, _, err := s.read()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the secrets on SIGHUP and, if interval is positive, every
+// interval, until ctx is done.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-tick:
+		}
+		if err := s.Reload(); err != nil {
+			log.Printf("Failed to reload secrets, keeping the current ones: %v", err)
+		}
+	}
+}
+
+// sameSecret reports whether a and b hold the same secret. Providers trim
+// and editors rewrite line endings, so a PEM key that differs only in
+// surrounding whitespace or CRLFs is still the same key.
+func sameSecret(a, b string) bool {
+	normalize := func(s string) string {
+		return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	}
+	return normalize(a) == normalize(b)
+}
+
+// read returns the current values and the names of those that fell back
+// to their defaults.
+func (s *Store) read() (map[string]string, []string, error) {
+	values := make(map[string]string, len(s.specs))
+	var defaulted, problems []string
+	for _, spec := range s.specs {
+		v, err := s.provider.Get(spec.Name)
+		switch {
+		case errors.Is(err, ErrNotFound) && s.production:
+			problems = append(problems, fmt.Sprintf("secret %s is not set", spec.Name))
+			continue
+		case errors.Is(err, ErrNotFound):
+			v = spec.Default
+			defaulted = append(defaulted, spec.Name)
+		case err != nil:
+			return nil, nil, err
+		case s.production && spec.Default != "" && sameSecret(v, spec.Default):
+			problems = append(problems, fmt.Sprintf("secret %s is set to its development default", spec.Name))
+			continue
+		}
+		if spec.Validate != nil {
+			if err := spec.Validate(v); err != nil {
+				problems = append(problems, fmt.Sprintf("secret %s is invalid: %v", spec.Name, err))
+				continue
+			}
+		}
+		values[spec.Name] = v
+	}
+	if len(problems) > 0 {
+		if s.production {
+			return nil, nil, fmt.Errorf("refusing to run in production: %s", strings.Join(problems, "; "))
+		}
+		return nil, nil, errors.New(strings.Join(problems, "; "))
+	}
+	return values, defaulted, nil
+}
@@ -0,0 +1,247 @@
+// Package settings loads a service's configuration into a struct from
+// defaults, an optional YAML file, the environment and command-line flags,
+// each overriding the one before, and validates it at startup.
+//
+// Every exported field tagged `setting:"<key>"` is one setting. The key is
+// used as is in the YAML file, upper-cased after the service's prefix as
+// the environment variable and with dashes as the flag:
+//
+//	type Config struct {
+//		ListenAddr  string `setting:"listen_addr" default:":50051" usage:"gRPC listen address"`
+//		PostgresURL string `setting:"postgres_url" required:"true" usage:"database DSN"`
+//	}
+//
+// loaded with the prefix ORDER_SERVICE reads listen_addr,
+// ORDER_SERVICE_LISTEN_ADDR or -listen-addr. The prefix keeps services
+// that share an environment from reading each other's settings. Fields of
+// embedded structs are settings too. The YAML file is named by -config or
+// the prefixed CONFIG_FILE and holds a flat map of keys. Strings, ints,
+// bools and durations are supported.
+package settings
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv, under the service's prefix, and ConfigFileFlag name the
+// YAML file to load.
+const (
+	ConfigFileEnv  = "CONFIG_FILE"
+	ConfigFileFlag = "config"
+)
+
+// Error reports every setting that is missing or invalid, each by its key,
+// environment variable and flag.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Source is where Load reads settings from besides the defaults.
+type Source struct {
+	// Args are the command-line arguments, without the program name.
+	Args []string
+	// LookupEnv reads an environment variable.
+	LookupEnv func(string) (string, bool)
+	// EnvPrefix is put, with an underscore, in front of every environment
+	// variable read.
+	EnvPrefix string
+}
+
+// Load fills cfg, a pointer to a struct, from the process's environment,
+// read under envPrefix, and command-line arguments.
+func Load(cfg interface{}, envPrefix string) error {
+	return LoadFrom(cfg, Source{Args: os.Args[1:], LookupEnv: os.LookupEnv, EnvPrefix: envPrefix})
+}
+
+// LoadFrom fills cfg from src. Errors about individual settings are
+// collected into one *Error.
+func LoadFrom(cfg interface{}, src Source) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("settings: cfg must be a pointer to a struct, got %T", cfg)
+	}
+	if src.LookupEnv == nil {
+		src.LookupEnv = func(string) (string, bool) { return "", false }
+	}
+	fields, err := fieldsOf(v.Elem())
+	if err != nil {
+		return err
+	}
+	for i := range fields {
+		fields[i].envPrefix = src.EnvPrefix
+	}
+
+	// Flags are parsed first, to find -config, but applied last
+	fs := flag.NewFlagSet("settings", flag.ContinueOnError)
+	configFile := fs.String(ConfigFileFlag, "", "YAML file to read settings from")
+	flagValues := make(map[string]*string, len(fields))
+	for _, f := range fields {
+		flagValues[f.key] = fs.String(f.flagName(), "", f.usage)
+	}
+	if err := fs.Parse(src.Args); err != nil {
+		return err
+	}
+	setFlags := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { setFlags[fl.Name] = true })
+
+	if *configFile == "" {
+		*configFile, _ = src.LookupEnv(prefixed(src.EnvPrefix, ConfigFileEnv))
+	}
+	var file map[string]interface{}
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %v", err)
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %v", *configFile, err)
+		}
+	}
+
+	var problems []string
+	for _, f := range fields {
+		raw, set := f.def, f.hasDefault
+		if val, ok := file[f.key]; ok {
+			raw, set = fmt.Sprint(val), true
+		}
+		if val, ok := src.LookupEnv(f.envName()); ok {
+			raw, set = val, true
+		}
+		if setFlags[f.flagName()] {
+			raw, set = *flagValues[f.key], true
+		}
+
+		if !set || raw == "" {
+			if f.required {
+				problems = append(problems, "missing required setting "+f.describe())
+			}
+			continue
+		}
+		if err := f.set(raw); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid value %q for %s: %v", raw, f.describe(), err))
+		}
+	}
+	for key := range file {
+		if !hasKey(fields, key) {
+			problems = append(problems, fmt.Sprintf("unknown setting %q in %s", key, *configFile))
+		}
+	}
+	if len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	return nil
+}
+
+type field struct {
+	key        string
+	def        string
+	hasDefault bool
+	required   bool
+	usage      string
+	value      reflect.Value
+	envPrefix  string
+}
+
+func fieldsOf(v reflect.Value) ([]field, error) {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key, ok := sf.Tag.Lookup("setting")
+		if !ok {
+			// Settings shared between services come as embedded structs
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				embedded, err := fieldsOf(v.Field(i))
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, embedded...)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("settings: field %s is not exported", sf.Name)
+		}
+		def, hasDefault := sf.Tag.Lookup("default")
+		fields = append(fields, field{
+			key:        key,
+			def:        def,
+			hasDefault: hasDefault,
+			required:   sf.Tag.Get("required") == "true",
+			usage:      sf.Tag.Get("usage"),
+			value:      v.Field(i),
+		})
+	}
+	return fields, nil
+}
+
+func (f field) envName() string {
+	return prefixed(f.envPrefix, strings.ToUpper(f.key))
+}
+
+func prefixed(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+func (f field) flagName() string {
+	return strings.ReplaceAll(f.key, "_", "-")
+}
+
+func (f field) describe() string {
+	return fmt.Sprintf("%s (env %s, flag -%s)", f.key, f.envName(), f.flagName())
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (f field) set(raw string) error {
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(raw)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("not a boolean")
+		}
+		f.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+func hasKey(fields []field, key string) bool {
+	for _, f := range fields {
+		if f.key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	ListenAddr    string        `setting:"listen_addr" default:":50051" usage:"gRPC listen address"`
+	PostgresURL   string        `setting:"postgres_url" required:"true" usage:"database DSN"`
+	InventoryAddr string        `setting:"inventory_addr" default:"localhost:50051" required:"true"`
+	Workers       int           `setting:"workers" default:"4"`
+	Ordered       bool          `setting:"ordered"`
+	Retention     time.Duration `setting:"retention" default:"1h"`
+	ignored       string
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadAppliesDefaults(t *testing.T) {
+	var cfg testConfig
+	err := LoadFrom(&cfg, Source{LookupEnv: env(map[string]string{"POSTGRES_URL": "postgres://db"})})
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	want := testConfig{
+		ListenAddr:    ":50051",
+		PostgresURL:   "postgres://db",
+		InventoryAddr: "localhost:50051",
+		Workers:       4,
+		Retention:     time.Hour,
+	}
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "listen_addr: ':7000'\nworkers: 8\nordered: true\npostgres_url: postgres://file\n")
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, Source{
+		Args: []string{"-config", path, "-workers", "16"},
+		LookupEnv: env(map[string]string{
+			"LISTEN_ADDR": ":8000",
+			"WORKERS":     "12",
+		}),
+	})
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if cfg.PostgresURL != "postgres://file" || !cfg.Ordered {
+		t.Errorf("file settings not applied: %+v", cfg)
+	}
+	if cfg.ListenAddr != ":8000" {
+		t.Errorf("listen_addr = %q, want the environment's :8000", cfg.ListenAddr)
+	}
+	if cfg.Workers != 16 {
+		t.Errorf("workers = %d, want the flag's 16", cfg.Workers)
+	}
+}
+
+func TestLoadReadsConfigFileFromEnv(t *testing.T) {
+	path := writeFile(t, "postgres_url: postgres://file\n")
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, Source{LookupEnv: env(map[string]string{ConfigFileEnv: path})}); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if cfg.PostgresURL != "postgres://file" {
+		t.Errorf("postgres_url = %q, want the file's", cfg.PostgresURL)
+	}
+}
+
+func TestLoadNamesMissingSettings(t *testing.T) {
+	var cfg testConfig
+	err := LoadFrom(&cfg, Source{LookupEnv: env(map[string]string{"INVENTORY_ADDR": ""})})
+
+	var settingsErr *Error
+	if !errors.As(err, &settingsErr) {
+		t.Fatalf("got %v, want *Error", err)
+	}
+	want := []string{
+		"missing required setting postgres_url (env POSTGRES_URL, flag -postgres-url)",
+		"missing required setting inventory_addr (env INVENTORY_ADDR, flag -inventory-addr)",
+	}
+	if strings.Join(settingsErr.Problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got problems %q, want %q", settingsErr.Problems, want)
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	path := writeFile(t, "postgres_url: postgres://file\nworker: 3\n")
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, Source{
+		Args:      []string{"-config", path, "-retention", "soon"},
+		LookupEnv: env(map[string]string{"WORKERS": "many"}),
+	})
+	if err == nil {
+		t.Fatal("loaded invalid settings")
+	}
+	for _, want := range []string{
+		`invalid value "many" for workers (env WORKERS, flag -workers)`,
+		`invalid value "soon" for retention (env RETENTION, flag -retention)`,
+		`unknown setting "worker"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownFlags(t *testing.T) {
+	var cfg testConfig
+	err := LoadFrom(&cfg, Source{Args: []string{"-listen", ":1"}, LookupEnv: env(map[string]string{"POSTGRES_URL": "x"})})
+	if err == nil {
+		t.Fatal("accepted an unknown flag")
+	}
+}
+
+type sharedSettings struct {
+	Environment string `setting:"environment" default:"development"`
+}
+
+func TestLoadEmbeddedSettings(t *testing.T) {
+	var cfg struct {
+		sharedSettings
+		ListenAddr string `setting:"listen_addr"`
+	}
+	err := LoadFrom(&cfg, Source{Args: []string{"-environment", "production", "-listen-addr", ":1"}})
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if cfg.Environment != "production" || cfg.ListenAddr != ":1" {
+		t.Errorf("got %+v, want production on :1", cfg)
+	}
+}
+
+func TestLoadReadsPrefixedEnv(t *testing.T) {
+	path := writeFile(t, "workers: 8\n")
+	var cfg testConfig
+	err := LoadFrom(&cfg, Source{EnvPrefix: "ORDER_SERVICE", LookupEnv: env(map[string]string{
+		"ORDER_SERVICE_POSTGRES_URL": "postgres://orders",
+		"ORDER_SERVICE_CONFIG_FILE":  path,
+		// Another service's settings, or unprefixed ones, are not read
+		"USER_SERVICE_LISTEN_ADDR": ":1",
+		"LISTEN_ADDR":              ":2",
+	})})
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if cfg.PostgresURL != "postgres://orders" || cfg.ListenAddr != ":50051" || cfg.Workers != 8 {
+		t.Errorf("got %+v", cfg)
+	}
+
+	err = LoadFrom(&testConfig{}, Source{EnvPrefix: "ORDER_SERVICE", LookupEnv: env(map[string]string{"POSTGRES_URL": "postgres://orders"})})
+	want := "invalid configuration: missing required setting postgres_url (env ORDER_SERVICE_POSTGRES_URL, flag -postgres-url)"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,132 @@
+// Package tracing sets up OpenTelemetry tracing the same way in every
+// service, so that one request shows up as one trace across HTTP, gRPC and
+// the message broker. Trace context travels as W3C traceparent and
+// tracestate: in HTTP headers, gRPC metadata and broker message headers.
+package tracing
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"E-Commerce/pkg/broker"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const instrumentationName = "E-Commerce/pkg/tracing"
+
+// Propagator carries W3C trace context and baggage. Init installs it
+// globally; it is used directly for broker messages.
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// Init exports the traces of service over OTLP to
+// OTEL_EXPORTER_OTLP_ENDPOINT (default localhost:4317) and installs the
+// tracer provider and Propagator globally. The returned function flushes
+// and stops the exporter.
+func Init(service string) func() {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "localhost:4317"
+	}
+	exporter, err := otlptracegrpc.New(context.Background(),
+		otlptracegrpc.WithEndpoint(endpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create exporter: %v", err)
+	}
+
+	resources, err := resource.New(
+		context.Background(),
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String(service),
+		),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create resource: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resources),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator)
+
+	return func() {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			log.Printf("Error shutting down tracer provider: %v", err)
+		}
+	}
+}
+
+// ServerOption traces every RPC a server handles, continuing the trace of
+// the caller.
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+// DialOption traces every RPC made on a client connection and sends the
+// trace context along.
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
+
+// Inject adds the trace context of ctx to the headers of msg.
+func Inject(ctx context.Context, msg *broker.Message) {
+	if msg.Headers == nil {
+		msg.Headers = make(map[string]interface{})
+	}
+	Propagator.Inject(ctx, headerCarrier(msg.Headers))
+}
+
+// Extract returns ctx with the trace context carried by the headers of msg.
+func Extract(ctx context.Context, msg broker.Message) context.Context {
+	return Propagator.Extract(ctx, headerCarrier(msg.Headers))
+}
+
+// StartConsumer starts the span of processing msg, continuing the trace it
+// carries. The caller ends the span.
+func StartConsumer(msg broker.Message, name string) (context.Context, trace.Span) {
+	ctx := Extract(context.Background(), msg)
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.String("messaging.message.id", msg.ID),
+		),
+	)
+}
+
+// headerCarrier adapts broker message headers to propagation.TextMapCarrier.
+type headerCarrier map[string]interface{}
+
+func (c headerCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"E-Commerce/pkg/broker"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestInjectExtractRoundTrip(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	var msg broker.Message
+	Inject(ctx, &msg)
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if got := msg.Header("traceparent"); got != want {
+		t.Fatalf("traceparent = %q, want %q", got, want)
+	}
+
+	got := trace.SpanContextFromContext(Extract(context.Background(), msg))
+	if got.TraceID() != sc.TraceID() || got.SpanID() != sc.SpanID() || !got.IsRemote() {
+		t.Errorf("extracted %+v, want the injected span context as remote", got)
+	}
+}
+
+func TestExtractWithoutTraceContext(t *testing.T) {
+	ctx := Extract(context.Background(), broker.Message{Headers: map[string]interface{}{"x-attempt": 2}})
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		t.Error("extracted a span context from a message without one")
+	}
+}
+
+func TestStartConsumerContinuesMessageTrace(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	msg := broker.Message{ID: "m1", Topic: "order.created", Headers: map[string]interface{}{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}}
+	ctx, span := StartConsumer(msg, "order.created process")
+	defer span.End()
+
+	sc := trace.SpanContextFromContext(ctx)
+	if sc.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the message's", sc.TraceID())
+	}
+	if sc.SpanID().String() == "00f067aa0ba902b7" || sc.IsRemote() {
+		t.Error("no local child span was started")
+	}
+}
@@ -1,34 +1,83 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net"
+	"time"
 
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/jetstream"
+	"E-Commerce/pkg/broker/rabbitmq"
+	"E-Commerce/pkg/messaging"
+	"E-Commerce/pkg/observability"
 	"E-Commerce/producer-service/config"
 	"E-Commerce/producer-service/internal/handler"
 	"E-Commerce/producer-service/internal/repository"
 	"E-Commerce/producer-service/internal/service"
 	pb "E-Commerce/producer-service/proto"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
 )
 
 func main() {
-	cfg := config.NewConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	shutdown := observability.Init("producer-service", cfg.MetricsAddr)
+	defer shutdown()
+
+	publisher, err := newPublisher(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create event publisher: %v", err)
+	}
+	repo := repository.NewEventRepository(observability.InstrumentPublisher(publisher))
+	defer repo.Close()
 
-	repo := repository.NewRabbitMQRepository(cfg.RabbitMQConn)
-	svc := service.NewProducerService(repo)
+	db, err := sqlx.Connect("postgres", cfg.PostgresURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to the event store: %v", err)
+	}
+	defer db.Close()
+	svc := service.NewProducerService(repo, repository.NewEventStoreRepository(db))
 	h := handler.NewProducerHandler(svc)
 
-	
-	lis, err := net.Listen("tcp", ":50054")
+	lis, err := net.Listen("tcp", cfg.ListenAddr)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
-	s := grpc.NewServer()
+	s := grpc.NewServer(observability.ServerOptions()...)
 	pb.RegisterProducerServiceServer(s, h)
 
-	log.Println("producer-service started, listening on :50054")
+	log.Printf("producer-service started, listening on %s", cfg.ListenAddr)
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// newPublisher connects to the event backend selected by cfg.Broker.
+func newPublisher(cfg *config.Config) (broker.Publisher, error) {
+	switch cfg.Broker {
+	case "rabbitmq":
+		dial := func() (messaging.Connection, error) {
+			return messaging.Dial(cfg.RabbitMQURL)
+		}
+		return rabbitmq.NewPublisher(dial, messaging.OrderEvents, messaging.OrderEventsExchange, rabbitmq.DefaultPublisherConfig())
+	case "jetstream":
+		client, err := jetstream.Connect(cfg.NATSURL)
+		if err != nil {
+			return nil, err
+		}
+		publisher, err := jetstream.NewPublisher(client, 5*time.Second, jetstream.OrderEvents)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+		return publisher, nil
+	default:
+		return nil, fmt.Errorf("unknown broker %q, want rabbitmq or jetstream", cfg.Broker)
+	}
+}
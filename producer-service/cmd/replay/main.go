@@ -0,0 +1,66 @@
+// Command replay asks producer-service to publish recorded events again, so
+// a downstream projection can be rebuilt from the event store.
+//
+//	replay -routing-key KEY [-from RFC3339] [-to RFC3339] [-type TYPE]...
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	pb "E-Commerce/producer-service/proto"
+
+	"google.golang.org/grpc"
+)
+
+// typeList collects repeated -type flags.
+type typeList []string
+
+func (l *typeList) String() string { return strings.Join(*l, ",") }
+
+func (l *typeList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	routingKey := flag.String("routing-key", "", "routing key to publish the events on (required)")
+	from := flag.String("from", "", "replay events that occurred at or after this RFC3339 time")
+	to := flag.String("to", "", "replay events that occurred before this RFC3339 time")
+	timeout := flag.Duration("timeout", 10*time.Minute, "how long the replay may take")
+	var types typeList
+	flag.Var(&types, "type", "replay only events of this CloudEvents type; repeatable")
+	flag.Parse()
+	if *routingKey == "" {
+		fmt.Fprintln(os.Stderr, "usage: replay -routing-key KEY [-from RFC3339] [-to RFC3339] [-type TYPE]...")
+		os.Exit(2)
+	}
+
+	addr := os.Getenv("PRODUCER_ADDR")
+	if addr == "" {
+		addr = "localhost:50054"
+	}
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("Failed to connect to producer-service: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	resp, err := pb.NewProducerServiceClient(conn).ReplayEvents(ctx, &pb.ReplayEventsRequest{
+		From:       *from,
+		To:         *to,
+		EventTypes: types,
+		RoutingKey: *routingKey,
+	})
+	if err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+	fmt.Printf("Replayed %d events to %s\n", resp.Replayed, *routingKey)
+}
@@ -5,6 +5,8 @@ go 1.23
 toolchain go1.24.2
 
 require (
+	github.com/jmoiron/sqlx v1.3.5
+	github.com/lib/pq v1.10.9
 	github.com/streadway/amqp v1.1.0
 	google.golang.org/grpc v1.72.0
 	google.golang.org/protobuf v1.36.6
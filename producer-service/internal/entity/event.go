@@ -1,6 +1,7 @@
 package entity
 
-type OrderCreatedEvent struct {
-	OrderID  string   `json:"order_id"`
-	Products []string `json:"products"`
-}
\ No newline at end of file
+import "E-Commerce/pkg/events"
+
+// OrderCreatedEvent is the versioned order.created payload shared by the
+// producer and consumer services.
+type OrderCreatedEvent = events.OrderCreated
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// StoredEvent is a published event as recorded in the event store. Payload
+// holds the CloudEvents envelope exactly as it was published.
+type StoredEvent struct {
+	Position   int64     `db:"position"`
+	EventID    string    `db:"event_id"`
+	EventType  string    `db:"event_type"`
+	Subject    string    `db:"subject"`
+	RoutingKey string    `db:"routing_key"`
+	Payload    []byte    `db:"payload"`
+	OccurredAt time.Time `db:"occurred_at"`
+	RecordedAt time.Time `db:"recorded_at"`
+}
+
+// EventFilter selects stored events. Zero fields do not filter.
+type EventFilter struct {
+	// From is inclusive and To exclusive; both apply to OccurredAt.
+	From  time.Time
+	To    time.Time
+	Types []string
+}
@@ -2,10 +2,19 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/events"
+	"E-Commerce/pkg/schema"
+	"E-Commerce/producer-service/internal/entity"
 	"E-Commerce/producer-service/internal/service"
 	pb "E-Commerce/producer-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type ProducerHandler struct {
@@ -18,12 +27,81 @@ func NewProducerHandler(svc service.ProducerService) *ProducerHandler {
 }
 
 func (h *ProducerHandler) NotifyOrderCreated(ctx context.Context, req *pb.OrderCreatedRequest) (*pb.OrderCreatedResponse, error) {
-	log.Printf("Received order.created notification for order %s with products: %v", req.OrderId, req.ProductIds)
-	err := h.svc.PublishOrderCreated(req.OrderId, req.ProductIds)
+	log.Printf("Received order.created notification for order %s with %d items", req.OrderId, len(req.Items))
+
+	createdAt, err := time.Parse(time.RFC3339, req.CreatedAt)
 	if err != nil {
-		log.Printf("Failed to publish order.created event: %v", err)
-		return &pb.OrderCreatedResponse{Success: false}, err
+		return nil, status.Error(codes.InvalidArgument, "invalid created_at timestamp")
+	}
+	event := entity.OrderCreatedEvent{
+		Version:       int(req.Version),
+		EventID:       req.EventId,
+		OrderID:       req.OrderId,
+		UserID:        req.UserId,
+		Items:         make([]events.LineItem, len(req.Items)),
+		Total:         req.Total,
+		CreatedAt:     createdAt,
+		ReservationID: req.ReservationId,
+	}
+	for i, item := range req.Items {
+		event.Items[i] = events.LineItem{
+			ProductID: item.ProductId,
+			Quantity:  int(item.Quantity),
+			UnitPrice: item.UnitPrice,
+		}
+	}
+
+	err = h.svc.PublishOrderCreated(ctx, event)
+	if errors.Is(err, events.ErrUnsupportedVersion) || errors.Is(err, events.ErrInvalidEvent) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		log.Printf("Failed to publish order.created event for order %s: %v", req.OrderId, err)
+		if errors.Is(err, broker.ErrConfirmTimeout) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 	log.Printf("Successfully published order.created event for order %s", req.OrderId)
 	return &pb.OrderCreatedResponse{Success: true}, nil
-}
\ No newline at end of file
+}
+
+func (h *ProducerHandler) ReplayEvents(ctx context.Context, req *pb.ReplayEventsRequest) (*pb.ReplayEventsResponse, error) {
+	var filter entity.EventFilter
+	var err error
+	if req.From != "" {
+		if filter.From, err = time.Parse(time.RFC3339, req.From); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid from timestamp")
+		}
+	}
+	if req.To != "" {
+		if filter.To, err = time.Parse(time.RFC3339, req.To); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid to timestamp")
+		}
+	}
+	known := make(map[string]bool)
+	for _, t := range schema.Default.Types() {
+		known[t] = true
+	}
+	for _, t := range req.EventTypes {
+		if !known[t] {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown event type %q", t)
+		}
+	}
+	filter.Types = req.EventTypes
+
+	log.Printf("Replaying events from %q to %q of types %v to %s", req.From, req.To, req.EventTypes, req.RoutingKey)
+	n, err := h.svc.ReplayEvents(ctx, filter, req.RoutingKey)
+	switch {
+	case errors.Is(err, service.ErrInvalidReplay):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, broker.ErrUnroutable):
+		return nil, status.Errorf(codes.FailedPrecondition, "nothing consumes routing key %s: %v", req.RoutingKey, err)
+	case errors.Is(err, broker.ErrConfirmTimeout):
+		return nil, status.Errorf(codes.Unavailable, "replayed %d events before: %v", n, err)
+	case err != nil:
+		log.Printf("Replay to %s failed after %d events: %v", req.RoutingKey, n, err)
+		return nil, status.Errorf(codes.Internal, "replayed %d events before: %v", n, err)
+	}
+	return &pb.ReplayEventsResponse{Replayed: n}, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"E-Commerce/producer-service/internal/entity"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type EventStoreRepository interface {
+	// Append records a published event. Recording an event ID again is a
+	// no-op, so an event that is published twice is stored once.
+	Append(event *entity.StoredEvent) error
+	// List returns up to limit events matching filter that were recorded
+	// after position, oldest first.
+	List(filter entity.EventFilter, after int64, limit int) ([]*entity.StoredEvent, error)
+}
+
+type eventStoreRepository struct {
+	db *sqlx.DB
+}
+
+func NewEventStoreRepository(db *sqlx.DB) EventStoreRepository {
+	return &eventStoreRepository{db: db}
+}
+
+func (r *eventStoreRepository) Append(event *entity.StoredEvent) error {
+	query := `INSERT INTO event_store (event_id, event_type, subject, routing_key, payload, occurred_at)
+	          VALUES (:event_id, :event_type, :subject, :routing_key, :payload, :occurred_at)
+	          ON CONFLICT (event_id) DO NOTHING`
+	_, err := r.db.NamedExec(query, event)
+	return err
+}
+
+func (r *eventStoreRepository) List(filter entity.EventFilter, after int64, limit int) ([]*entity.StoredEvent, error) {
+	// occurred_at holds UTC times without a zone
+	var from, to interface{}
+	if !filter.From.IsZero() {
+		from = filter.From.UTC()
+	}
+	if !filter.To.IsZero() {
+		to = filter.To.UTC()
+	}
+	var types interface{}
+	if len(filter.Types) > 0 {
+		types = pq.Array(filter.Types)
+	}
+
+	var events []*entity.StoredEvent
+	err := r.db.Select(&events, `
+		SELECT * FROM event_store
+		WHERE position > $1
+		  AND ($2::timestamp IS NULL OR occurred_at >= $2)
+		  AND ($3::timestamp IS NULL OR occurred_at < $3)
+		  AND ($4::text[] IS NULL OR event_type = ANY($4))
+		ORDER BY position
+		LIMIT $5`,
+		after, from, to, types, limit)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/events"
+	"E-Commerce/pkg/messaging"
+	"E-Commerce/pkg/tracing"
+	"E-Commerce/producer-service/internal/entity"
+)
+
+// eventSource is the CloudEvents source of every event published here.
+const eventSource = "/producer-service"
+
+// ReplayHeader marks a republished event with the ID of its replay.
+const ReplayHeader = "x-replay-id"
+
+type EventRepository interface {
+	// PublishOrderCreated returns the event as it was published, ready to
+	// be recorded in the event store.
+	PublishOrderCreated(ctx context.Context, event entity.OrderCreatedEvent) (*entity.StoredEvent, error)
+	// Republish publishes a stored event again on routingKey as part of
+	// replay replayID.
+	Republish(ctx context.Context, event *entity.StoredEvent, routingKey, replayID string) error
+	Close() error
+}
+
+type eventRepository struct {
+	publisher broker.Publisher
+}
+
+func NewEventRepository(publisher broker.Publisher) EventRepository {
+	return &eventRepository{publisher: publisher}
+}
+
+// PublishOrderCreated publishes the event with
+// messaging.PublishOrderCreated. It fails with broker.ErrConfirmTimeout if
+// no confirmation arrived in time, in which case the event may or may not
+// have been delivered.
+func (r *eventRepository) PublishOrderCreated(ctx context.Context, event entity.OrderCreatedEvent) (*entity.StoredEvent, error) {
+	envelope, body, err := messaging.PublishOrderCreated(ctx, r.publisher, eventSource, event)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.StoredEvent{
+		EventID:    envelope.ID,
+		EventType:  envelope.Type,
+		Subject:    envelope.Subject,
+		RoutingKey: messaging.OrderCreatedRoutingKey,
+		Payload:    body,
+		OccurredAt: envelope.Time,
+	}, nil
+}
+
+// Republish keeps the envelope, and so the event ID consumers deduplicate
+// by, but gives the message an ID of its own: brokers that drop repeated
+// message IDs would otherwise drop a replay of a recent event.
+func (r *eventRepository) Republish(ctx context.Context, event *entity.StoredEvent, routingKey, replayID string) error {
+	msg := broker.Message{
+		ID:          event.EventID + "/" + replayID,
+		Key:         event.Subject,
+		Headers:     map[string]interface{}{ReplayHeader: replayID},
+		ContentType: events.ContentType,
+		Timestamp:   event.OccurredAt,
+		Body:        event.Payload,
+	}
+	tracing.Inject(ctx, &msg)
+	return r.publisher.Publish(ctx, routingKey, msg)
+}
+
+func (r *eventRepository) Close() error {
+	return r.publisher.Close()
+}
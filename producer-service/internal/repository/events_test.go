@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/memory"
+	"E-Commerce/pkg/events"
+	"E-Commerce/pkg/messaging"
+	"E-Commerce/producer-service/internal/entity"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func testEvent(orderID string) entity.OrderCreatedEvent {
+	return entity.OrderCreatedEvent{
+		Version:   events.OrderCreatedVersion,
+		EventID:   "event-" + orderID,
+		OrderID:   orderID,
+		Items:     []events.LineItem{{ProductID: "product-1", Quantity: 1, UnitPrice: 10}},
+		Total:     10,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestPublishOrderCreated(t *testing.T) {
+	b := memory.NewBroker()
+	defer b.Close()
+	b.Bind(messaging.OrderCreatedQueue, messaging.OrderCreatedRoutingKey)
+	repo := NewEventRepository(b.Publisher())
+
+	event := testEvent("order-1")
+	ctx := events.ContextWithTraceParent(context.Background(), testTraceParent)
+	stored, err := repo.PublishOrderCreated(ctx, event)
+	if err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if stored.EventID != event.EventID || stored.EventType != events.OrderCreatedType || stored.RoutingKey != messaging.OrderCreatedRoutingKey {
+		t.Errorf("got stored event %+v", stored)
+	}
+
+	msgs, err := b.Subscriber(messaging.OrderCreatedQueue).Subscribe(0)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	msg := <-msgs
+	if msg.ID != event.EventID || msg.Topic != messaging.OrderCreatedRoutingKey {
+		t.Errorf("got message %q on %q, want %q on %q", msg.ID, msg.Topic, event.EventID, messaging.OrderCreatedRoutingKey)
+	}
+	if msg.Key != event.OrderID {
+		t.Errorf("got key %q, want the order ID %q", msg.Key, event.OrderID)
+	}
+	if tp := msg.Header("traceparent"); tp != testTraceParent {
+		t.Errorf("got traceparent header %q, want %q", tp, testTraceParent)
+	}
+	if msg.ContentType != events.ContentType {
+		t.Errorf("got content type %q, want %q", msg.ContentType, events.ContentType)
+	}
+	envelope, err := events.Decode(msg.Body)
+	if err != nil {
+		t.Fatalf("failed to decode envelope: %v", err)
+	}
+	if envelope.Type != events.OrderCreatedType || envelope.ID != event.EventID || envelope.Source != eventSource || envelope.TraceParent != testTraceParent {
+		t.Errorf("got envelope %+v", envelope)
+	}
+	var got entity.OrderCreatedEvent
+	if err := json.Unmarshal(envelope.Data, &got); err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	if got.OrderID != event.OrderID || !got.CreatedAt.Equal(event.CreatedAt) || len(got.Items) != 1 {
+		t.Errorf("got event %+v, want %+v", got, event)
+	}
+}
+
+func TestPublishOrderCreatedWithoutSubscribersFails(t *testing.T) {
+	b := memory.NewBroker()
+	defer b.Close()
+	repo := NewEventRepository(b.Publisher())
+
+	_, err := repo.PublishOrderCreated(context.Background(), testEvent("order-1"))
+	if !errors.Is(err, broker.ErrUnroutable) {
+		t.Errorf("got %v, want %v", err, broker.ErrUnroutable)
+	}
+}
+
+func TestPublishOrderCreatedRejectsPayloadAgainstSchema(t *testing.T) {
+	b := memory.NewBroker()
+	defer b.Close()
+	b.Bind(messaging.OrderCreatedQueue, messaging.OrderCreatedRoutingKey)
+	repo := NewEventRepository(b.Publisher())
+
+	event := testEvent("order-1")
+	event.Items[0].Quantity = 0
+	_, err := repo.PublishOrderCreated(context.Background(), event)
+	if !errors.Is(err, events.ErrInvalidEvent) {
+		t.Fatalf("got %v, want %v", err, events.ErrInvalidEvent)
+	}
+	if n := b.Len(messaging.OrderCreatedQueue); n != 0 {
+		t.Errorf("%d invalid events were published", n)
+	}
+}
+
+func TestRepublishKeepsEnvelope(t *testing.T) {
+	b := memory.NewBroker()
+	defer b.Close()
+	b.Bind(messaging.OrderCreatedQueue, messaging.OrderCreatedRoutingKey)
+	b.Bind("projection", "order.created.replay")
+	repo := NewEventRepository(b.Publisher())
+
+	stored, err := repo.PublishOrderCreated(context.Background(), testEvent("order-1"))
+	if err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if err := repo.Republish(context.Background(), stored, "order.created.replay", "r1"); err != nil {
+		t.Fatalf("republish failed: %v", err)
+	}
+
+	msgs, err := b.Subscriber("projection").Subscribe(0)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	msg := <-msgs
+	if msg.ID == stored.EventID || msg.Header(ReplayHeader) != "r1" || msg.Key != "order-1" {
+		t.Errorf("got message %q with replay %q and key %q", msg.ID, msg.Header(ReplayHeader), msg.Key)
+	}
+	envelope, err := events.Decode(msg.Body)
+	if err != nil || envelope.ID != stored.EventID {
+		t.Errorf("got envelope %+v, %v; want event %s", envelope, err, stored.EventID)
+	}
+}
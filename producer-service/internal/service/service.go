@@ -1,26 +1,90 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
 	"E-Commerce/producer-service/internal/entity"
 	"E-Commerce/producer-service/internal/repository"
 )
 
+// replayBatchSize is how many stored events a replay reads at a time.
+const replayBatchSize = 100
+
+var ErrInvalidReplay = errors.New("invalid replay")
+
 type ProducerService interface {
-	PublishOrderCreated(orderID string, products []string) error
+	PublishOrderCreated(ctx context.Context, event entity.OrderCreatedEvent) error
+	// ReplayEvents publishes the stored events matching filter again on
+	// routingKey, oldest first, and returns how many it published.
+	ReplayEvents(ctx context.Context, filter entity.EventFilter, routingKey string) (int64, error)
 }
 
 type producerService struct {
-	repo repository.RabbitMQRepository
+	repo  repository.EventRepository
+	store repository.EventStoreRepository
 }
 
-func NewProducerService(repo repository.RabbitMQRepository) ProducerService {
-	return &producerService{repo: repo}
+func NewProducerService(repo repository.EventRepository, store repository.EventStoreRepository) ProducerService {
+	return &producerService{repo: repo, store: store}
 }
 
-func (s *producerService) PublishOrderCreated(orderID string, products []string) error {
-	event := entity.OrderCreatedEvent{
-		OrderID:  orderID,
-		Products: products,
+// PublishOrderCreated records the event in the event store once it was
+// published. If recording fails the call fails too, so the caller publishes
+// it again; the store keeps one record per event ID.
+func (s *producerService) PublishOrderCreated(ctx context.Context, event entity.OrderCreatedEvent) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	stored, err := s.repo.PublishOrderCreated(ctx, event)
+	if err != nil {
+		return err
 	}
-	return s.repo.PublishOrderCreated(event)
-}
\ No newline at end of file
+	if err := s.store.Append(stored); err != nil {
+		log.Printf("Failed to record event %s in the event store: %v", stored.EventID, err)
+		return fmt.Errorf("failed to record event %s: %v", stored.EventID, err)
+	}
+	return nil
+}
+
+// ReplayEvents stops at the first event it fails to publish, after the
+// ones published before it. Without an upper bound it replays the events
+// that occurred before it started.
+func (s *producerService) ReplayEvents(ctx context.Context, filter entity.EventFilter, routingKey string) (int64, error) {
+	if routingKey == "" {
+		return 0, fmt.Errorf("%w: missing routing key", ErrInvalidReplay)
+	}
+	now := time.Now()
+	if filter.To.IsZero() {
+		filter.To = now
+	}
+	if !filter.From.IsZero() && !filter.From.Before(filter.To) {
+		return 0, fmt.Errorf("%w: from %s is not before to %s", ErrInvalidReplay, filter.From.Format(time.RFC3339), filter.To.Format(time.RFC3339))
+	}
+
+	replayID := strconv.FormatInt(now.UnixNano(), 36)
+	var replayed int64
+	var after int64
+	for {
+		batch, err := s.store.List(filter, after, replayBatchSize)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read the event store: %v", err)
+		}
+		for _, event := range batch {
+			if err := s.repo.Republish(ctx, event, routingKey, replayID); err != nil {
+				return replayed, fmt.Errorf("failed to republish event %s: %w", event.EventID, err)
+			}
+			replayed++
+			after = event.Position
+		}
+		if len(batch) < replayBatchSize {
+			break
+		}
+	}
+	log.Printf("Replayed %d events to %s (replay %s)", replayed, routingKey, replayID)
+	return replayed, nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"E-Commerce/pkg/broker"
+	"E-Commerce/pkg/broker/memory"
+	"E-Commerce/pkg/events"
+	"E-Commerce/pkg/messaging"
+	"E-Commerce/producer-service/internal/entity"
+	"E-Commerce/producer-service/internal/repository"
+)
+
+// fakeEventStore mirrors the event_store table.
+type fakeEventStore struct {
+	mu     sync.Mutex
+	events []*entity.StoredEvent
+	// fail makes Append fail
+	fail bool
+}
+
+func (f *fakeEventStore) Append(event *entity.StoredEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return errors.New("database unavailable")
+	}
+	for _, e := range f.events {
+		if e.EventID == event.EventID {
+			return nil
+		}
+	}
+	stored := *event
+	stored.Position = int64(len(f.events) + 1)
+	f.events = append(f.events, &stored)
+	return nil
+}
+
+func (f *fakeEventStore) List(filter entity.EventFilter, after int64, limit int) ([]*entity.StoredEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*entity.StoredEvent
+	for _, e := range f.events {
+		if e.Position <= after || len(out) == limit {
+			continue
+		}
+		if !filter.From.IsZero() && e.OccurredAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !e.OccurredAt.Before(filter.To) {
+			continue
+		}
+		if len(filter.Types) > 0 && !contains(filter.Types, e.EventType) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+var epoch = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+func testEvent(n int) entity.OrderCreatedEvent {
+	return entity.OrderCreatedEvent{
+		Version:   events.OrderCreatedVersion,
+		EventID:   fmt.Sprintf("event-%d", n),
+		OrderID:   fmt.Sprintf("order-%d", n),
+		UserID:    "user-1",
+		Items:     []events.LineItem{{ProductID: "product-1", Quantity: 1, UnitPrice: 10}},
+		Total:     10,
+		CreatedAt: epoch.Add(time.Duration(n) * time.Hour),
+	}
+}
+
+func newTestService(t *testing.T) (*memory.Broker, ProducerService, *fakeEventStore) {
+	t.Helper()
+	b := memory.NewBroker()
+	t.Cleanup(func() { b.Close() })
+	b.Bind(messaging.OrderCreatedQueue, messaging.OrderCreatedRoutingKey)
+	b.Bind("projection", "order.created.replay")
+	store := &fakeEventStore{}
+	return b, NewProducerService(repository.NewEventRepository(b.Publisher()), store), store
+}
+
+func TestPublishOrderCreatedRecordsEventOnce(t *testing.T) {
+	_, svc, store := newTestService(t)
+
+	for i := 0; i < 2; i++ {
+		if err := svc.PublishOrderCreated(context.Background(), testEvent(1)); err != nil {
+			t.Fatalf("publish %d failed: %v", i+1, err)
+		}
+	}
+	if len(store.events) != 1 {
+		t.Fatalf("got %d stored events, want 1", len(store.events))
+	}
+	e := store.events[0]
+	if e.EventID != "event-1" || e.EventType != events.OrderCreatedType || !e.OccurredAt.Equal(testEvent(1).CreatedAt) {
+		t.Errorf("got stored event %+v", e)
+	}
+	if _, err := events.Decode(e.Payload); err != nil {
+		t.Errorf("stored payload is not the published envelope: %v", err)
+	}
+}
+
+func TestPublishOrderCreatedFailsWhenNotRecorded(t *testing.T) {
+	_, svc, store := newTestService(t)
+	store.fail = true
+
+	if err := svc.PublishOrderCreated(context.Background(), testEvent(1)); err == nil {
+		t.Fatal("expected an error when the event store is down")
+	}
+}
+
+func TestPublishOrderCreatedIsNotRecordedWhenPublishFails(t *testing.T) {
+	b := memory.NewBroker()
+	defer b.Close()
+	store := &fakeEventStore{}
+	svc := NewProducerService(repository.NewEventRepository(b.Publisher()), store)
+
+	err := svc.PublishOrderCreated(context.Background(), testEvent(1))
+	if !errors.Is(err, broker.ErrUnroutable) {
+		t.Fatalf("got %v, want %v", err, broker.ErrUnroutable)
+	}
+	if len(store.events) != 0 {
+		t.Errorf("an event that was not published was recorded")
+	}
+}
+
+func TestReplayEventsFiltersByTimeAndType(t *testing.T) {
+	b, svc, store := newTestService(t)
+	for i := 1; i <= 250; i++ {
+		if err := svc.PublishOrderCreated(context.Background(), testEvent(i)); err != nil {
+			t.Fatalf("publish failed: %v", err)
+		}
+	}
+	store.events[9].EventType = "com.ecommerce.order.cancelled.v1"
+
+	filter := entity.EventFilter{
+		From:  epoch.Add(5 * time.Hour),
+		To:    epoch.Add(205 * time.Hour),
+		Types: []string{events.OrderCreatedType},
+	}
+	n, err := svc.ReplayEvents(context.Background(), filter, "order.created.replay")
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	// Events 5 to 204, less the cancelled event 10
+	if n != 199 || b.Len("projection") != 199 {
+		t.Fatalf("replayed %d events, %d queued; want 199", n, b.Len("projection"))
+	}
+
+	msgs, err := b.Subscriber("projection").Subscribe(0)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	first := <-msgs
+	if e, err := events.Decode(first.Body); err != nil || e.ID != "event-5" {
+		t.Errorf("first replayed event is %+v, %v; want event-5", e, err)
+	}
+}
+
+func TestReplayEventsRejectsInvalidRequests(t *testing.T) {
+	_, svc, _ := newTestService(t)
+
+	if _, err := svc.ReplayEvents(context.Background(), entity.EventFilter{}, ""); !errors.Is(err, ErrInvalidReplay) {
+		t.Errorf("got %v for a missing routing key, want %v", err, ErrInvalidReplay)
+	}
+	filter := entity.EventFilter{From: epoch, To: epoch}
+	if _, err := svc.ReplayEvents(context.Background(), filter, "order.created.replay"); !errors.Is(err, ErrInvalidReplay) {
+		t.Errorf("got %v for an empty time range, want %v", err, ErrInvalidReplay)
+	}
+}
+
+func TestReplayEventsToUnboundRoutingKeyFails(t *testing.T) {
+	_, svc, _ := newTestService(t)
+	if err := svc.PublishOrderCreated(context.Background(), testEvent(1)); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+
+	n, err := svc.ReplayEvents(context.Background(), entity.EventFilter{}, "nobody.listens")
+	if n != 0 || !errors.Is(err, broker.ErrUnroutable) {
+		t.Fatalf("got %d, %v; want 0, %v", n, err, broker.ErrUnroutable)
+	}
+}
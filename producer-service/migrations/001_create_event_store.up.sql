@@ -0,0 +1,25 @@
+-- Every event producer-service published, in the order it was recorded.
+-- Rows are never changed or removed; replays read from here.
+CREATE TABLE event_store (
+    position BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(255) NOT NULL UNIQUE,
+    event_type VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL DEFAULT '',
+    routing_key VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_event_store_occurred_at ON event_store (occurred_at);
+CREATE INDEX idx_event_store_event_type ON event_store (event_type, occurred_at);
+
+CREATE FUNCTION event_store_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'event_store is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER event_store_append_only
+    BEFORE UPDATE OR DELETE ON event_store
+    FOR EACH ROW EXECUTE FUNCTION event_store_append_only();
@@ -21,17 +21,83 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type OrderLineItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     float64                `protobuf:"fixed64,3,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderLineItem) Reset() {
+	*x = OrderLineItem{}
+	mi := &file_producer_service_proto_producer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderLineItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderLineItem) ProtoMessage() {}
+
+func (x *OrderLineItem) ProtoReflect() protoreflect.Message {
+	mi := &file_producer_service_proto_producer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderLineItem.ProtoReflect.Descriptor instead.
+func (*OrderLineItem) Descriptor() ([]byte, []int) {
+	return file_producer_service_proto_producer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OrderLineItem) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *OrderLineItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderLineItem) GetUnitPrice() float64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
 type OrderCreatedRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	ProductIds    []string               `protobuf:"bytes,2,rep,name=product_ids,json=productIds,proto3" json:"product_ids,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Items         []*OrderLineItem       `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`
+	Total         float64                `protobuf:"fixed64,5,opt,name=total,proto3" json:"total,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC3339
+	Version       int32                  `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	EventId       string                 `protobuf:"bytes,8,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`                   // stable across redeliveries, used as the AMQP message ID
+	ReservationId string                 `protobuf:"bytes,9,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"` // empty if the order's stock was not reserved
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *OrderCreatedRequest) Reset() {
 	*x = OrderCreatedRequest{}
-	mi := &file_producer_service_proto_producer_proto_msgTypes[0]
+	mi := &file_producer_service_proto_producer_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -43,7 +109,7 @@ func (x *OrderCreatedRequest) String() string {
 func (*OrderCreatedRequest) ProtoMessage() {}
 
 func (x *OrderCreatedRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_producer_service_proto_producer_proto_msgTypes[0]
+	mi := &file_producer_service_proto_producer_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -56,7 +122,7 @@ func (x *OrderCreatedRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OrderCreatedRequest.ProtoReflect.Descriptor instead.
 func (*OrderCreatedRequest) Descriptor() ([]byte, []int) {
-	return file_producer_service_proto_producer_proto_rawDescGZIP(), []int{0}
+	return file_producer_service_proto_producer_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *OrderCreatedRequest) GetOrderId() string {
@@ -66,13 +132,55 @@ func (x *OrderCreatedRequest) GetOrderId() string {
 	return ""
 }
 
-func (x *OrderCreatedRequest) GetProductIds() []string {
+func (x *OrderCreatedRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *OrderCreatedRequest) GetItems() []*OrderLineItem {
 	if x != nil {
-		return x.ProductIds
+		return x.Items
 	}
 	return nil
 }
 
+func (x *OrderCreatedRequest) GetTotal() float64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *OrderCreatedRequest) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *OrderCreatedRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *OrderCreatedRequest) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *OrderCreatedRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
 type OrderCreatedResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -82,7 +190,7 @@ type OrderCreatedResponse struct {
 
 func (x *OrderCreatedResponse) Reset() {
 	*x = OrderCreatedResponse{}
-	mi := &file_producer_service_proto_producer_proto_msgTypes[1]
+	mi := &file_producer_service_proto_producer_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -94,7 +202,7 @@ func (x *OrderCreatedResponse) String() string {
 func (*OrderCreatedResponse) ProtoMessage() {}
 
 func (x *OrderCreatedResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_producer_service_proto_producer_proto_msgTypes[1]
+	mi := &file_producer_service_proto_producer_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -107,7 +215,7 @@ func (x *OrderCreatedResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OrderCreatedResponse.ProtoReflect.Descriptor instead.
 func (*OrderCreatedResponse) Descriptor() ([]byte, []int) {
-	return file_producer_service_proto_producer_proto_rawDescGZIP(), []int{1}
+	return file_producer_service_proto_producer_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *OrderCreatedResponse) GetSuccess() bool {
@@ -117,19 +225,153 @@ func (x *OrderCreatedResponse) GetSuccess() bool {
 	return false
 }
 
+type ReplayEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`                               // RFC3339, inclusive; empty to start at the first event
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`                                   // RFC3339, exclusive; empty for no upper bound
+	EventTypes    []string               `protobuf:"bytes,3,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"` // CloudEvents types; empty for every type
+	RoutingKey    string                 `protobuf:"bytes,4,opt,name=routing_key,json=routingKey,proto3" json:"routing_key,omitempty"` // where the events are published again
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayEventsRequest) Reset() {
+	*x = ReplayEventsRequest{}
+	mi := &file_producer_service_proto_producer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayEventsRequest) ProtoMessage() {}
+
+func (x *ReplayEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_producer_service_proto_producer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayEventsRequest.ProtoReflect.Descriptor instead.
+func (*ReplayEventsRequest) Descriptor() ([]byte, []int) {
+	return file_producer_service_proto_producer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReplayEventsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ReplayEventsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ReplayEventsRequest) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *ReplayEventsRequest) GetRoutingKey() string {
+	if x != nil {
+		return x.RoutingKey
+	}
+	return ""
+}
+
+type ReplayEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replayed      int64                  `protobuf:"varint,1,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayEventsResponse) Reset() {
+	*x = ReplayEventsResponse{}
+	mi := &file_producer_service_proto_producer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayEventsResponse) ProtoMessage() {}
+
+func (x *ReplayEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_producer_service_proto_producer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayEventsResponse.ProtoReflect.Descriptor instead.
+func (*ReplayEventsResponse) Descriptor() ([]byte, []int) {
+	return file_producer_service_proto_producer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ReplayEventsResponse) GetReplayed() int64 {
+	if x != nil {
+		return x.Replayed
+	}
+	return 0
+}
+
 var File_producer_service_proto_producer_proto protoreflect.FileDescriptor
 
 const file_producer_service_proto_producer_proto_rawDesc = "" +
 	"\n" +
-	"%producer-service/proto/producer.proto\x12\bproducer\"Q\n" +
+	"%producer-service/proto/producer.proto\x12\bproducer\"i\n" +
+	"\rOrderLineItem\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x03 \x01(\x01R\tunitPrice\"\x8f\x02\n" +
 	"\x13OrderCreatedRequest\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x1f\n" +
-	"\vproduct_ids\x18\x02 \x03(\tR\n" +
-	"productIds\"0\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12-\n" +
+	"\x05items\x18\x04 \x03(\v2\x17.producer.OrderLineItemR\x05items\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\x01R\x05total\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x18\n" +
+	"\aversion\x18\a \x01(\x05R\aversion\x12\x19\n" +
+	"\bevent_id\x18\b \x01(\tR\aeventId\x12%\n" +
+	"\x0ereservation_id\x18\t \x01(\tR\rreservationIdJ\x04\b\x02\x10\x03\"0\n" +
 	"\x14OrderCreatedResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2f\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"{\n" +
+	"\x13ReplayEventsRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x1f\n" +
+	"\vevent_types\x18\x03 \x03(\tR\n" +
+	"eventTypes\x12\x1f\n" +
+	"\vrouting_key\x18\x04 \x01(\tR\n" +
+	"routingKey\"2\n" +
+	"\x14ReplayEventsResponse\x12\x1a\n" +
+	"\breplayed\x18\x01 \x01(\x03R\breplayed2\xb5\x01\n" +
 	"\x0fProducerService\x12S\n" +
-	"\x12NotifyOrderCreated\x12\x1d.producer.OrderCreatedRequest\x1a\x1e.producer.OrderCreatedResponseB#Z!E-Commerce/producer-service/protob\x06proto3"
+	"\x12NotifyOrderCreated\x12\x1d.producer.OrderCreatedRequest\x1a\x1e.producer.OrderCreatedResponse\x12M\n" +
+	"\fReplayEvents\x12\x1d.producer.ReplayEventsRequest\x1a\x1e.producer.ReplayEventsResponseB#Z!E-Commerce/producer-service/protob\x06proto3"
 
 var (
 	file_producer_service_proto_producer_proto_rawDescOnce sync.Once
@@ -143,19 +385,25 @@ func file_producer_service_proto_producer_proto_rawDescGZIP() []byte {
 	return file_producer_service_proto_producer_proto_rawDescData
 }
 
-var file_producer_service_proto_producer_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_producer_service_proto_producer_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_producer_service_proto_producer_proto_goTypes = []any{
-	(*OrderCreatedRequest)(nil),  // 0: producer.OrderCreatedRequest
-	(*OrderCreatedResponse)(nil), // 1: producer.OrderCreatedResponse
+	(*OrderLineItem)(nil),        // 0: producer.OrderLineItem
+	(*OrderCreatedRequest)(nil),  // 1: producer.OrderCreatedRequest
+	(*OrderCreatedResponse)(nil), // 2: producer.OrderCreatedResponse
+	(*ReplayEventsRequest)(nil),  // 3: producer.ReplayEventsRequest
+	(*ReplayEventsResponse)(nil), // 4: producer.ReplayEventsResponse
 }
 var file_producer_service_proto_producer_proto_depIdxs = []int32{
-	0, // 0: producer.ProducerService.NotifyOrderCreated:input_type -> producer.OrderCreatedRequest
-	1, // 1: producer.ProducerService.NotifyOrderCreated:output_type -> producer.OrderCreatedResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: producer.OrderCreatedRequest.items:type_name -> producer.OrderLineItem
+	1, // 1: producer.ProducerService.NotifyOrderCreated:input_type -> producer.OrderCreatedRequest
+	3, // 2: producer.ProducerService.ReplayEvents:input_type -> producer.ReplayEventsRequest
+	2, // 3: producer.ProducerService.NotifyOrderCreated:output_type -> producer.OrderCreatedResponse
+	4, // 4: producer.ProducerService.ReplayEvents:output_type -> producer.ReplayEventsResponse
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_producer_service_proto_producer_proto_init() }
@@ -169,7 +417,7 @@ func file_producer_service_proto_producer_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_producer_service_proto_producer_proto_rawDesc), len(file_producer_service_proto_producer_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,6 +20,7 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	ProducerService_NotifyOrderCreated_FullMethodName = "/producer.ProducerService/NotifyOrderCreated"
+	ProducerService_ReplayEvents_FullMethodName       = "/producer.ProducerService/ReplayEvents"
 )
 
 // ProducerServiceClient is the client API for ProducerService service.
@@ -27,6 +28,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ProducerServiceClient interface {
 	NotifyOrderCreated(ctx context.Context, in *OrderCreatedRequest, opts ...grpc.CallOption) (*OrderCreatedResponse, error)
+	// ReplayEvents publishes the recorded events matching the request again,
+	// oldest first, so downstream projections can be rebuilt.
+	ReplayEvents(ctx context.Context, in *ReplayEventsRequest, opts ...grpc.CallOption) (*ReplayEventsResponse, error)
 }
 
 type producerServiceClient struct {
@@ -47,11 +51,24 @@ func (c *producerServiceClient) NotifyOrderCreated(ctx context.Context, in *Orde
 	return out, nil
 }
 
+func (c *producerServiceClient) ReplayEvents(ctx context.Context, in *ReplayEventsRequest, opts ...grpc.CallOption) (*ReplayEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplayEventsResponse)
+	err := c.cc.Invoke(ctx, ProducerService_ReplayEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProducerServiceServer is the server API for ProducerService service.
 // All implementations must embed UnimplementedProducerServiceServer
 // for forward compatibility.
 type ProducerServiceServer interface {
 	NotifyOrderCreated(context.Context, *OrderCreatedRequest) (*OrderCreatedResponse, error)
+	// ReplayEvents publishes the recorded events matching the request again,
+	// oldest first, so downstream projections can be rebuilt.
+	ReplayEvents(context.Context, *ReplayEventsRequest) (*ReplayEventsResponse, error)
 	mustEmbedUnimplementedProducerServiceServer()
 }
 
@@ -65,6 +82,9 @@ type UnimplementedProducerServiceServer struct{}
 func (UnimplementedProducerServiceServer) NotifyOrderCreated(context.Context, *OrderCreatedRequest) (*OrderCreatedResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method NotifyOrderCreated not implemented")
 }
+func (UnimplementedProducerServiceServer) ReplayEvents(context.Context, *ReplayEventsRequest) (*ReplayEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayEvents not implemented")
+}
 func (UnimplementedProducerServiceServer) mustEmbedUnimplementedProducerServiceServer() {}
 func (UnimplementedProducerServiceServer) testEmbeddedByValue()                         {}
 
@@ -104,6 +124,24 @@ func _ProducerService_NotifyOrderCreated_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProducerService_ReplayEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProducerServiceServer).ReplayEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProducerService_ReplayEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProducerServiceServer).ReplayEvents(ctx, req.(*ReplayEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ProducerService_ServiceDesc is the grpc.ServiceDesc for ProducerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,6 +153,10 @@ var ProducerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "NotifyOrderCreated",
 			Handler:    _ProducerService_NotifyOrderCreated_Handler,
 		},
+		{
+			MethodName: "ReplayEvents",
+			Handler:    _ProducerService_ReplayEvents_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "producer-service/proto/producer.proto",
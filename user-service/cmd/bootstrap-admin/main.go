@@ -0,0 +1,108 @@
+// Command bootstrap-admin makes the first admin, straight in the user
+// database. It promotes the account with the given email, creating it if
+// needed, and refuses to run once an admin exists: further admins are
+// appointed with PUT /admin/users/:id/role.
+//
+//	bootstrap-admin -email EMAIL
+//
+// The password of a new account is read from ADMIN_PASSWORD or, if that
+// is not set, from the first line of standard input.
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"E-Commerce/user-service/config"
+	"E-Commerce/user-service/internal/entity"
+	"E-Commerce/user-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the account to make admin (required)")
+	flag.Parse()
+	if *email == "" {
+		fmt.Fprintln(os.Stderr, "usage: bootstrap-admin -email EMAIL")
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadWithoutFlags()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	db, err := sqlx.Connect("postgres", cfg.PostgresURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	repo := repository.NewUserRepository(db)
+
+	admins, err := repo.CountUsersWithRole(models.RoleAdmin)
+	if err != nil {
+		log.Fatalf("Failed to count admins: %v", err)
+	}
+	if admins > 0 {
+		log.Fatalf("An admin already exists; appoint further admins with PUT /admin/users/:id/role")
+	}
+
+	user, err := repo.GetUserByEmail(*email)
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = createUser(repo, *email)
+	}
+	if err != nil {
+		log.Fatalf("Failed to find or create %s: %v", *email, err)
+	}
+
+	err = repo.ChangeUserRole(&models.RoleChange{
+		UserID:    user.ID,
+		OldRole:   user.Role,
+		NewRole:   models.RoleAdmin,
+		ChangedBy: "bootstrap-admin",
+		Reason:    "first admin",
+	})
+	if err != nil {
+		log.Fatalf("Failed to make %s an admin: %v", *email, err)
+	}
+	fmt.Printf("%s (%s) is now an admin\n", user.Email, user.ID)
+}
+
+func createUser(repo repository.UserRepository, email string) (*models.User, error) {
+	password, ok := os.LookupEnv("ADMIN_PASSWORD")
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Password for new account %s: ", email)
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("failed to read password: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if password == "" {
+		return nil, errors.New("the password must not be empty")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %v", err)
+	}
+	user := &models.User{
+		ID:       uuid.New().String(),
+		Email:    email,
+		Password: string(hashedPassword),
+		Role:     models.RoleUser,
+	}
+	if err := repo.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
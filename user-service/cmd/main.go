@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
-	"os"
+	"time"
 
+	"E-Commerce/pkg/authz"
+	"E-Commerce/pkg/observability"
+	"E-Commerce/pkg/secrets"
+	"E-Commerce/user-service/config"
+	"E-Commerce/user-service/internal/auth"
 	"E-Commerce/user-service/internal/handler"
 	"E-Commerce/user-service/internal/repository"
 	pb "E-Commerce/user-service/proto"
@@ -15,25 +21,49 @@ import (
 )
 
 func main() {
-	dsn := os.Getenv("POSTGRES_URL")
-	db, err := sqlx.Connect("postgres", dsn)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	store, err := secrets.Open(cfg.Config, auth.KeySpec)
+	if err != nil {
+		log.Fatalf("Failed to load secrets: %v", err)
+	}
+	auth.UseKeys(store.Bytes(auth.KeySpec.Name))
+	go store.Watch(context.Background(), cfg.SecretsReloadInterval)
+
+	shutdown := observability.Init("user-service", cfg.MetricsAddr)
+	defer shutdown()
+
+	db, err := sqlx.Connect("postgres", cfg.PostgresURL)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
 	repo := repository.NewUserRepository(db)
-	service := handler.NewUserService(repo)
+	service := handler.NewUserService(repo, repository.NewRefreshTokenRepository(db), handler.TokenConfig{
+		AccessTTL:  cfg.AccessTokenTTL,
+		RefreshTTL: cfg.RefreshTokenTTL,
+	})
 
-	lis, err := net.Listen("tcp", ":50053")
+	lis, err := net.Listen("tcp", cfg.ListenAddr)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	// Role changes need a forwarded token of a caller allowed to make them;
+	// the other methods serve logins and other services. Keys come from the
+	// local ring, so refreshing them often is cheap.
+	verifier := authz.NewVerifier(authz.NewKeySet(auth.LocalKeys, time.Minute))
+	rules := authz.Rules{
+		pb.UserService_SetUserRole_FullMethodName: authz.UserManageRoles,
+	}
+	s := grpc.NewServer(append(observability.ServerOptions(), authz.ServerOption(verifier, rules))...)
 	pb.RegisterUserServiceServer(s, service)
 
-	log.Println("User Service is running on port 50053")
+	log.Printf("User Service is running on %s", cfg.ListenAddr)
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 	}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"E-Commerce/pkg/secrets"
+	"E-Commerce/pkg/settings"
+)
+
+// EnvPrefix is the prefix of the user-service environment variables,
+// such as USER_SERVICE_POSTGRES_URL.
+const EnvPrefix = "USER_SERVICE"
+
+// Config holds the user-service settings. Each can be set in the YAML
+// file, the environment or a flag; see pkg/settings.
+type Config struct {
+	secrets.Config
+
+	ListenAddr  string `setting:"listen_addr" default:":50053" required:"true" usage:"gRPC listen address"`
+	MetricsAddr string `setting:"metrics_addr" default:":9103" usage:"Prometheus /metrics listen address; empty disables it"`
+	PostgresURL string `setting:"postgres_url" required:"true" usage:"PostgreSQL DSN of the user database"`
+
+	AccessTokenTTL  time.Duration `setting:"access_token_ttl" default:"15m" required:"true" usage:"lifetime of access tokens"`
+	RefreshTokenTTL time.Duration `setting:"refresh_token_ttl" default:"720h" required:"true" usage:"lifetime of refresh tokens; each refresh issues a new one"`
+}
+
+// Load reads the settings and fails naming every one that is missing or
+// invalid.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := settings.Load(cfg, EnvPrefix); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadWithoutFlags is Load for commands that parse their own flags.
+func LoadWithoutFlags() (*Config, error) {
+	cfg := &Config{}
+	if err := settings.LoadFrom(cfg, settings.Source{LookupEnv: os.LookupEnv, EnvPrefix: EnvPrefix}); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is one private key of a KeyRing.
+type SigningKey struct {
+	// ID is the kid tokens signed with the key carry, derived from the
+	// public key so every replica agrees on it.
+	ID     string
+	Method jwt.SigningMethod
+	Signer crypto.Signer
+}
+
+// PublicKey is the verifying half of a SigningKey.
+type PublicKey struct {
+	ID        string
+	Algorithm string
+	// DER is the PKIX encoding of the key.
+	DER []byte
+}
+
+// KeyRing holds the keys tokens are signed with. The first key signs new
+// tokens. The others no longer sign but stay published until the tokens
+// they signed have expired, so a key can be rotated without logging
+// anyone out.
+type KeyRing struct {
+	keys []SigningKey
+}
+
+// ParseKeyRing reads a key ring from PEM-encoded PKCS #8 private keys,
+// newest first. Ed25519 keys sign with EdDSA and RSA keys with RS256.
+func ParseKeyRing(data []byte) (*KeyRing, error) {
+	ring := &KeyRing{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PRIVATE KEY" {
+			return nil, fmt.Errorf("unexpected PEM block %q, want PRIVATE KEY", block.Type)
+		}
+		key, err := parseSigningKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ring.keys = append(ring.keys, key)
+	}
+	if len(ring.keys) == 0 {
+		return nil, errors.New("no private keys found")
+	}
+	return ring, nil
+}
+
+func parseSigningKey(der []byte) (SigningKey, error) {
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	var key SigningKey
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		key = SigningKey{Method: jwt.SigningMethodEdDSA, Signer: k}
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return SigningKey{}, fmt.Errorf("RSA key has %d bits, want at least 2048", k.N.BitLen())
+		}
+		key = SigningKey{Method: jwt.SigningMethodRS256, Signer: k}
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported key type %T, want Ed25519 or RSA", parsed)
+	}
+	public, err := x509.MarshalPKIXPublicKey(key.Signer.Public())
+	if err != nil {
+		return SigningKey{}, err
+	}
+	sum := sha256.Sum256(public)
+	key.ID = base64.RawURLEncoding.EncodeToString(sum[:12])
+	return key, nil
+}
+
+// Active returns the key new tokens are signed with.
+func (r *KeyRing) Active() SigningKey {
+	return r.keys[0]
+}
+
+// PublicKeys returns the public keys of every key in the ring.
+func (r *KeyRing) PublicKeys() ([]PublicKey, error) {
+	keys := make([]PublicKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		der, err := x509.MarshalPKIXPublicKey(k.Signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, PublicKey{ID: k.ID, Algorithm: k.Method.Alg(), DER: der})
+	}
+	return keys, nil
+}
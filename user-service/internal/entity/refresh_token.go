@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RefreshToken is a stored refresh token. Only the hash of the token is
+// kept. Tokens issued from one login share a FamilyID.
+type RefreshToken struct {
+	ID        string     `db:"id"`
+	FamilyID  string     `db:"family_id"`
+	UserID    string     `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
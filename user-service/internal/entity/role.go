@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"E-Commerce/pkg/authz"
+)
+
+// Roles a user can have. Everyone who registers is a RoleUser, a customer;
+// the staff roles and what they grant are listed in package authz.
+const (
+	RoleUser  = authz.RoleUser
+	RoleAdmin = authz.RoleAdmin
+)
+
+// ValidRole reports whether role is a known role.
+func ValidRole(role string) bool {
+	return authz.ValidRole(role)
+}
+
+// RoleChange is an entry of the role audit log.
+type RoleChange struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	OldRole   string    `db:"old_role"`
+	NewRole   string    `db:"new_role"`
+	ChangedBy string    `db:"changed_by"`
+	Reason    string    `db:"reason"`
+	ChangedAt time.Time `db:"changed_at"`
+}
@@ -2,7 +2,12 @@ package handler
 
 import (
 	"context"	
+	"database/sql"
+	"errors"
+	"log"
+	"time"
 
+	"E-Commerce/pkg/authz"
 	"E-Commerce/user-service/internal/auth"
 	"E-Commerce/user-service/internal/entity"
 	"E-Commerce/user-service/internal/repository"
@@ -16,11 +21,19 @@ import (
 
 type UserService struct {
 	pb.UnimplementedUserServiceServer
-	repo repository.UserRepository
+	repo          repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	tokens        TokenConfig
 }
 
-func NewUserService(repo repository.UserRepository) *UserService {
-	return &UserService{repo: repo}
+// TokenConfig sets the lifetimes of the tokens the service issues.
+type TokenConfig struct {
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+func NewUserService(repo repository.UserRepository, refreshTokens repository.RefreshTokenRepository, tokens TokenConfig) *UserService {
+	return &UserService{repo: repo, refreshTokens: refreshTokens, tokens: tokens}
 }
 
 func (s *UserService) RegisterUser(ctx context.Context, req *pb.RegisterUserRequest) (*pb.AuthResponse, error) {
@@ -36,38 +49,19 @@ func (s *UserService) RegisterUser(ctx context.Context, req *pb.RegisterUserRequ
 
 	id := uuid.New().String()
 
-	role := req.Role
-	if role == "" {
-		role = "user"
-	}
-	if role != "admin" && role != "user" {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid role")
-	}
-
+	// Registration only ever creates customers; see SetUserRole
 	user := &models.User{
 		ID:       id,
 		Email:    req.Email,
 		Password: string(hashedPassword),
-		Role:     role,
+		Role:     models.RoleUser,
 	}
 	err = s.repo.CreateUser(user)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create user")
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Role)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to generate token")
-	}
-
-	return &pb.AuthResponse{
-		Token: token,
-		User: &pb.User{
-			Id:    user.ID,
-			Email: user.Email,
-			Role:  user.Role,
-		},
-	}, nil
+	return s.issueTokens(user, uuid.New().String())
 }
 
 func (s *UserService) AuthenticateUser(ctx context.Context, req *pb.AuthenticateUserRequest) (*pb.AuthResponse, error) {
@@ -81,19 +75,7 @@ func (s *UserService) AuthenticateUser(ctx context.Context, req *pb.Authenticate
 		return nil, status.Errorf(codes.Unauthenticated, "invalid credentials")
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Role)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to generate token")
-	}
-
-	return &pb.AuthResponse{
-		Token: token,
-		User: &pb.User{
-			Id:    user.ID,
-			Email: user.Email,
-			Role:  user.Role,
-		},
-	}, nil
+	return s.issueTokens(user, uuid.New().String())
 }
 
 func (s *UserService) GetUserProfile(ctx context.Context, req *pb.GetUserProfileRequest) (*pb.UserProfile, error) {
@@ -131,4 +113,155 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, req *pb.UpdateUserP
 		Email: user.Email,
 		Role:  user.Role,
 	}, nil
-}
\ No newline at end of file
+}
+func (s *UserService) GetSigningKeys(ctx context.Context, req *pb.GetSigningKeysRequest) (*pb.GetSigningKeysResponse, error) {
+	ring, err := auth.CurrentKeys()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load signing keys")
+	}
+	keys, err := ring.PublicKeys()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode signing keys")
+	}
+
+	resp := &pb.GetSigningKeysResponse{}
+	for _, k := range keys {
+		resp.Keys = append(resp.Keys, &pb.SigningKey{
+			Kid:       k.ID,
+			Alg:       k.Algorithm,
+			PublicKey: k.DER,
+		})
+	}
+	return resp, nil
+}
+
+func (s *UserService) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.AuthResponse, error) {
+	token, err := s.refreshTokens.GetRefreshTokenByHash(auth.HashRefreshToken(req.RefreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to look up refresh token")
+	}
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	// A refresh token is used once. Seeing it again means it was copied,
+	// so every token of its family is revoked, the thief's and the user's.
+	fresh := token.UsedAt == nil
+	if fresh {
+		fresh, err = s.refreshTokens.MarkRefreshTokenUsed(token.ID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to use refresh token")
+		}
+	}
+	if !fresh {
+		log.Printf("Refresh token reuse detected for user %s, revoking token family %s", token.UserID, token.FamilyID)
+		if err := s.refreshTokens.RevokeRefreshTokenFamily(token.FamilyID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke refresh tokens")
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	user, err := s.repo.GetUserByID(token.UserID)
+	if err != nil || user == nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	}
+	return s.issueTokens(user, token.FamilyID)
+}
+
+func (s *UserService) RevokeRefreshToken(ctx context.Context, req *pb.RevokeRefreshTokenRequest) (*pb.RevokeRefreshTokenResponse, error) {
+	token, err := s.refreshTokens.GetRefreshTokenByHash(auth.HashRefreshToken(req.RefreshToken))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && token.UserID != req.UserId) {
+		return nil, status.Errorf(codes.NotFound, "refresh token not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to look up refresh token")
+	}
+	if err := s.refreshTokens.RevokeRefreshTokenFamily(token.FamilyID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke refresh tokens")
+	}
+	return &pb.RevokeRefreshTokenResponse{}, nil
+}
+
+func (s *UserService) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) (*pb.UserProfile, error) {
+	// The actor is the caller whose token the interceptor verified. Its
+	// role is checked again against the database in case it changed since
+	// the token was issued
+	caller, ok := authz.FromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "missing caller identity")
+	}
+	actor, err := s.repo.GetUserByID(caller.UserID)
+	if err != nil || actor == nil || !authz.Has(actor.Role, authz.UserManageRoles) {
+		return nil, status.Errorf(codes.PermissionDenied, "changing roles requires permission %s", authz.UserManageRoles)
+	}
+	if !models.ValidRole(req.Role) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid role %q", req.Role)
+	}
+	if req.UserId == actor.ID {
+		return nil, status.Errorf(codes.FailedPrecondition, "users cannot change their own role")
+	}
+
+	user, err := s.repo.GetUserByID(req.UserId)
+	if err != nil || user == nil {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+
+	if user.Role != req.Role {
+		err = s.repo.ChangeUserRole(&models.RoleChange{
+			UserID:    user.ID,
+			OldRole:   user.Role,
+			NewRole:   req.Role,
+			ChangedBy: actor.ID,
+			Reason:    req.Reason,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to change role")
+		}
+		log.Printf("Role of user %s changed from %s to %s by %s", user.ID, user.Role, req.Role, actor.ID)
+		user.Role = req.Role
+	}
+
+	return &pb.UserProfile{
+		Id:    user.ID,
+		Email: user.Email,
+		Role:  user.Role,
+	}, nil
+}
+
+// issueTokens returns an access token and a refresh token of the given
+// family for user.
+func (s *UserService) issueTokens(user *models.User, familyID string) (*pb.AuthResponse, error) {
+	accessToken, err := auth.GenerateToken(user.ID, user.Role, s.tokens.AccessTTL)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate token")
+	}
+
+	refreshToken, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate refresh token")
+	}
+	err = s.refreshTokens.CreateRefreshToken(&models.RefreshToken{
+		ID:        uuid.New().String(),
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.tokens.RefreshTTL),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store refresh token")
+	}
+
+	return &pb.AuthResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.tokens.AccessTTL.Seconds()),
+		User: &pb.User{
+			Id:    user.ID,
+			Email: user.Email,
+			Role:  user.Role,
+		},
+	}, nil
+}
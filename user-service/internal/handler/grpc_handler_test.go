@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"E-Commerce/pkg/authz"
+	"E-Commerce/user-service/internal/entity"
+	"E-Commerce/user-service/internal/repository"
+	pb "E-Commerce/user-service/proto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUserRepository keeps users in memory and records the role audit log.
+type fakeUserRepository struct {
+	repository.UserRepository
+	users   map[string]*models.User
+	changes []*models.RoleChange
+}
+
+func newFakeUserRepository(users ...*models.User) *fakeUserRepository {
+	r := &fakeUserRepository{users: make(map[string]*models.User)}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepository) GetUserByID(id string) (*models.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) ChangeUserRole(change *models.RoleChange) error {
+	r.users[change.UserID].Role = change.NewRole
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func TestSetUserRole(t *testing.T) {
+	admin := authz.Identity{UserID: "admin-1", Role: authz.RoleAdmin}
+
+	tests := []struct {
+		name   string
+		caller *authz.Identity
+		req    *pb.SetUserRoleRequest
+		code   codes.Code
+		// audited is whether the change lands in the audit log
+		audited bool
+	}{
+		{
+			name:    "Admin Assigns Role",
+			caller:  &admin,
+			req:     &pb.SetUserRoleRequest{UserId: "user-1", Role: authz.RoleSupport, Reason: "joined support"},
+			code:    codes.OK,
+			audited: true,
+		},
+		{
+			name:   "Unchanged Role",
+			caller: &admin,
+			req:    &pb.SetUserRoleRequest{UserId: "user-1", Role: authz.RoleUser},
+			code:   codes.OK,
+		},
+		{
+			name: "Missing Caller",
+			req:  &pb.SetUserRoleRequest{UserId: "user-1", Role: authz.RoleSupport},
+			code: codes.Unauthenticated,
+		},
+		{
+			// The token still says admin, but the role was revoked since
+			name:   "Demoted Admin",
+			caller: &authz.Identity{UserID: "former-admin", Role: authz.RoleAdmin},
+			req:    &pb.SetUserRoleRequest{UserId: "user-1", Role: authz.RoleAdmin},
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "Unknown Role",
+			caller: &admin,
+			req:    &pb.SetUserRoleRequest{UserId: "user-1", Role: "superuser"},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "Own Role",
+			caller: &admin,
+			req:    &pb.SetUserRoleRequest{UserId: "admin-1", Role: authz.RoleUser},
+			code:   codes.FailedPrecondition,
+		},
+		{
+			name:   "Unknown User",
+			caller: &admin,
+			req:    &pb.SetUserRoleRequest{UserId: "missing", Role: authz.RoleSupport},
+			code:   codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeUserRepository(
+				&models.User{ID: "admin-1", Email: "admin@test.com", Role: authz.RoleAdmin},
+				&models.User{ID: "former-admin", Email: "former@test.com", Role: authz.RoleUser},
+				&models.User{ID: "user-1", Email: "user@test.com", Role: authz.RoleUser},
+			)
+			ctx := context.Background()
+			if tt.caller != nil {
+				ctx = authz.NewContext(ctx, *tt.caller)
+			}
+
+			resp, err := NewUserService(repo, nil, TokenConfig{}).SetUserRole(ctx, tt.req)
+			require.Equal(t, tt.code, status.Code(err), "error: %v", err)
+			if tt.code == codes.OK {
+				assert.Equal(t, tt.req.Role, resp.Role)
+				assert.Equal(t, tt.req.Role, repo.users[tt.req.UserId].Role)
+			}
+
+			if !tt.audited {
+				assert.Empty(t, repo.changes)
+				return
+			}
+			require.Len(t, repo.changes, 1)
+			change := repo.changes[0]
+			assert.Equal(t, "user-1", change.UserID)
+			assert.Equal(t, authz.RoleUser, change.OldRole)
+			assert.Equal(t, authz.RoleSupport, change.NewRole)
+			assert.Equal(t, "admin-1", change.ChangedBy, "the actor comes from the verified token")
+			assert.Equal(t, "joined support", change.Reason)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"E-Commerce/user-service/internal/entity"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(token *models.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*models.RefreshToken, error)
+	// MarkRefreshTokenUsed reports whether the token was still unused, so
+	// two concurrent refreshes with one token cannot both succeed.
+	MarkRefreshTokenUsed(id string) (bool, error)
+	RevokeRefreshTokenFamily(familyID string) error
+}
+
+type refreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, family_id, user_id, token_hash, expires_at)
+		VALUES (:id, :family_id, :user_id, :token_hash, :expires_at)`
+	_, err := r.db.NamedExec(query, token)
+	return err
+}
+
+func (r *refreshTokenRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Get(&token, "SELECT * FROM refresh_tokens WHERE token_hash = $1", hash)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) MarkRefreshTokenUsed(id string) (bool, error) {
+	res, err := r.db.Exec(`UPDATE refresh_tokens SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *refreshTokenRepository) RevokeRefreshTokenFamily(familyID string) error {
+	_, err := r.db.Exec(`UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	return err
+}
@@ -11,6 +11,10 @@ type UserRepository interface {
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(id string) (*models.User, error)
 	UpdateUser(user *models.User) error
+	// ChangeUserRole sets the user's role and appends change to the audit
+	// log in one transaction.
+	ChangeUserRole(change *models.RoleChange) error
+	CountUsersWithRole(role string) (int, error)
 }
 
 type userRepository struct {
@@ -49,4 +53,28 @@ func (r *userRepository) UpdateUser(user *models.User) error {
 	query := `UPDATE users SET password_hash = :password_hash WHERE id = :id`
 	_, err := r.db.NamedExec(query, user)
 	return err
-}
\ No newline at end of file
+}
+
+func (r *userRepository) ChangeUserRole(change *models.RoleChange) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET role = $1 WHERE id = $2`, change.NewRole, change.UserID); err != nil {
+		return err
+	}
+	query := `INSERT INTO role_changes (user_id, old_role, new_role, changed_by, reason)
+		VALUES (:user_id, :old_role, :new_role, :changed_by, :reason)`
+	if _, err := tx.NamedExec(query, change); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *userRepository) CountUsersWithRole(role string) (int, error) {
+	var n int
+	err := r.db.Get(&n, "SELECT COUNT(*) FROM users WHERE role = $1", role)
+	return n, err
+}
@@ -0,0 +1,13 @@
+-- Audit log of role changes. changed_by is the ID of the admin who made the
+-- change, or the name of the tool, e.g. bootstrap-admin.
+CREATE TABLE role_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    old_role VARCHAR(50) NOT NULL,
+    new_role VARCHAR(50) NOT NULL,
+    changed_by VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_role_changes_user_id ON role_changes (user_id);
@@ -0,0 +1,29 @@
+// Package signingkeys lets other services verify the user-service's
+// access tokens by fetching its public keys over gRPC.
+package signingkeys
+
+import (
+	"context"
+
+	"E-Commerce/pkg/authz"
+	pb "E-Commerce/user-service/proto"
+)
+
+// Fetch fetches the keys with the user-service's GetSigningKeys RPC.
+func Fetch(client pb.UserServiceClient) authz.FetchFunc {
+	return func(ctx context.Context) ([]authz.PublicKey, error) {
+		resp, err := client.GetSigningKeys(ctx, &pb.GetSigningKeysRequest{})
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]authz.PublicKey, 0, len(resp.Keys))
+		for _, k := range resp.Keys {
+			key, err := authz.ParsePublicKey(k.Kid, k.Alg, k.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+		return keys, nil
+	}
+}